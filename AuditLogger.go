@@ -0,0 +1,365 @@
+package golog
+
+import (
+	"io"
+	"os"
+	"hash"
+	"sync"
+	"time"
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/base64"
+	"encoding/binary"
+)
+
+const DefaultAuditCheckpointInterval = 1000
+
+const MaxAuditLineSize = 64 << 20
+
+type AuditEntryKind byte
+
+const (
+	AEK_PACKET AuditEntryKind = 'P'
+	AEK_CHECKPOINT AuditEntryKind = 'C'
+)
+
+func AuditEntryHash(key []byte, kind AuditEntryKind, sequence uint64, prev []byte, payload []byte) []byte {
+	var digest hash.Hash
+	if len(key) > 0 {
+		digest = hmac.New(sha256.New, key)
+	} else {
+		digest = sha256.New()
+	}
+	var header [9]byte
+	header[0] = byte(kind)
+	binary.BigEndian.PutUint64(header[1:], sequence)
+	digest.Write(header[:])
+	digest.Write(prev)
+	digest.Write(payload)
+	return digest.Sum(nil)
+}
+
+type AuditLogger struct {
+	ID uintptr
+	Writer io.Writer
+	CloseStream func()
+	OnError func(error)
+	HashKey []byte
+	CheckpointKey ed25519.PrivateKey
+	CheckpointEvery int
+	mutex sync.Mutex
+	sequence uint64
+	last []byte
+	entries uint64
+	sinceCheckpoint int
+	TornTail []byte
+}
+
+func(logger *AuditLogger) Resume(report *AuditReport) {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	logger.sequence = report.LastSequence
+	logger.last = report.LastHash
+	logger.entries = report.Entries
+	logger.sinceCheckpoint = int(report.Unsigned)
+	logger.TornTail = report.TornTail
+}
+
+func(logger *AuditLogger) prevHash() []byte {
+	if logger.last == nil {
+		return make([]byte, sha256.Size)
+	}
+	return logger.last
+}
+
+func(logger *AuditLogger) appendEntry(kind AuditEntryKind, payload []byte) error {
+	prev := logger.prevHash()
+	sequence := logger.sequence + 1
+	sum := AuditEntryHash(logger.HashKey, kind, sequence, prev, payload)
+	var builder strings.Builder
+	builder.WriteString(`{"seq":`)
+	builder.WriteString(strconv.FormatUint(sequence, 10))
+	builder.WriteString(`,"prev":"`)
+	builder.WriteString(hex.EncodeToString(prev))
+	if kind == AEK_CHECKPOINT {
+		builder.WriteString(`","checkpoint":`)
+	} else {
+		builder.WriteString(`","packet":`)
+	}
+	builder.Write(payload)
+	if kind == AEK_CHECKPOINT {
+		builder.WriteString(`,"signature":"`)
+		builder.WriteString(base64.StdEncoding.EncodeToString(ed25519.Sign(logger.CheckpointKey, sum)))
+		builder.WriteByte('"')
+	}
+	builder.WriteString(`,"hash":"`)
+	builder.WriteString(hex.EncodeToString(sum))
+	builder.WriteString("\"}\n")
+	if _, err := io.WriteString(logger.Writer, builder.String()); err != nil {
+		return err
+	}
+	logger.sequence = sequence
+	logger.last = sum
+	return nil
+}
+
+func(logger *AuditLogger) checkpoint() error {
+	sink := &JSONStructSink{}
+	m := sink.Map()
+	m.StringProperty("time", time.Now().Format(time.RFC3339Nano))
+	PutUintProperty(m, "entries", logger.entries)
+	m.EndMap()
+	if err := logger.appendEntry(AEK_CHECKPOINT, []byte(sink.ToString())); err != nil {
+		return err
+	}
+	logger.sinceCheckpoint = 0
+	return nil
+}
+
+func(logger *AuditLogger) reportError(err error) {
+	if err != nil && logger.OnError != nil {
+		logger.OnError(err)
+	}
+}
+
+func(logger *AuditLogger) Log(packet *Packet) {
+	if packet == nil || logger.Writer == nil {
+		return
+	}
+	payload, _ := JSONPacketEncoder{}.EncodePacket(packet)
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	if err := logger.appendEntry(AEK_PACKET, payload); err != nil {
+		logger.reportError(err)
+		return
+	}
+	logger.entries++
+	logger.sinceCheckpoint++
+	if logger.CheckpointKey == nil {
+		return
+	}
+	interval := logger.CheckpointEvery
+	if interval == 0 {
+		interval = DefaultAuditCheckpointInterval
+	}
+	if interval > 0 && logger.sinceCheckpoint >= interval {
+		logger.reportError(logger.checkpoint())
+	}
+}
+
+func(logger *AuditLogger) Checkpoint() error {
+	if logger.CheckpointKey == nil || logger.Writer == nil {
+		return nil
+	}
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	return logger.checkpoint()
+}
+
+func(logger *AuditLogger) Close() {
+	logger.mutex.Lock()
+	if logger.CheckpointKey != nil && logger.Writer != nil && logger.sinceCheckpoint > 0 {
+		logger.reportError(logger.checkpoint())
+	}
+	logger.mutex.Unlock()
+	if logger.CloseStream != nil {
+		logger.CloseStream()
+		logger.CloseStream = nil
+	}
+}
+
+func(logger *AuditLogger) SubLoggers() []Logger {
+	return nil
+}
+
+func(logger *AuditLogger) Identity() uintptr {
+	return logger.ID
+}
+
+func AuditFileLogger(path string, hashKey []byte, checkpointKey ed25519.PrivateKey) (*AuditLogger, error) {
+	f, err := os.OpenFile(path, os.O_RDWR | os.O_CREATE | os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	verifier := &AuditVerifier {
+		HashKey: hashKey,
+	}
+	report, err := verifier.Verify(f)
+	if err == nil && report.TornLine > 0 {
+		err = f.Truncate(report.Size)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	logger := &AuditLogger {
+		ID: NewLoggerID(),
+		Writer: f,
+		CloseStream: func() {
+			f.Close()
+		},
+		HashKey: hashKey,
+		CheckpointKey: checkpointKey,
+	}
+	logger.Resume(report)
+	return logger, nil
+}
+
+type AuditVerifyError struct {
+	Line int
+	Sequence uint64
+	Reason string
+}
+
+func(err *AuditVerifyError) Error() string {
+	text := "invalid audit entry at line " + strconv.Itoa(err.Line)
+	if err.Sequence > 0 {
+		text += " (seq " + strconv.FormatUint(err.Sequence, 10) + ")"
+	}
+	return text + ": " + err.Reason
+}
+
+type AuditReport struct {
+	Entries uint64
+	Checkpoints int
+	LastSequence uint64
+	LastHash []byte
+	LastCheckpoint uint64
+	Unsigned uint64
+	Size int64
+	TornLine int
+	TornTail []byte
+}
+
+type AuditVerifier struct {
+	HashKey []byte
+	PublicKey ed25519.PublicKey
+	OnPacket func(sequence uint64, packet *Packet)
+}
+
+type auditLine struct {
+	Seq uint64 `json:"seq"`
+	Prev string `json:"prev"`
+	Packet json.RawMessage `json:"packet"`
+	Checkpoint json.RawMessage `json:"checkpoint"`
+	Signature string `json:"signature"`
+	Hash string `json:"hash"`
+}
+
+type auditCheckpoint struct {
+	Time string `json:"time"`
+	Entries uint64 `json:"entries"`
+}
+
+func(verifier *AuditVerifier) Verify(reader io.Reader) (*AuditReport, error) {
+	report := &AuditReport{}
+	prev := make([]byte, sha256.Size)
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, MaxAuditLineSize)
+	var consumed int64
+	var torn []byte
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) > 0 && bytes.IndexByte(data, '\n') < 0 {
+			torn = append([]byte(nil), data...)
+			return len(data), nil, nil
+		}
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		consumed += int64(advance)
+		return advance, token, err
+	})
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			report.Size = consumed
+			continue
+		}
+		fail := func(sequence uint64, reason string) (*AuditReport, error) {
+			return report, &AuditVerifyError {
+				Line: lineNumber,
+				Sequence: sequence,
+				Reason: reason,
+			}
+		}
+		var line auditLine
+		if err := json.Unmarshal(text, &line); err != nil {
+			return fail(0, "malformed line: " + err.Error())
+		}
+		expected := report.LastSequence + 1
+		if line.Seq != expected {
+			return fail(line.Seq, "expected sequence number " + strconv.FormatUint(expected, 10))
+		}
+		if line.Prev != hex.EncodeToString(prev) {
+			return fail(line.Seq, "previous hash does not match preceding entry")
+		}
+		var kind AuditEntryKind
+		var payload []byte
+		switch {
+			case len(line.Packet) > 0 && len(line.Checkpoint) == 0:
+				kind, payload = AEK_PACKET, line.Packet
+			case len(line.Checkpoint) > 0 && len(line.Packet) == 0:
+				kind, payload = AEK_CHECKPOINT, line.Checkpoint
+			default:
+				return fail(line.Seq, "entry must contain exactly one of packet or checkpoint")
+		}
+		sum := AuditEntryHash(verifier.HashKey, kind, line.Seq, prev, payload)
+		if line.Hash != hex.EncodeToString(sum) {
+			return fail(line.Seq, "hash mismatch")
+		}
+		if kind == AEK_CHECKPOINT {
+			var checkpoint auditCheckpoint
+			if err := json.Unmarshal(payload, &checkpoint); err != nil {
+				return fail(line.Seq, "malformed checkpoint: " + err.Error())
+			}
+			if checkpoint.Entries != report.Entries {
+				return fail(line.Seq, "checkpoint covers " + strconv.FormatUint(checkpoint.Entries, 10) +
+						" entries, but " + strconv.FormatUint(report.Entries, 10) + " precede it")
+			}
+			if verifier.PublicKey != nil {
+				signature, err := base64.StdEncoding.DecodeString(line.Signature)
+				if err != nil || !ed25519.Verify(verifier.PublicKey, sum, signature) {
+					return fail(line.Seq, "bad checkpoint signature")
+				}
+			}
+			report.Checkpoints++
+			report.LastCheckpoint = line.Seq
+			report.Unsigned = 0
+		} else {
+			if verifier.OnPacket != nil {
+				value, err := DecodeJSON(payload)
+				if err != nil {
+					return fail(line.Seq, err.Error())
+				}
+				packet, err := PacketFromValue(value)
+				if err != nil {
+					return fail(line.Seq, err.Error())
+				}
+				verifier.OnPacket(line.Seq, packet)
+			}
+			report.Entries++
+			report.Unsigned++
+		}
+		report.LastSequence = line.Seq
+		report.LastHash = sum
+		report.Size = consumed
+		prev = sum
+	}
+	if err := scanner.Err(); err != nil {
+		return report, err
+	}
+	if torn != nil {
+		report.TornLine = lineNumber + 1
+		report.TornTail = torn
+	}
+	return report, nil
+}
+
+var _ Logger = &AuditLogger{}
@@ -0,0 +1,219 @@
+package golog
+
+import (
+	"os"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"crypto/ed25519"
+	"path/filepath"
+)
+
+func auditPacket(text string) *Packet {
+	return &Packet {
+		Level: INFO,
+		Source: TextSource("audit"),
+		Message: &StringMessage {
+			Text: []string { text },
+		},
+	}
+}
+
+func writeAuditLog(t *testing.T, hashKey []byte, signer ed25519.PrivateKey, every int, texts ...string) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	logger := &AuditLogger {
+		ID: NewLoggerID(),
+		Writer: &buffer,
+		OnError: func(err error) {
+			t.Errorf("audit logger: %v", err)
+		},
+		HashKey: hashKey,
+		CheckpointKey: signer,
+		CheckpointEvery: every,
+	}
+	for _, text := range texts {
+		logger.Log(auditPacket(text))
+	}
+	logger.Close()
+	return buffer.Bytes()
+}
+
+func expectAuditFailure(t *testing.T, verifier *AuditVerifier, data []byte, line int, reason string) {
+	t.Helper()
+	_, err := verifier.Verify(bytes.NewReader(data))
+	var verifyErr *AuditVerifyError
+	if !errors.As(err, &verifyErr) {
+		t.Fatalf("expected AuditVerifyError, got %v", err)
+	}
+	if verifyErr.Line != line || !strings.Contains(verifyErr.Reason, reason) {
+		t.Fatalf("got %v, want line %d containing %q", err, line, reason)
+	}
+}
+
+func TestAuditChainVerifies(t *testing.T) {
+	key := []byte("chain key")
+	data := writeAuditLog(t, key, nil, 0, "a", "b", "c")
+	var seen []string
+	verifier := &AuditVerifier {
+		HashKey: key,
+		OnPacket: func(sequence uint64, packet *Packet) {
+			seen = append(seen, packet.Message.Lines()[0])
+		},
+	}
+	report, err := verifier.Verify(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Entries != 3 || report.LastSequence != 3 || report.Checkpoints != 0 || report.Unsigned != 3 {
+		t.Fatalf("report: %+v", report)
+	}
+	if strings.Join(seen, ",") != "a,b,c" {
+		t.Fatalf("packets: %q", seen)
+	}
+}
+
+func TestAuditDetectsTampering(t *testing.T) {
+	key := []byte("chain key")
+	data := writeAuditLog(t, key, nil, 0, "alpha", "beta", "gamma")
+	lines := strings.SplitAfter(string(data), "\n")
+	verifier := &AuditVerifier {
+		HashKey: key,
+	}
+	edited := strings.Replace(string(data), "beta", "BETA", 1)
+	expectAuditFailure(t, verifier, []byte(edited), 2, "hash mismatch")
+	dropped := lines[0] + lines[2]
+	expectAuditFailure(t, verifier, []byte(dropped), 2, "expected sequence number 2")
+	swapped := lines[0] + lines[2] + lines[1]
+	expectAuditFailure(t, verifier, []byte(swapped), 2, "expected sequence number")
+	expectAuditFailure(t, &AuditVerifier {
+		HashKey: []byte("other key"),
+	}, data, 1, "hash mismatch")
+	expectAuditFailure(t, verifier, []byte(lines[0] + "{not json\n"), 2, "malformed line")
+	truncatedTail := []byte(lines[0] + lines[1])
+	if report, err := verifier.Verify(bytes.NewReader(truncatedTail)); err != nil || report.Entries != 2 {
+		t.Fatalf("valid prefix: %+v, %v", report, err)
+	}
+	torn := lines[0] + lines[1][:len(lines[1]) - 1]
+	report, err := verifier.Verify(strings.NewReader(torn))
+	if err != nil || report.Entries != 1 || report.TornLine != 2 || string(report.TornTail) != lines[1][:len(lines[1]) - 1] {
+		t.Fatalf("torn tail: %+v, %v", report, err)
+	}
+	if report.Size != int64(len(lines[0])) {
+		t.Fatalf("torn tail: valid size %d, want %d", report.Size, len(lines[0]))
+	}
+}
+
+func TestAuditCheckpoints(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := writeAuditLog(t, nil, private, 2, "a", "b", "c")
+	verifier := &AuditVerifier {
+		PublicKey: public,
+	}
+	report, err := verifier.Verify(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Entries != 3 || report.Checkpoints != 2 || report.Unsigned != 0 || report.LastCheckpoint != 5 {
+		t.Fatalf("report: %+v", report)
+	}
+	otherPublic, _, _ := ed25519.GenerateKey(nil)
+	expectAuditFailure(t, &AuditVerifier {
+		PublicKey: otherPublic,
+	}, data, 3, "bad checkpoint signature")
+	lines := strings.SplitAfter(string(data), "\n")
+	resealed := &AuditLogger {
+		Writer: &bytes.Buffer{},
+		CheckpointKey: private,
+	}
+	resealed.appendEntry(AEK_PACKET, []byte(`{"level":"INFO"}`))
+	resealed.appendEntry(AEK_CHECKPOINT, []byte(`{"time":"","entries":2}`))
+	forged := resealed.Writer.(*bytes.Buffer).String()
+	expectAuditFailure(t, &AuditVerifier{}, []byte(forged), 2, "checkpoint covers 2 entries, but 1 precede it")
+	if _, err := (&AuditVerifier{}).Verify(strings.NewReader(lines[0] + lines[1])); err != nil {
+		t.Fatalf("unsigned verify: %v", err)
+	}
+}
+
+func TestAuditFileLoggerResumes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	key := []byte("resume key")
+	for session := 0; session < 2; session++ {
+		logger, err := AuditFileLogger(path, key, nil)
+		if err != nil {
+			t.Fatalf("session %d: %v", session, err)
+		}
+		logger.Log(auditPacket("entry"))
+		logger.Log(auditPacket("entry"))
+		logger.Close()
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	report, err := (&AuditVerifier {
+		HashKey: key,
+	}).Verify(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Entries != 4 || report.LastSequence != 4 {
+		t.Fatalf("report: %+v", report)
+	}
+	if _, err := AuditFileLogger(path, []byte("wrong key"), nil); err == nil {
+		t.Fatal("resumed a chain written with another key")
+	}
+}
+
+func TestAuditFileLoggerTruncatesTornTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	key := []byte("torn key")
+	data := writeAuditLog(t, key, nil, 0, "kept", "torn")
+	lines := strings.SplitAfter(string(data), "\n")
+	tail := lines[1][:len(lines[1]) / 2]
+	if err := os.WriteFile(path, []byte(lines[0] + tail), 0644); err != nil {
+		t.Fatal(err)
+	}
+	logger, err := AuditFileLogger(path, key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(logger.TornTail) != tail {
+		t.Fatalf("torn tail %q, want %q", logger.TornTail, tail)
+	}
+	logger.Log(auditPacket("after"))
+	logger.Close()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	report, err := (&AuditVerifier {
+		HashKey: key,
+	}).Verify(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Entries != 2 || report.TornLine != 0 {
+		t.Fatalf("report: %+v", report)
+	}
+}
+
+func TestAuditLoggerReportsWriteErrors(t *testing.T) {
+	var reported error
+	logger := &AuditLogger {
+		Writer: failingWriter{},
+		OnError: func(err error) {
+			reported = err
+		},
+	}
+	logger.Log(auditPacket("x"))
+	if reported != errFailingWriter || logger.sequence != 0 {
+		t.Fatalf("got %v at sequence %d", reported, logger.sequence)
+	}
+}
@@ -0,0 +1,168 @@
+package golog
+
+import (
+	"io"
+	"os"
+	"bufio"
+	"sync"
+	"bytes"
+	"errors"
+	"encoding/binary"
+)
+
+const BinaryLogMagic = "GOLOGB1\n"
+
+const MaxBinaryRecordSize = 64 << 20
+
+func EncodeBinaryRecord(packet *Packet) []byte {
+	sink := &CBORStructSink{}
+	PacketStructure {
+		Packet: packet,
+	}.PutStruct(sink)
+	payload := sink.ToBytes()
+	record := make([]byte, 0, binary.MaxVarintLen64 + len(payload))
+	record = binary.AppendUvarint(record, uint64(len(payload)))
+	return append(record, payload...)
+}
+
+type BinaryPacketEncoder struct {}
+
+func(enc BinaryPacketEncoder) EncodePacket(packet *Packet) ([]byte, error) {
+	return EncodeBinaryRecord(packet), nil
+}
+
+type BinaryLogger struct {
+	ID uintptr
+	Writer io.Writer
+	CloseStream func()
+	OnError func(error)
+	mutex sync.Mutex
+}
+
+func(logger *BinaryLogger) reportError(err error) {
+	if err != nil && logger.OnError != nil {
+		logger.OnError(err)
+	}
+}
+
+func(logger *BinaryLogger) Log(packet *Packet) {
+	if packet == nil || logger.Writer == nil {
+		return
+	}
+	record := EncodeBinaryRecord(packet)
+	logger.mutex.Lock()
+	_, err := logger.Writer.Write(record)
+	logger.mutex.Unlock()
+	logger.reportError(err)
+}
+
+func(logger *BinaryLogger) Close() {
+	if logger.CloseStream != nil {
+		logger.CloseStream()
+		logger.CloseStream = nil
+	}
+}
+
+func(logger *BinaryLogger) SubLoggers() []Logger {
+	return nil
+}
+
+func(logger *BinaryLogger) Identity() uintptr {
+	return logger.ID
+}
+
+func BinaryFileLogger(path string) (*BinaryLogger, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY | os.O_CREATE | os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err == nil && info.Size() == 0 {
+		_, err = f.WriteString(BinaryLogMagic)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	logger := &BinaryLogger {
+		ID: NewLoggerID(),
+		Writer: f,
+	}
+	logger.CloseStream = func() {
+		logger.reportError(f.Close())
+	}
+	return logger, nil
+}
+
+var ErrBinaryRecordTooLarge = errors.New("binary log record exceeds maximum size")
+
+type BinaryPacketReader struct {
+	reader *bufio.Reader
+	MaxRecordSize int
+}
+
+func NewBinaryPacketReader(reader io.Reader) *BinaryPacketReader {
+	return &BinaryPacketReader {
+		reader: bufio.NewReader(reader),
+	}
+}
+
+func(reader *BinaryPacketReader) skipMagic() error {
+	for {
+		peeked, err := reader.reader.Peek(len(BinaryLogMagic))
+		if len(peeked) == 0 && err != nil {
+			return err
+		}
+		if !bytes.Equal(peeked, []byte(BinaryLogMagic)) {
+			return nil
+		}
+		reader.reader.Discard(len(BinaryLogMagic))
+	}
+}
+
+func(reader *BinaryPacketReader) NextRecord() ([]byte, error) {
+	if err := reader.skipMagic(); err != nil {
+		return nil, err
+	}
+	size, err := binary.ReadUvarint(reader.reader)
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	limit := reader.MaxRecordSize
+	if limit <= 0 {
+		limit = MaxBinaryRecordSize
+	}
+	if size > uint64(limit) {
+		return nil, ErrBinaryRecordTooLarge
+	}
+	payload := make([]byte, int(size))
+	if _, err := io.ReadFull(reader.reader, payload); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return payload, nil
+}
+
+func(reader *BinaryPacketReader) NextValue() (*Value, error) {
+	payload, err := reader.NextRecord()
+	if err != nil {
+		return nil, err
+	}
+	return DecodeCBOR(payload)
+}
+
+func(reader *BinaryPacketReader) Next() (*Packet, error) {
+	value, err := reader.NextValue()
+	if err != nil {
+		return nil, err
+	}
+	return PacketFromValue(value)
+}
+
+var _ Logger = &BinaryLogger{}
+var _ PacketEncoder = BinaryPacketEncoder{}
@@ -0,0 +1,119 @@
+package golog
+
+import (
+	"io"
+	"errors"
+	"os"
+	"bytes"
+	"testing"
+	"path/filepath"
+)
+
+func TestBinaryFileLoggerRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.glb")
+	for session := 0; session < 2; session++ {
+		logger, err := BinaryFileLogger(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.Log(encoderPacket())
+		logger.Close()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte(BinaryLogMagic)) || bytes.Count(data, []byte(BinaryLogMagic)) != 1 {
+		t.Fatalf("magic not written exactly once: %q", data)
+	}
+	reader := NewBinaryPacketReader(bytes.NewReader(data))
+	for i := 0; i < 2; i++ {
+		packet, err := reader.Next()
+		if err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+		if packet.Level != ERROR || packet.Source.StringSource() != "app.db" {
+			t.Errorf("record %d header: %v %v", i, packet.Level, packet.Source)
+		}
+		if lines := packet.Message.Lines(); len(lines) != 2 || lines[1] != "retrying" {
+			t.Errorf("record %d lines: %q", i, lines)
+		}
+		if len(packet.Stack) != 1 || packet.Stack[0].Function != "main.run" {
+			t.Errorf("record %d stack: %v", i, packet.Stack)
+		}
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Fatalf("expected io.EOF at end, got %v", err)
+	}
+}
+
+func TestBinaryPacketReaderRepeatedMagic(t *testing.T) {
+	var buffer bytes.Buffer
+	buffer.WriteString(BinaryLogMagic)
+	buffer.Write(EncodeBinaryRecord(encoderPacket()))
+	buffer.WriteString(BinaryLogMagic)
+	buffer.WriteString(BinaryLogMagic)
+	buffer.Write(EncodeBinaryRecord(encoderPacket()))
+	reader := NewBinaryPacketReader(&buffer)
+	for i := 0; i < 2; i++ {
+		if _, err := reader.Next(); err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestBinaryPacketReaderErrors(t *testing.T) {
+	record := EncodeBinaryRecord(encoderPacket())
+	limited := NewBinaryPacketReader(bytes.NewReader(record))
+	limited.MaxRecordSize = 8
+	if _, err := limited.NextRecord(); err != ErrBinaryRecordTooLarge {
+		t.Errorf("oversized record: got %v", err)
+	}
+	truncated := NewBinaryPacketReader(bytes.NewReader(record[:len(record) - 3]))
+	if _, err := truncated.NextRecord(); err != io.ErrUnexpectedEOF {
+		t.Errorf("truncated payload: got %v", err)
+	}
+	header := NewBinaryPacketReader(bytes.NewReader([]byte { 0x80 }))
+	if _, err := header.NextRecord(); err != io.ErrUnexpectedEOF {
+		t.Errorf("truncated length: got %v", err)
+	}
+	empty := NewBinaryPacketReader(bytes.NewReader(nil))
+	if _, err := empty.NextRecord(); err != io.EOF {
+		t.Errorf("empty stream: got %v", err)
+	}
+}
+
+func TestBinaryLoggerReportsWriteErrors(t *testing.T) {
+	var reported error
+	logger := &BinaryLogger {
+		ID: NewLoggerID(),
+		Writer: failingWriter{},
+		OnError: func(err error) {
+			reported = err
+		},
+	}
+	logger.Log(encoderPacket())
+	if reported != errFailingWriter {
+		t.Fatalf("got %v", reported)
+	}
+}
+
+func TestBinaryFileLoggerReportsCloseErrors(t *testing.T) {
+	logger, err := BinaryFileLogger(filepath.Join(t.TempDir(), "app.glb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reported []error
+	logger.OnError = func(err error) {
+		reported = append(reported, err)
+	}
+	logger.Writer.(*os.File).Close()
+	logger.Close()
+	logger.Close()
+	if len(reported) != 1 || !errors.Is(reported[0], os.ErrClosed) {
+		t.Fatalf("close errors %v", reported)
+	}
+}
@@ -0,0 +1,172 @@
+package golog
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+	"bufio"
+)
+
+const DefaultFlushInterval = time.Second
+
+type BufferedTextLogger struct {
+	ID uintptr
+	Writer io.Writer
+	CloseStream func()
+	OnError func(error)
+	Formatter TextFormatter
+	Plain bool
+	BufferSize int
+	FlushInterval time.Duration
+	mutex sync.Mutex
+	buffer *bufio.Writer
+	scratch []byte
+	timer *time.Timer
+	closed bool
+}
+
+func(logger *BufferedTextLogger) reportError(err error) {
+	if err != nil && logger.OnError != nil {
+		logger.OnError(err)
+	}
+}
+
+func(logger *BufferedTextLogger) ensureBuffer() {
+	if logger.buffer != nil {
+		return
+	}
+	if logger.BufferSize > 0 {
+		logger.buffer = bufio.NewWriterSize(logger.Writer, logger.BufferSize)
+	} else {
+		logger.buffer = bufio.NewWriter(logger.Writer)
+	}
+}
+
+func(logger *BufferedTextLogger) discardOnError(err error) error {
+	if err != nil {
+		logger.buffer.Reset(logger.Writer)
+	}
+	return err
+}
+
+func(logger *BufferedTextLogger) write(text []byte) error {
+	if logger.Plain {
+		text = StripStyleBytes(text)
+	}
+	_, err := logger.buffer.Write(text)
+	return logger.discardOnError(err)
+}
+
+func(logger *BufferedTextLogger) flush() error {
+	if logger.timer != nil {
+		logger.timer.Stop()
+		logger.timer = nil
+	}
+	if logger.buffer == nil || logger.buffer.Buffered() == 0 {
+		return nil
+	}
+	return logger.discardOnError(logger.buffer.Flush())
+}
+
+func(logger *BufferedTextLogger) arm() {
+	if logger.timer != nil || logger.FlushInterval < 0 || logger.buffer.Buffered() == 0 {
+		return
+	}
+	interval := logger.FlushInterval
+	if interval == 0 {
+		interval = DefaultFlushInterval
+	}
+	logger.timer = time.AfterFunc(interval, logger.timedFlush)
+}
+
+func(logger *BufferedTextLogger) timedFlush() {
+	logger.mutex.Lock()
+	err := logger.flush()
+	logger.mutex.Unlock()
+	logger.reportError(err)
+}
+
+func(logger *BufferedTextLogger) Log(packet *Packet) {
+	if packet == nil || packet.Message == nil || logger.Writer == nil {
+		return
+	}
+	logger.mutex.Lock()
+	if logger.closed {
+		logger.mutex.Unlock()
+		return
+	}
+	logger.ensureBuffer()
+	logger.scratch = AppendPacketText(logger.scratch[:0], packet, logger.Formatter)
+	var err error
+	if len(logger.scratch) > 0 {
+		err = logger.write(logger.scratch)
+	}
+	if err == nil {
+		if packet.Level != nil && !packet.Level.IsNominal() {
+			err = logger.flush()
+		} else {
+			logger.arm()
+		}
+	}
+	if cap(logger.scratch) > maxPooledBufferSize {
+		logger.scratch = nil
+	}
+	logger.mutex.Unlock()
+	logger.reportError(err)
+}
+
+func(logger *BufferedTextLogger) FlushErr() error {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	return logger.flush()
+}
+
+func(logger *BufferedTextLogger) Flush() {
+	logger.reportError(logger.FlushErr())
+}
+
+func(logger *BufferedTextLogger) Close() {
+	logger.mutex.Lock()
+	if logger.closed {
+		logger.mutex.Unlock()
+		return
+	}
+	logger.closed = true
+	err := logger.flush()
+	closeStream := logger.CloseStream
+	logger.CloseStream = nil
+	logger.mutex.Unlock()
+	logger.reportError(err)
+	if closeStream != nil {
+		closeStream()
+	}
+}
+
+func(logger *BufferedTextLogger) SubLoggers() []Logger {
+	return nil
+}
+
+func(logger *BufferedTextLogger) Identity() uintptr {
+	return logger.ID
+}
+
+func BufferedTextFileLogger(path string, formatter TextFormatter) (*BufferedTextLogger, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY | os.O_CREATE | os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	logger := &BufferedTextLogger {
+		ID: NewLoggerID(),
+		Writer: f,
+		Formatter: formatter,
+		Plain: plainFallback(f),
+	}
+	logger.CloseStream = func() {
+		logger.reportError(f.Close())
+	}
+	return logger, nil
+}
+
+var _ Logger = &BufferedTextLogger{}
+var _ Flusher = &BufferedTextLogger{}
@@ -0,0 +1,190 @@
+package golog
+
+import (
+	"os"
+	"time"
+	"strings"
+	"testing"
+	"path/filepath"
+)
+
+func levelPacket(level Level, lines ...string) *Packet {
+	return &Packet {
+		Level: level,
+		Message: &StringMessage {
+			Text: lines,
+		},
+	}
+}
+
+func newBufferedLogger(writer *recordingWriter) *BufferedTextLogger {
+	return &BufferedTextLogger {
+		ID: NewLoggerID(),
+		Writer: writer,
+		FlushInterval: -1,
+	}
+}
+
+func TestBufferedTextLoggerHoldsNominalLines(t *testing.T) {
+	writer := &recordingWriter{}
+	logger := newBufferedLogger(writer)
+	logger.Log(levelPacket(INFO, "one", "two"))
+	logger.Log(levelPacket(DEBUG, "three"))
+	if got := writer.String(); got != "" {
+		t.Fatalf("nominal lines written before flush: %q", got)
+	}
+	if err := logger.FlushErr(); err != nil {
+		t.Fatal(err)
+	}
+	if got := writer.String(); got != "one\ntwo\nthree\n" {
+		t.Fatalf("flushed %q", got)
+	}
+	if writer.writes != 1 {
+		t.Fatalf("flush took %d writes", writer.writes)
+	}
+	if err := logger.FlushErr(); err != nil || writer.writes != 1 {
+		t.Fatalf("empty flush wrote: %v, %d writes", err, writer.writes)
+	}
+}
+
+func TestBufferedTextLoggerFlushesSevereLevels(t *testing.T) {
+	writer := &recordingWriter{}
+	logger := newBufferedLogger(writer)
+	logger.Log(levelPacket(INFO, "context"))
+	logger.Log(levelPacket(ERROR, "failure"))
+	if got := writer.String(); got != "context\nfailure\n" {
+		t.Fatalf("error did not flush pending lines: %q", got)
+	}
+	logger.Log(levelPacket(nil, "unleveled"))
+	if got := writer.String(); got != "context\nfailure\n" {
+		t.Fatalf("nil level treated as severe: %q", got)
+	}
+}
+
+func TestBufferedTextLoggerTimerFlush(t *testing.T) {
+	writer := &recordingWriter{}
+	logger := newBufferedLogger(writer)
+	logger.FlushInterval = 5 * time.Millisecond
+	logger.Log(levelPacket(INFO, "later"))
+	deadline := time.Now().Add(2 * time.Second)
+	for writer.String() == "" {
+		if time.Now().After(deadline) {
+			t.Fatal("timer never flushed the buffer")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := writer.String(); got != "later\n" {
+		t.Fatalf("timer flushed %q", got)
+	}
+	logger.Close()
+}
+
+func TestBufferedTextLoggerBufferSize(t *testing.T) {
+	writer := &recordingWriter{}
+	logger := newBufferedLogger(writer)
+	logger.BufferSize = 16
+	logger.Log(levelPacket(INFO, "short"))
+	if got := writer.String(); got != "" {
+		t.Fatalf("small line written early: %q", got)
+	}
+	logger.Log(levelPacket(INFO, strings.Repeat("x", 20)))
+	if got := writer.String(); !strings.HasPrefix(got, "short\n") {
+		t.Fatalf("full buffer not written through: %q", got)
+	}
+	logger.Flush()
+	if got := writer.String(); got != "short\n" + strings.Repeat("x", 20) + "\n" {
+		t.Fatalf("final output %q", got)
+	}
+}
+
+func TestBufferedTextLoggerRecoversFromWriteErrors(t *testing.T) {
+	writer := &recordingWriter{}
+	logger := newBufferedLogger(writer)
+	var errs []error
+	logger.OnError = func(err error) {
+		errs = append(errs, err)
+	}
+	writer.setFailing(true)
+	logger.Log(levelPacket(ERROR, "lost"))
+	if len(errs) != 1 || errs[0] != errFailingWriter {
+		t.Fatalf("errors %v", errs)
+	}
+	writer.setFailing(false)
+	logger.Log(levelPacket(ERROR, "kept"))
+	if got := writer.String(); got != "kept\n" {
+		t.Fatalf("failed text was not discarded: %q", got)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("healthy write reported %v", errs[1:])
+	}
+	writer.setFailing(true)
+	logger.Log(levelPacket(INFO, "pending"))
+	if err := logger.FlushErr(); err != errFailingWriter {
+		t.Fatalf("FlushErr returned %v", err)
+	}
+	logger.Flush()
+	if len(errs) != 1 {
+		t.Fatal("discarded buffer was flushed again")
+	}
+}
+
+func TestBufferedTextLoggerClose(t *testing.T) {
+	writer := &recordingWriter{}
+	logger := newBufferedLogger(writer)
+	closed := 0
+	logger.CloseStream = func() {
+		closed++
+	}
+	logger.Log(levelPacket(INFO, "tail"))
+	logger.Close()
+	logger.Close()
+	if closed != 1 {
+		t.Fatalf("stream closed %d times", closed)
+	}
+	if got := writer.String(); got != "tail\n" {
+		t.Fatalf("close did not flush: %q", got)
+	}
+	logger.Log(levelPacket(ERROR, "after"))
+	if err := logger.FlushErr(); err != nil {
+		t.Fatal(err)
+	}
+	if got := writer.String(); got != "tail\n" {
+		t.Fatalf("closed logger still wrote: %q", got)
+	}
+}
+
+func TestBufferedTextLoggerPlain(t *testing.T) {
+	styled := "\x1b[31mred\x1b[0m"
+	for _, plain := range []bool { false, true } {
+		writer := &recordingWriter{}
+		logger := newBufferedLogger(writer)
+		logger.Plain = plain
+		logger.Log(levelPacket(ERROR, styled))
+		want := styled + "\n"
+		if plain {
+			want = "red\n"
+		}
+		if got := writer.String(); got != want {
+			t.Errorf("plain=%v: got %q, want %q", plain, got, want)
+		}
+	}
+}
+
+func TestBufferedTextFileLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	for _, line := range []string { "first", "second" } {
+		logger, err := BufferedTextFileLogger(path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.Log(levelPacket(INFO, line))
+		logger.Close()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "first\nsecond\n" {
+		t.Fatalf("file holds %q", data)
+	}
+}
@@ -3,6 +3,7 @@ package golog
 type DispatchingLogger struct {
 	ID uintptr
 	Rules []*DispatchRule
+	enabled EnabledCache
 }
 
 type DispatchRule struct {
@@ -28,6 +29,45 @@ func(logger *DispatchingLogger) Log(packet *Packet) {
 	}
 }
 
+func(logger *DispatchingLogger) Enabled(level Level, source Source) bool {
+	return logger.enabled.Lookup(level, source, func() bool {
+		return logger.computeEnabled(level, source)
+	})
+}
+
+func(logger *DispatchingLogger) computeEnabled(level Level, source Source) bool {
+	for _, rule := range logger.Rules {
+		if rule == nil {
+			continue
+		}
+		matches, known := PredicateEnabled(rule.Condition, level, source)
+		if known && !matches {
+			continue
+		}
+		if rule.Logger != nil && LoggerEnabled(rule.Logger, level, source) {
+			return true
+		}
+		if !known {
+			continue
+		}
+		if rule.Continue == nil {
+			break
+		}
+		if proceed, known := PredicateEnabled(rule.Continue, level, source); known && !proceed {
+			break
+		}
+	}
+	return false
+}
+
+func(logger *DispatchingLogger) enabledCache() *EnabledCache {
+	return &logger.enabled
+}
+
+func(logger *DispatchingLogger) Invalidate() {
+	InvalidateLoggers(logger)
+}
+
 func(logger *DispatchingLogger) Close() {
 	for _, rule := range logger.Rules {
 		if rule != nil && rule.Logger != nil {
@@ -51,3 +91,5 @@ func(logger *DispatchingLogger) Identity() uintptr {
 }
 
 var _ Logger = &DispatchingLogger{}
+var _ EnabledChecker = &DispatchingLogger{}
+var _ EnabledInvalidator = &DispatchingLogger{}
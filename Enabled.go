@@ -0,0 +1,154 @@
+package golog
+
+import (
+	"sync"
+	"reflect"
+	"sync/atomic"
+)
+
+type EnabledChecker interface {
+	Enabled(level Level, source Source) bool
+}
+
+type EnabledPredicate interface {
+	MatchEnabled(level Level, source Source) (result bool, known bool)
+}
+
+func LoggerEnabled(logger Logger, level Level, source Source) bool {
+	if logger == nil {
+		return false
+	}
+	if checker, ok := logger.(EnabledChecker); ok {
+		return checker.Enabled(level, source)
+	}
+	return true
+}
+
+func PredicateEnabled[SubjectT any](pred Predicate[SubjectT], level Level, source Source) (bool, bool) {
+	if pred == nil {
+		return true, true
+	}
+	if enabled, ok := pred.(EnabledPredicate); ok {
+		return enabled.MatchEnabled(level, source)
+	}
+	return false, false
+}
+
+var enabledGeneration atomic.Uint64
+
+func InvalidateEnabledCaches() {
+	enabledGeneration.Add(1)
+}
+
+type EnabledInvalidator interface {
+	Invalidate()
+}
+
+type enabledCacheHolder interface {
+	enabledCache() *EnabledCache
+}
+
+type invalidateWalker struct {}
+
+func(walker invalidateWalker) EnterLogger(logger Logger, index int, depth uint, leaf bool) bool {
+	switch holder := logger.(type) {
+		case enabledCacheHolder:
+			holder.enabledCache().Invalidate()
+		case EnabledInvalidator:
+			holder.Invalidate()
+			return true
+	}
+	return false
+}
+
+func(walker invalidateWalker) LeaveLogger(logger Logger, index int, depth uint, leaf bool) {}
+
+func(walker invalidateWalker) SkipLogger(logger Logger, index int, depth uint, leaf bool, seen bool) {}
+
+func InvalidateLoggers(root Logger) {
+	if root != nil {
+		WalkLoggers(root, invalidateWalker{})
+	}
+}
+
+const MaxEnabledCacheSize = 1024
+
+type enabledKey struct {
+	number int
+	name string
+	source any
+	text string
+	fields DefaultSource
+}
+
+type textSourceKey struct {}
+
+type defaultSourceKey struct {}
+
+type EnabledCache struct {
+	mutex sync.RWMutex
+	generation uint64
+	invalidations uint64
+	entries map[enabledKey]bool
+}
+
+func makeEnabledKey(level Level, source Source) enabledKey {
+	var key enabledKey
+	if level != nil {
+		key.number = level.Numerical()
+		key.name = level.HumanReadable(ADJ_NONE)
+	}
+	switch src := source.(type) {
+		case nil:
+		case TextSource:
+			key.source = textSourceKey{}
+			key.text = string(src)
+		case *DefaultSource:
+			if src == nil {
+				key.source = src
+			} else {
+				key.source = defaultSourceKey{}
+				key.fields = *src
+			}
+		default:
+			if reflect.ValueOf(source).Comparable() {
+				key.source = source
+			} else {
+				key.source = reflect.TypeOf(source)
+				key.text = source.StringSource()
+			}
+	}
+	return key
+}
+
+func(cache *EnabledCache) Lookup(level Level, source Source, compute func() bool) bool {
+	key := makeEnabledKey(level, source)
+	generation := enabledGeneration.Load()
+	cache.mutex.RLock()
+	result, found := cache.entries[key]
+	found = found && cache.generation == generation
+	invalidations := cache.invalidations
+	cache.mutex.RUnlock()
+	if found {
+		return result
+	}
+	result = compute()
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cache.invalidations != invalidations {
+		return result
+	}
+	if cache.entries == nil || cache.generation != generation || len(cache.entries) >= MaxEnabledCacheSize {
+		cache.entries = make(map[enabledKey]bool)
+		cache.generation = generation
+	}
+	cache.entries[key] = result
+	return result
+}
+
+func(cache *EnabledCache) Invalidate() {
+	cache.mutex.Lock()
+	cache.entries = nil
+	cache.invalidations++
+	cache.mutex.Unlock()
+}
@@ -0,0 +1,155 @@
+package golog
+
+import (
+	"strconv"
+	"testing"
+)
+
+func thresholdDispatcher(threshold Level, target Logger) (*DispatchingLogger, *LevelOrderPredicate) {
+	order := &LevelOrderPredicate {
+		Threshold: threshold.Numerical(),
+		Relation: ORDR_GREATER_EQUAL,
+	}
+	return &DispatchingLogger {
+		ID: NewLoggerID(),
+		Rules: []*DispatchRule {
+			{
+				Condition: &LevelPredicate {
+					Predicate: order,
+				},
+				Logger: target,
+			},
+		},
+	}, order
+}
+
+func TestEnabledInvalidationScope(t *testing.T) {
+	dispatcher, order := thresholdDispatcher(WARNING, newCaptureLogger())
+	parent := &MultiLogger {
+		ID: NewLoggerID(),
+		Children: []Logger { dispatcher },
+	}
+	sibling, siblingOrder := thresholdDispatcher(WARNING, newCaptureLogger())
+	source := TextSource("app")
+	if parent.Enabled(INFO, source) || !parent.Enabled(ERROR, source) || sibling.Enabled(INFO, source) {
+		t.Fatal("initial threshold not applied")
+	}
+	order.Threshold = DEBUG.Numerical()
+	siblingOrder.Threshold = DEBUG.Numerical()
+	if parent.Enabled(INFO, source) {
+		t.Fatal("cache not consulted before invalidation")
+	}
+	parent.Invalidate()
+	if !parent.Enabled(INFO, source) || !dispatcher.Enabled(INFO, source) {
+		t.Fatal("invalidating the parent did not reach its children")
+	}
+	if sibling.Enabled(INFO, source) {
+		t.Fatal("invalidation cleared an unrelated cache")
+	}
+	order.Threshold = FATAL.Numerical()
+	InvalidateEnabledCaches()
+	if parent.Enabled(ERROR, source) || dispatcher.Enabled(ERROR, source) || !sibling.Enabled(INFO, source) {
+		t.Fatal("global invalidation ignored")
+	}
+}
+
+type sourceTypePredicate string
+
+func(pred sourceTypePredicate) Match(source Source) bool {
+	src, ok := source.(*DefaultSource)
+	return ok && src.Type == string(pred)
+}
+
+func TestEnabledCacheKeysSourcesByValue(t *testing.T) {
+	dispatcher := &DispatchingLogger {
+		ID: NewLoggerID(),
+		Rules: []*DispatchRule {
+			{
+				Condition: &SourcePredicate {
+					Predicate: sourceTypePredicate("b"),
+				},
+				Logger: newCaptureLogger(),
+			},
+		},
+	}
+	module := &DefaultSource {
+		Module: "a.b",
+	}
+	typed := &DefaultSource {
+		Module: "a",
+		Type: "b",
+	}
+	if module.StringSource() != typed.StringSource() {
+		t.Fatalf("sources render differently: %q, %q", module.StringSource(), typed.StringSource())
+	}
+	if dispatcher.Enabled(INFO, module) || !dispatcher.Enabled(INFO, typed) {
+		t.Fatal("sources with equal text shared a cache entry")
+	}
+}
+
+func TestEnabledCacheKeys(t *testing.T) {
+	var cache EnabledCache
+	calls := 0
+	lookup := func(level Level, source Source) bool {
+		return cache.Lookup(level, source, func() bool {
+			calls++
+			return true
+		})
+	}
+	lookup(INFO, TextSource("a"))
+	lookup(INFO, TextSource("a"))
+	if calls != 1 {
+		t.Fatalf("repeated lookup computed %d times", calls)
+	}
+	lookup(INFO, TextSource("b"))
+	lookup(WARNING, TextSource("a"))
+	lookup(&GenericLevel {
+		Number: INFO.Numerical(),
+		Name: "NOTICE",
+	}, TextSource("a"))
+	lookup(nil, nil)
+	if calls != 5 {
+		t.Fatalf("distinct keys computed %d times, want 5", calls)
+	}
+	before := calls
+	lookup(INFO, &DefaultSource {
+		Module: "m",
+	})
+	lookup(INFO, &DefaultSource {
+		Module: "m",
+	})
+	if calls != before + 1 {
+		t.Fatalf("equal sources behind distinct pointers computed %d times", calls - before)
+	}
+	for i := 0; i < MaxEnabledCacheSize + 10; i++ {
+		lookup(INFO, TextSource("s" + strconv.Itoa(i)))
+	}
+	cache.mutex.RLock()
+	size := len(cache.entries)
+	cache.mutex.RUnlock()
+	if size > MaxEnabledCacheSize {
+		t.Fatalf("cache grew to %d entries", size)
+	}
+}
+
+type plainLogger struct {
+	captureLogger
+}
+
+func TestLoggerEnabledDefaults(t *testing.T) {
+	if LoggerEnabled(nil, INFO, nil) {
+		t.Error("nil logger enabled")
+	}
+	if !LoggerEnabled(&plainLogger{}, DEBUG, nil) {
+		t.Error("logger without EnabledChecker disabled")
+	}
+	if result, known := PredicateEnabled[*Packet](nil, INFO, nil); !result || !known {
+		t.Error("nil predicate not known to match")
+	}
+	log := &Log {
+		Logger: &MultiLogger{},
+	}
+	if log.Enabled(FATAL, nil) {
+		t.Error("empty MultiLogger enabled")
+	}
+}
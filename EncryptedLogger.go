@@ -0,0 +1,504 @@
+package golog
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+	"bufio"
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"crypto/aes"
+	"crypto/rand"
+	"crypto/cipher"
+	"encoding/binary"
+)
+
+const EncryptedLogMagic = "GOLOGE1\n"
+
+const (
+	encryptedChunkFrame byte = 'C'
+	encryptedNonceSize = 12
+	maxEncryptedKeyIDSize = 255
+)
+
+const MaxEncryptedChunkSize = 64 << 20
+
+const DefaultEncryptedChunkSize = 64 << 10
+
+type EncryptionKeyFunc func(keyID string) ([]byte, error)
+
+var ErrEncryptedChunkTooLarge = errors.New("encrypted log chunk exceeds maximum size")
+
+type EncryptedLogError struct {
+	Offset int64
+	Reason string
+}
+
+func(err *EncryptedLogError) Error() string {
+	return "invalid encrypted log at offset " + strconv.FormatInt(err.Offset, 10) + ": " + err.Reason
+}
+
+func newLogCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func encryptedSegmentHeader(keyID string) []byte {
+	header := make([]byte, 0, len(EncryptedLogMagic) + 1 + len(keyID))
+	header = append(header, EncryptedLogMagic...)
+	header = append(header, byte(len(keyID)))
+	return append(header, keyID...)
+}
+
+func encryptedChunkAAD(header []byte, index uint64) []byte {
+	aad := make([]byte, len(header) + 8)
+	copy(aad, header)
+	binary.BigEndian.PutUint64(aad[len(header):], index)
+	return aad
+}
+
+type EncryptedLogger struct {
+	ID uintptr
+	Writer io.Writer
+	CloseStream func()
+	OnError func(error)
+	Formatter TextFormatter
+	ChunkSize int
+	FlushInterval time.Duration
+	mutex sync.Mutex
+	timer *time.Timer
+	aead cipher.AEAD
+	header []byte
+	index uint64
+	buffer []byte
+}
+
+func(logger *EncryptedLogger) Rotate(keyID string, key []byte) error {
+	if len(keyID) > maxEncryptedKeyIDSize {
+		return errors.New("encryption key ID exceeds 255 bytes")
+	}
+	aead, err := newLogCipher(key)
+	if err != nil {
+		return err
+	}
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	if err := logger.flush(); err != nil {
+		return err
+	}
+	header := encryptedSegmentHeader(keyID)
+	if _, err := logger.Writer.Write(header); err != nil {
+		return err
+	}
+	logger.aead = aead
+	logger.header = header
+	logger.index = 0
+	return nil
+}
+
+func(logger *EncryptedLogger) flush() error {
+	if logger.timer != nil {
+		logger.timer.Stop()
+		logger.timer = nil
+	}
+	if len(logger.buffer) == 0 {
+		return nil
+	}
+	if logger.aead == nil {
+		return errors.New("encrypted logger has no key; call Rotate first")
+	}
+	size := encryptedNonceSize + len(logger.buffer) + logger.aead.Overhead()
+	frame := make([]byte, 0, 1 + binary.MaxVarintLen64 + size)
+	frame = append(frame, encryptedChunkFrame)
+	frame = binary.AppendUvarint(frame, uint64(size))
+	nonceStart := len(frame)
+	frame = frame[:nonceStart + encryptedNonceSize]
+	if _, err := io.ReadFull(rand.Reader, frame[nonceStart:]); err != nil {
+		return err
+	}
+	frame = logger.aead.Seal(frame, frame[nonceStart:], logger.buffer, encryptedChunkAAD(logger.header, logger.index))
+	logger.buffer = logger.buffer[:0]
+	if _, err := logger.Writer.Write(frame); err != nil {
+		return err
+	}
+	logger.index++
+	return nil
+}
+
+func(logger *EncryptedLogger) arm() {
+	if logger.timer != nil || logger.FlushInterval < 0 || len(logger.buffer) == 0 {
+		return
+	}
+	interval := logger.FlushInterval
+	if interval == 0 {
+		interval = DefaultFlushInterval
+	}
+	logger.timer = time.AfterFunc(interval, logger.timedFlush)
+}
+
+func(logger *EncryptedLogger) timedFlush() {
+	logger.mutex.Lock()
+	err := logger.flush()
+	logger.mutex.Unlock()
+	logger.reportError(err)
+}
+
+func(logger *EncryptedLogger) FlushErr() error {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	return logger.flush()
+}
+
+func(logger *EncryptedLogger) Flush() {
+	logger.reportError(logger.FlushErr())
+}
+
+func(logger *EncryptedLogger) reportError(err error) {
+	if err != nil && logger.OnError != nil {
+		logger.OnError(err)
+	}
+}
+
+func(logger *EncryptedLogger) Log(packet *Packet) {
+	if packet == nil || logger.Writer == nil {
+		return
+	}
+	formatter := logger.Formatter
+	if formatter == nil {
+		formatter = JSONPacketEncoder{}
+	}
+	lines := formatter.PacketToText(packet)
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	for _, line := range lines {
+		logger.buffer = append(logger.buffer, line...)
+		logger.buffer = append(logger.buffer, '\n')
+	}
+	chunkSize := logger.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = DefaultEncryptedChunkSize
+	}
+	if chunkSize < 0 || len(logger.buffer) >= chunkSize || (packet.Level != nil && !packet.Level.IsNominal()) {
+		logger.reportError(logger.flush())
+	} else {
+		logger.arm()
+	}
+}
+
+func(logger *EncryptedLogger) Close() {
+	logger.Flush()
+	if logger.CloseStream != nil {
+		logger.CloseStream()
+		logger.CloseStream = nil
+	}
+}
+
+func(logger *EncryptedLogger) SubLoggers() []Logger {
+	return nil
+}
+
+func(logger *EncryptedLogger) Identity() uintptr {
+	return logger.ID
+}
+
+func EncryptedFileLogger(path string, keyID string, keys EncryptionKeyFunc) (*EncryptedLogger, error) {
+	key, err := keys(keyID)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR | os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	valid, err := scanEncryptedFrames(bufio.NewReader(f))
+	if err == nil {
+		err = f.Truncate(valid)
+	}
+	if err == nil {
+		_, err = f.Seek(valid, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	logger := &EncryptedLogger {
+		ID: NewLoggerID(),
+		Writer: f,
+		CloseStream: func() {
+			f.Close()
+		},
+	}
+	if err := logger.Rotate(keyID, key); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return logger, nil
+}
+
+func scanEncryptedFrames(reader *bufio.Reader) (int64, error) {
+	var valid, offset int64
+	for {
+		kind, err := reader.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return valid, nil
+			}
+			return 0, err
+		}
+		offset++
+		var size uint64
+		switch kind {
+			case EncryptedLogMagic[0]:
+				rest := make([]byte, len(EncryptedLogMagic))
+				count, err := io.ReadFull(reader, rest)
+				if err == io.EOF || err == io.ErrUnexpectedEOF {
+					return valid, nil
+				}
+				if err != nil {
+					return 0, err
+				}
+				if !bytes.Equal(rest[:len(rest) - 1], []byte(EncryptedLogMagic[1:])) {
+					return 0, &EncryptedLogError {
+						Offset: offset - 1,
+						Reason: "bad segment magic",
+					}
+				}
+				offset += int64(count)
+				size = uint64(rest[len(rest) - 1])
+			case encryptedChunkFrame:
+				size, err = binary.ReadUvarint(reader)
+				if err == io.EOF || err == io.ErrUnexpectedEOF {
+					return valid, nil
+				}
+				if err != nil {
+					return 0, err
+				}
+				if size > MaxEncryptedChunkSize {
+					return 0, ErrEncryptedChunkTooLarge
+				}
+				offset += int64(uvarintLength(size))
+			default:
+				return 0, &EncryptedLogError {
+					Offset: offset - 1,
+					Reason: "unknown frame type",
+				}
+		}
+		skipped, err := reader.Discard(int(size))
+		offset += int64(skipped)
+		if err == io.EOF {
+			return valid, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		valid = offset
+	}
+}
+
+func uvarintLength(value uint64) int {
+	length := 1
+	for value >= 0x80 {
+		value >>= 7
+		length++
+	}
+	return length
+}
+
+type EncryptedLogReader struct {
+	reader *bufio.Reader
+	keys EncryptionKeyFunc
+	offset int64
+	aead cipher.AEAD
+	header []byte
+	frame []byte
+	index uint64
+	plain []byte
+	lines *bufio.Reader
+	err error
+	MaxChunkSize int
+}
+
+func NewEncryptedLogReader(reader io.Reader, keys EncryptionKeyFunc) *EncryptedLogReader {
+	er := &EncryptedLogReader {
+		reader: bufio.NewReader(reader),
+		keys: keys,
+	}
+	er.lines = bufio.NewReader(er)
+	return er
+}
+
+func(reader *EncryptedLogReader) fail(at int, reason string) error {
+	return &EncryptedLogError {
+		Offset: reader.offset + int64(at),
+		Reason: reason,
+	}
+}
+
+func(reader *EncryptedLogReader) fill(size int) error {
+	if cap(reader.frame) < size {
+		frame := make([]byte, len(reader.frame), size)
+		copy(frame, reader.frame)
+		reader.frame = frame
+	}
+	for len(reader.frame) < size {
+		count, err := reader.reader.Read(reader.frame[len(reader.frame):size])
+		reader.frame = reader.frame[:len(reader.frame) + count]
+		if err != nil && len(reader.frame) < size {
+			if err == io.EOF && len(reader.frame) > 0 {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func(reader *EncryptedLogReader) consume() {
+	reader.offset += int64(len(reader.frame))
+	reader.frame = reader.frame[:0]
+}
+
+func(reader *EncryptedLogReader) readSegmentHeader() (bool, error) {
+	size := len(EncryptedLogMagic) + 1
+	if err := reader.fill(size); err != nil {
+		return false, err
+	}
+	if string(reader.frame[:len(EncryptedLogMagic)]) != EncryptedLogMagic {
+		return true, reader.fail(0, "bad segment magic")
+	}
+	if err := reader.fill(size + int(reader.frame[size - 1])); err != nil {
+		return false, err
+	}
+	key, err := reader.keys(string(reader.frame[size:]))
+	if err != nil {
+		return true, err
+	}
+	aead, err := newLogCipher(key)
+	if err != nil {
+		return true, err
+	}
+	reader.aead = aead
+	reader.header = append([]byte(nil), reader.frame...)
+	reader.index = 0
+	reader.consume()
+	return false, nil
+}
+
+func(reader *EncryptedLogReader) NextChunk() ([]byte, error) {
+	if reader.err != nil {
+		return nil, reader.err
+	}
+	chunk, fatal, err := reader.nextChunk()
+	if fatal {
+		reader.err = err
+	}
+	return chunk, err
+}
+
+func(reader *EncryptedLogReader) nextChunk() ([]byte, bool, error) {
+	for {
+		if err := reader.fill(1); err != nil {
+			return nil, false, err
+		}
+		switch reader.frame[0] {
+			case EncryptedLogMagic[0]:
+				if fatal, err := reader.readSegmentHeader(); err != nil {
+					return nil, fatal, err
+				}
+			case encryptedChunkFrame:
+				if reader.aead == nil {
+					return nil, true, reader.fail(0, "chunk before segment header")
+				}
+				size, length := binary.Uvarint(reader.frame[1:])
+				for length == 0 {
+					if len(reader.frame) > binary.MaxVarintLen64 {
+						return nil, true, reader.fail(1, "bad chunk size")
+					}
+					if err := reader.fill(len(reader.frame) + 1); err != nil {
+						return nil, false, err
+					}
+					size, length = binary.Uvarint(reader.frame[1:])
+				}
+				if length < 0 {
+					return nil, true, reader.fail(1, "bad chunk size")
+				}
+				header := 1 + length
+				limit := reader.MaxChunkSize
+				if limit <= 0 {
+					limit = MaxEncryptedChunkSize
+				}
+				if size > uint64(limit) {
+					return nil, true, ErrEncryptedChunkTooLarge
+				}
+				if size < uint64(encryptedNonceSize + reader.aead.Overhead()) {
+					return nil, true, reader.fail(header, "chunk too short")
+				}
+				if err := reader.fill(header + int(size)); err != nil {
+					return nil, false, err
+				}
+				sealed := reader.frame[header:]
+				plain, err := reader.aead.Open(nil, sealed[:encryptedNonceSize], sealed[encryptedNonceSize:],
+						encryptedChunkAAD(reader.header, reader.index))
+				if err != nil {
+					return nil, true, reader.fail(header, "chunk authentication failed")
+				}
+				reader.consume()
+				reader.index++
+				return plain, false, nil
+			default:
+				return nil, true, reader.fail(0, "unknown frame type")
+		}
+	}
+}
+
+func(reader *EncryptedLogReader) Read(buffer []byte) (int, error) {
+	for len(reader.plain) == 0 {
+		chunk, err := reader.NextChunk()
+		if err != nil {
+			return 0, err
+		}
+		reader.plain = chunk
+	}
+	count := copy(buffer, reader.plain)
+	reader.plain = reader.plain[count:]
+	return count, nil
+}
+
+func(reader *EncryptedLogReader) NextLine() (string, error) {
+	line, err := reader.lines.ReadString('\n')
+	if len(line) == 0 && err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func(reader *EncryptedLogReader) Next() (*Packet, error) {
+	for {
+		line, err := reader.NextLine()
+		if err != nil {
+			return nil, err
+		}
+		trimmed := strings.TrimSpace(line)
+		if len(trimmed) == 0 {
+			continue
+		}
+		if !strings.HasPrefix(trimmed, "{") {
+			return ParseLogfmt(trimmed)
+		}
+		value, err := DecodeJSON([]byte(trimmed))
+		if err != nil {
+			return nil, err
+		}
+		return PacketFromValue(value)
+	}
+}
+
+var _ Logger = &EncryptedLogger{}
+var _ Flusher = &EncryptedLogger{}
+var _ io.Reader = &EncryptedLogReader{}
@@ -0,0 +1,268 @@
+package golog
+
+import (
+	"io"
+	"os"
+	"bytes"
+	"errors"
+	"time"
+	"testing"
+	"path/filepath"
+)
+
+var testEncryptionKeys = map[string][]byte {
+	"k1": bytes.Repeat([]byte { 1 }, 32),
+	"k2": bytes.Repeat([]byte { 2 }, 16),
+}
+
+func testEncryptionKey(keyID string) ([]byte, error) {
+	key, ok := testEncryptionKeys[keyID]
+	if !ok {
+		return nil, errors.New("unknown key " + keyID)
+	}
+	return key, nil
+}
+
+func encryptedPacket(level Level, text string) *Packet {
+	return &Packet {
+		Level: level,
+		Source: TextSource("vault"),
+		Message: &StringMessage {
+			Text: []string { text },
+		},
+	}
+}
+
+func newTestEncryptedLogger(t *testing.T, writer io.Writer, chunkSize int) *EncryptedLogger {
+	t.Helper()
+	logger := &EncryptedLogger {
+		ID: NewLoggerID(),
+		Writer: writer,
+		ChunkSize: chunkSize,
+		FlushInterval: -1,
+		OnError: func(err error) {
+			t.Errorf("encrypted logger: %v", err)
+		},
+	}
+	if err := logger.Rotate("k1", testEncryptionKeys["k1"]); err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+func readEncryptedTexts(t *testing.T, data []byte) ([]string, error) {
+	t.Helper()
+	reader := NewEncryptedLogReader(bytes.NewReader(data), testEncryptionKey)
+	var texts []string
+	for {
+		packet, err := reader.Next()
+		if err == io.EOF {
+			return texts, nil
+		}
+		if err != nil {
+			return texts, err
+		}
+		texts = append(texts, packet.Message.Lines()[0])
+	}
+}
+
+func TestEncryptedLoggerRoundTripAcrossRotation(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := newTestEncryptedLogger(t, &buffer, -1)
+	logger.Log(encryptedPacket(INFO, "one"))
+	if err := logger.Rotate("k2", testEncryptionKeys["k2"]); err != nil {
+		t.Fatal(err)
+	}
+	logger.Log(encryptedPacket(WARNING, "two"))
+	logger.Close()
+	if bytes.Contains(buffer.Bytes(), []byte("one")) {
+		t.Fatal("plaintext written")
+	}
+	texts, err := readEncryptedTexts(t, buffer.Bytes())
+	if err != nil || len(texts) != 2 || texts[0] != "one" || texts[1] != "two" {
+		t.Fatalf("got %q, %v", texts, err)
+	}
+}
+
+func TestEncryptedLoggerChunking(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := newTestEncryptedLogger(t, &buffer, 0)
+	headerSize := buffer.Len()
+	logger.Log(encryptedPacket(INFO, "buffered"))
+	logger.Log(encryptedPacket(nil, "no level"))
+	if buffer.Len() != headerSize {
+		t.Fatal("default chunk size sealed a nominal record immediately")
+	}
+	logger.Log(encryptedPacket(ERROR, "urgent"))
+	if buffer.Len() == headerSize {
+		t.Fatal("non-nominal record not flushed")
+	}
+	afterError := buffer.Len()
+	logger.Log(encryptedPacket(INFO, "later"))
+	FlushLoggers(logger)
+	if buffer.Len() == afterError {
+		t.Fatal("FlushLoggers did not seal the pending chunk")
+	}
+	texts, err := readEncryptedTexts(t, buffer.Bytes())
+	if err != nil || len(texts) != 4 {
+		t.Fatalf("got %q, %v", texts, err)
+	}
+	var unbuffered bytes.Buffer
+	each := newTestEncryptedLogger(t, &unbuffered, -1)
+	before := unbuffered.Len()
+	each.Log(encryptedPacket(INFO, "sealed"))
+	if unbuffered.Len() == before {
+		t.Fatal("negative chunk size buffered a record")
+	}
+}
+
+func TestEncryptedLoggerTimerFlush(t *testing.T) {
+	writer := &recordingWriter{}
+	logger := newTestEncryptedLogger(t, writer, 0)
+	logger.FlushInterval = 5 * time.Millisecond
+	header := writer.String()
+	logger.Log(encryptedPacket(INFO, "idle"))
+	deadline := time.Now().Add(2 * time.Second)
+	for writer.String() == header {
+		if time.Now().After(deadline) {
+			t.Fatal("timer never sealed the pending chunk")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	texts, err := readEncryptedTexts(t, []byte(writer.String()))
+	if err != nil || len(texts) != 1 || texts[0] != "idle" {
+		t.Fatalf("got %q, %v", texts, err)
+	}
+	logger.Close()
+}
+
+func TestEncryptedLogReaderDetectsTampering(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := newTestEncryptedLogger(t, &buffer, -1)
+	logger.Log(encryptedPacket(INFO, "first"))
+	firstEnd := buffer.Len()
+	logger.Log(encryptedPacket(INFO, "second"))
+	data := buffer.Bytes()
+	flipped := append([]byte(nil), data...)
+	flipped[len(flipped) - 1] ^= 1
+	texts, err := readEncryptedTexts(t, flipped)
+	var logErr *EncryptedLogError
+	if !errors.As(err, &logErr) || logErr.Reason != "chunk authentication failed" || len(texts) != 1 {
+		t.Fatalf("flipped byte: %q, %v", texts, err)
+	}
+	header := len(encryptedSegmentHeader("k1"))
+	swapped := append([]byte(nil), data[:header]...)
+	swapped = append(swapped, data[firstEnd:]...)
+	swapped = append(swapped, data[header:firstEnd]...)
+	if _, err := readEncryptedTexts(t, swapped); !errors.As(err, &logErr) {
+		t.Fatalf("reordered chunks: %v", err)
+	}
+	if _, err := readEncryptedTexts(t, data[:len(data) - 3]); err != io.ErrUnexpectedEOF {
+		t.Fatalf("truncated chunk: %v", err)
+	}
+	unknown := NewEncryptedLogReader(bytes.NewReader(encryptedSegmentHeader("k9")), testEncryptionKey)
+	if _, err := unknown.NextChunk(); err == nil {
+		t.Fatal("unknown key accepted")
+	}
+}
+
+type stallingReader struct {
+	data []byte
+	stalls int
+}
+
+var errStalled = errors.New("stalled")
+
+func(reader *stallingReader) Read(buffer []byte) (int, error) {
+	if len(reader.data) == 0 {
+		return 0, io.EOF
+	}
+	reader.stalls++
+	if reader.stalls % 2 == 1 {
+		return 0, errStalled
+	}
+	count := copy(buffer[:1], reader.data)
+	reader.data = reader.data[count:]
+	return count, nil
+}
+
+func TestEncryptedLogReaderResumesAfterReadErrors(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := newTestEncryptedLogger(t, &buffer, -1)
+	logger.Log(encryptedPacket(INFO, "first"))
+	logger.Log(encryptedPacket(INFO, "second"))
+	reader := NewEncryptedLogReader(&stallingReader {
+		data: buffer.Bytes(),
+	}, testEncryptionKey)
+	var texts []string
+	for {
+		packet, err := reader.Next()
+		if err == errStalled {
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		texts = append(texts, packet.Message.Lines()[0])
+	}
+	if len(texts) != 2 || texts[0] != "first" || texts[1] != "second" {
+		t.Fatalf("read %q", texts)
+	}
+}
+
+func TestEncryptedFileLoggerRecoversTruncatedTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret.log")
+	logger, err := EncryptedFileLogger(path, "k1", testEncryptionKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.ChunkSize = -1
+	logger.Log(encryptedPacket(INFO, "kept"))
+	logger.Log(encryptedPacket(INFO, "torn"))
+	logger.Close()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, info.Size() - 5); err != nil {
+		t.Fatal(err)
+	}
+	logger, err = EncryptedFileLogger(path, "k2", testEncryptionKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Log(encryptedPacket(INFO, "resumed"))
+	logger.Close()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	texts, err := readEncryptedTexts(t, data)
+	if err != nil || len(texts) != 2 || texts[0] != "kept" || texts[1] != "resumed" {
+		t.Fatalf("got %q, %v", texts, err)
+	}
+}
+
+func TestEncryptedLoggerFlushErrors(t *testing.T) {
+	var reported []error
+	logger := &EncryptedLogger {
+		Writer: &bytes.Buffer{},
+		OnError: func(err error) {
+			reported = append(reported, err)
+		},
+	}
+	logger.Log(encryptedPacket(INFO, "no key yet"))
+	if err := logger.FlushErr(); err == nil {
+		t.Fatal("flush without key succeeded")
+	}
+	logger.Flush()
+	if len(reported) != 1 {
+		t.Fatalf("reported %v", reported)
+	}
+	if err := logger.Rotate("k", []byte("short")); err == nil {
+		t.Fatal("invalid AES key accepted")
+	}
+}
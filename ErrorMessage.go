@@ -0,0 +1,182 @@
+package golog
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	ErrorStructKey = "error"
+	ErrorTypeStructKey = "errorType"
+	ErrorChainStructKey = "errorChain"
+	CauseMessageStructKey = "message"
+	CauseTypeStructKey = "type"
+	CauseDepthStructKey = "depth"
+	CauseDetailsStructKey = "details"
+	DefaultCauseIndent = "  "
+)
+
+type ErrorCause struct {
+	Error error
+	Text string
+	Type string
+	Depth uint
+}
+
+type CausalMessage interface {
+	Message
+	Summary() string
+	Causes() []ErrorCause
+}
+
+type ErrorMessage struct {
+	Err error
+	Text string
+	Details Structure
+}
+
+func NewErrorMessage(err error, details Structure) *ErrorMessage {
+	return &ErrorMessage {
+		Err: err,
+		Details: details,
+	}
+}
+
+func(msg *ErrorMessage) Summary() string {
+	return msg.Text
+}
+
+func(msg *ErrorMessage) Causes() []ErrorCause {
+	return CollectErrorCauses(msg.Err)
+}
+
+func(msg *ErrorMessage) Lines() []string {
+	return CausesToLines(msg.Text, msg.Causes(), DefaultCauseIndent, "")
+}
+
+func(msg *ErrorMessage) PutStruct(sink StructSink) {
+	m := sink.Map()
+	var seen map[string]bool
+	if msg.Details != nil {
+		seen = make(map[string]bool)
+		msg.Details.PutStruct(&inlineSink {
+			target: m,
+			seen: seen,
+		})
+	}
+	if msg.Err != nil {
+		if !seen[ErrorStructKey] {
+			m.StringProperty(ErrorStructKey, msg.Err.Error())
+		}
+		if !seen[ErrorTypeStructKey] {
+			m.StringProperty(ErrorTypeStructKey, fmt.Sprintf("%T", msg.Err))
+		}
+		if !seen[ErrorChainStructKey] {
+			chain := m.ListProperty(ErrorChainStructKey)
+			for _, cause := range msg.Causes() {
+				putErrorCause(chain.Map(), &cause)
+			}
+			chain.EndList()
+		}
+	}
+	m.EndMap()
+}
+
+func putErrorCause(m StructMap, cause *ErrorCause) {
+	m.StringProperty(CauseMessageStructKey, cause.Text)
+	m.StringProperty(CauseTypeStructKey, cause.Type)
+	PutUintProperty(m, CauseDepthStructKey, uint64(cause.Depth))
+	if details, ok := cause.Error.(Structure); ok {
+		details.PutStruct(&propertySink {
+			target: m,
+			name: CauseDetailsStructKey,
+		})
+	}
+	m.EndMap()
+}
+
+func CollectErrorCauses(err error) []ErrorCause {
+	if err == nil {
+		return nil
+	}
+	return collectErrorCausesRec(err, 0, nil)
+}
+
+func collectErrorCausesRec(err error, depth uint, causes []ErrorCause) []ErrorCause {
+	own := err.Error()
+	children := unwrapError(err)
+	switch len(children) {
+		case 0:
+		case 1:
+			child := children[0].Error()
+			if own == child {
+				own = ""
+			} else if strings.HasSuffix(own, ": " + child) {
+				own = strings.TrimSuffix(own, ": " + child)
+			}
+		default:
+			texts := make([]string, len(children))
+			for index, child := range children {
+				texts[index] = child.Error()
+			}
+			if own == strings.Join(texts, "\n") {
+				own = ""
+			}
+	}
+	next := depth
+	if len(own) > 0 {
+		causes = append(causes, ErrorCause {
+			Error: err,
+			Text: own,
+			Type: fmt.Sprintf("%T", err),
+			Depth: depth,
+		})
+		next++
+	}
+	for _, child := range children {
+		causes = collectErrorCausesRec(child, next, causes)
+	}
+	return causes
+}
+
+func unwrapError(err error) []error {
+	var children []error
+	switch wrapper := err.(type) {
+		case interface { Unwrap() error }:
+			if child := wrapper.Unwrap(); child != nil {
+				children = append(children, child)
+			}
+		case interface { Unwrap() []error }:
+			for _, child := range wrapper.Unwrap() {
+				if child != nil {
+					children = append(children, child)
+				}
+			}
+	}
+	return children
+}
+
+func CausesToLines(summary string, causes []ErrorCause, indent string, causePrefix string) []string {
+	var lines []string
+	var shift uint
+	if len(summary) > 0 {
+		lines = append(lines, strings.Split(summary, "\n")...)
+		shift = 1
+	}
+	for index, cause := range causes {
+		depth := cause.Depth + shift
+		prefix := strings.Repeat(indent, int(depth))
+		if len(lines) > 0 || index > 0 {
+			prefix += causePrefix
+		}
+		for _, line := range strings.Split(cause.Text, "\n") {
+			lines = append(lines, prefix + line)
+		}
+	}
+	if len(lines) == 0 {
+		lines = append(lines, NullText)
+	}
+	return lines
+}
+
+var _ CausalMessage = &ErrorMessage{}
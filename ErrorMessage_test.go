@@ -0,0 +1,132 @@
+package golog
+
+import (
+	"fmt"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type detailedError struct {
+	code int64
+}
+
+func(err *detailedError) Error() string {
+	return "detailed failure"
+}
+
+func(err *detailedError) PutStruct(sink StructSink) {
+	m := sink.Map()
+	m.IntProperty("code", err.code)
+	m.EndMap()
+}
+
+func TestCollectErrorCausesStripsWrappedText(t *testing.T) {
+	root := errors.New("disk full")
+	err := fmt.Errorf("save config: %w", fmt.Errorf("write file: %w", root))
+	causes := CollectErrorCauses(err)
+	var texts []string
+	var depths []uint
+	for _, cause := range causes {
+		texts = append(texts, cause.Text)
+		depths = append(depths, cause.Depth)
+	}
+	if !reflect.DeepEqual(texts, []string { "save config", "write file", "disk full" }) {
+		t.Fatalf("texts %q", texts)
+	}
+	if !reflect.DeepEqual(depths, []uint { 0, 1, 2 }) {
+		t.Fatalf("depths %v", depths)
+	}
+	if CollectErrorCauses(nil) != nil {
+		t.Fatal("nil error produced causes")
+	}
+}
+
+func TestCollectErrorCausesJoined(t *testing.T) {
+	err := errors.Join(errors.New("first"), errors.New("second"))
+	causes := CollectErrorCauses(err)
+	if len(causes) != 2 || causes[0].Text != "first" || causes[1].Text != "second" {
+		t.Fatalf("causes %+v", causes)
+	}
+	if causes[0].Depth != 0 || causes[1].Depth != 0 {
+		t.Fatalf("joined errors should be siblings: %+v", causes)
+	}
+}
+
+func TestErrorMessageLines(t *testing.T) {
+	msg := &ErrorMessage {
+		Err: fmt.Errorf("outer: %w", errors.New("inner")),
+		Text: "request failed",
+	}
+	want := []string { "request failed", "  outer", "    inner" }
+	if got := msg.Lines(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q", got)
+	}
+	if got := (&ErrorMessage{}).Lines(); !reflect.DeepEqual(got, []string { NullText }) {
+		t.Fatalf("empty message rendered %q", got)
+	}
+}
+
+func TestErrorMessageStructure(t *testing.T) {
+	details := MapValue()
+	details.Set(ErrorTypeStructKey, StringValue("custom"))
+	details.Set("user", StringValue("bob"))
+	msg := NewErrorMessage(fmt.Errorf("wrapped: %w", &detailedError {
+		code: 17,
+	}), details)
+	value := CaptureValue(msg)
+	if got := value.Field(ErrorStructKey).String; got != "wrapped: detailed failure" {
+		t.Fatalf("error text %q", got)
+	}
+	if got := value.Field(ErrorTypeStructKey).String; got != "custom" {
+		t.Fatalf("details should override the error type, got %q", got)
+	}
+	if got := value.Field("user").String; got != "bob" {
+		t.Fatalf("details not inlined: %v", value.ToAny())
+	}
+	chain := value.Field(ErrorChainStructKey)
+	if chain == nil || len(chain.Items) != 2 {
+		t.Fatalf("chain %v", value.ToAny())
+	}
+	if got := chain.Items[1].LookupPath([]string { CauseDetailsStructKey, "code" }); got == nil || got.Int != 17 {
+		t.Fatalf("structured cause details missing: %v", chain.ToAny())
+	}
+	count := 0
+	for _, field := range value.Fields {
+		if field.Name == ErrorTypeStructKey {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Fatalf("%s emitted %d times", ErrorTypeStructKey, count)
+	}
+}
+
+type countingStructure struct {
+	Structure
+	emits int
+}
+
+func(structure *countingStructure) PutStruct(sink StructSink) {
+	structure.emits++
+	structure.Structure.PutStruct(sink)
+}
+
+func TestErrorMessageStreamsDetailsOnce(t *testing.T) {
+	details := MapValue()
+	details.Set(ErrorStructKey, StringValue("redacted"))
+	counted := &countingStructure {
+		Structure: details,
+	}
+	value := CaptureValue(NewErrorMessage(errors.New("secret"), counted))
+	if counted.emits != 1 {
+		t.Fatalf("details emitted %d times", counted.emits)
+	}
+	if got := value.Field(ErrorStructKey).String; got != "redacted" || len(value.Fields) != 3 {
+		t.Fatalf("details did not shadow the error: %v", value.ToAny())
+	}
+	list := CaptureValue(NewErrorMessage(errors.New("boom"), ListValue(IntValue(1))))
+	if list.Field(BoundListKey) == nil || list.Field(ErrorStructKey).String != "boom" {
+		t.Fatalf("list details: %v", list.ToAny())
+	}
+}
@@ -0,0 +1,59 @@
+package golog
+
+import (
+	"sync"
+)
+
+type LazyMessage struct {
+	Render func() Message
+	once sync.Once
+	message Message
+}
+
+func NewLazyMessage(render func() Message) *LazyMessage {
+	return &LazyMessage {
+		Render: render,
+	}
+}
+
+func LazyText(details Structure, render func() string) *LazyMessage {
+	return NewLazyMessage(func() Message {
+		var text string
+		if render != nil {
+			text = render()
+		}
+		return &StringMessage {
+			Text: []string { text },
+			Details: details,
+		}
+	})
+}
+
+func(msg *LazyMessage) force() Message {
+	msg.once.Do(func() {
+		if msg.Render != nil {
+			msg.message = msg.Render()
+		}
+	})
+	return msg.message
+}
+
+func(msg *LazyMessage) Lines() []string {
+	if forced := msg.force(); forced != nil {
+		return forced.Lines()
+	}
+	return nil
+}
+
+func(msg *LazyMessage) PutStruct(sink StructSink) {
+	if forced := msg.force(); forced != nil {
+		forced.PutStruct(sink)
+	}
+}
+
+func(msg *LazyMessage) UnwrapMessage() Message {
+	return msg.force()
+}
+
+var _ Message = &LazyMessage{}
+var _ MessageWrapper = &LazyMessage{}
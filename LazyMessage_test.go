@@ -0,0 +1,95 @@
+package golog
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLazyMessageRendersOnce(t *testing.T) {
+	calls := 0
+	details := MapValue()
+	details.Set("k", IntValue(1))
+	msg := LazyText(details, func() string {
+		calls++
+		return "expensive"
+	})
+	if calls != 0 {
+		t.Fatal("rendered on construction")
+	}
+	if lines := msg.Lines(); len(lines) != 1 || lines[0] != "expensive" {
+		t.Fatalf("lines: %q", lines)
+	}
+	msg.Lines()
+	if captured := CaptureValue(msg); captured.Field("k").Int != 1 {
+		t.Fatalf("details: %v", captured.ToAny())
+	}
+	if inner, ok := FindMessage[*StringMessage](msg); !ok || inner.Text[0] != "expensive" {
+		t.Fatal("wrapped message not reachable")
+	}
+	if calls != 1 {
+		t.Fatalf("rendered %d times", calls)
+	}
+}
+
+func TestLazyMessageConcurrentForce(t *testing.T) {
+	var mutex sync.Mutex
+	calls := 0
+	msg := NewLazyMessage(func() Message {
+		mutex.Lock()
+		calls++
+		mutex.Unlock()
+		return &StringMessage {
+			Text: []string { "x" },
+		}
+	})
+	var group sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			msg.Lines()
+		}()
+	}
+	group.Wait()
+	if calls != 1 {
+		t.Fatalf("rendered %d times", calls)
+	}
+}
+
+func TestLazyMessageNilRender(t *testing.T) {
+	empty := NewLazyMessage(nil)
+	if empty.Lines() != nil || empty.UnwrapMessage() != nil {
+		t.Fatal("nil renderer produced a message")
+	}
+	empty.PutStruct(&CaptureSink{})
+	blank := LazyText(nil, nil)
+	if lines := blank.Lines(); len(lines) != 1 || lines[0] != "" {
+		t.Fatalf("nil text renderer: %q", lines)
+	}
+}
+
+func TestLoglSkipsRenderingWhenDisabled(t *testing.T) {
+	target := newCaptureLogger()
+	dispatcher, _ := thresholdDispatcher(WARNING, target)
+	rendered := 0
+	render := func() string {
+		rendered++
+		return "rendered"
+	}
+	log := &Log {
+		Logger: dispatcher,
+	}
+	log.Debugl(nil, nil, render)
+	bound := &BoundLog {
+		Logger: dispatcher,
+	}
+	bound.Infol(nil, render)
+	if rendered != 0 || len(target.Packets()) != 0 {
+		t.Fatalf("disabled levels rendered %d times", rendered)
+	}
+	log.Errorl(nil, nil, render)
+	bound.Warnl(nil, render)
+	if rendered != 2 || len(target.Packets()) != 2 || target.Last().Lines[0] != "rendered" {
+		t.Fatalf("enabled levels: rendered %d, packets %d", rendered, len(target.Packets()))
+	}
+}
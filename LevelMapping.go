@@ -0,0 +1,206 @@
+package golog
+
+import (
+	"strconv"
+)
+
+type LevelMappingEntry struct {
+	Level int
+	Foreign int
+	ForwardOnly bool
+}
+
+type LevelMapping struct {
+	Name string
+	Descending bool
+	Entries []LevelMappingEntry
+}
+
+type LevelMappingError struct {
+	Mapping string
+	Index int
+	Reason string
+}
+
+func(err *LevelMappingError) Error() string {
+	return "invalid level mapping " + strconv.Quote(err.Mapping) + " at entry " + strconv.Itoa(err.Index) +
+			": " + err.Reason
+}
+
+func(mapping *LevelMapping) Validate() error {
+	if err := mapping.emptyError(); err != nil {
+		return err
+	}
+	reversible := false
+	for _, entry := range mapping.Entries {
+		reversible = reversible || !entry.ForwardOnly
+	}
+	if !reversible {
+		return &LevelMappingError {
+			Mapping: mapping.Name,
+			Reason: "mapping has no reversible entries",
+		}
+	}
+	for index := 1; index < len(mapping.Entries); index++ {
+		previous, current := mapping.Entries[index - 1], mapping.Entries[index]
+		if current.Level <= previous.Level {
+			return &LevelMappingError {
+				Mapping: mapping.Name,
+				Index: index,
+				Reason: "levels must be strictly increasing",
+			}
+		}
+		if mapping.Descending && current.Foreign > previous.Foreign {
+			return &LevelMappingError {
+				Mapping: mapping.Name,
+				Index: index,
+				Reason: "foreign severities must not increase",
+			}
+		}
+		if !mapping.Descending && current.Foreign < previous.Foreign {
+			return &LevelMappingError {
+				Mapping: mapping.Name,
+				Index: index,
+				Reason: "foreign severities must not decrease",
+			}
+		}
+	}
+	return nil
+}
+
+func(mapping *LevelMapping) emptyError() error {
+	if len(mapping.Entries) > 0 {
+		return nil
+	}
+	return &LevelMappingError {
+		Mapping: mapping.Name,
+		Reason: "mapping has no entries",
+	}
+}
+
+func(mapping *LevelMapping) ToForeignNumber(numerical int) (int, error) {
+	if err := mapping.emptyError(); err != nil {
+		return 0, err
+	}
+	foreign := mapping.Entries[0].Foreign
+	for _, entry := range mapping.Entries {
+		if numerical < entry.Level {
+			break
+		}
+		foreign = entry.Foreign
+	}
+	return foreign, nil
+}
+
+func(mapping *LevelMapping) ToForeign(level Level) (int, error) {
+	if level == nil {
+		if err := mapping.emptyError(); err != nil {
+			return 0, err
+		}
+		return mapping.Entries[0].Foreign, nil
+	}
+	return mapping.ToForeignNumber(level.Numerical())
+}
+
+func(mapping *LevelMapping) lessSevere(a, b int) bool {
+	if mapping.Descending {
+		return a > b
+	}
+	return a < b
+}
+
+func(mapping *LevelMapping) FromForeignNumber(foreign int) (int, error) {
+	if err := mapping.emptyError(); err != nil {
+		return 0, err
+	}
+	numerical, found := 0, false
+	for _, entry := range mapping.Entries {
+		if entry.ForwardOnly {
+			continue
+		}
+		if found && mapping.lessSevere(foreign, entry.Foreign) {
+			break
+		}
+		numerical, found = entry.Level, true
+	}
+	if !found {
+		return 0, &LevelMappingError {
+			Mapping: mapping.Name,
+			Reason: "mapping has no reversible entries",
+		}
+	}
+	return numerical, nil
+}
+
+func(mapping *LevelMapping) FromForeign(foreign int) (Level, error) {
+	numerical, err := mapping.FromForeignNumber(foreign)
+	if err != nil {
+		return nil, err
+	}
+	if level, ok := Levels.ByNumber(numerical); ok {
+		return level, nil
+	}
+	return &GenericLevel {
+		Number: numerical,
+		Name: mapping.Name + ":" + strconv.Itoa(foreign),
+		Nominal: numerical < int(WARNING),
+	}, nil
+}
+
+var SyslogLevels = &LevelMapping {
+	Name: "syslog",
+	Descending: true,
+	Entries: []LevelMappingEntry {
+		{ Level: int(DEBUG), Foreign: 7 },
+		{ Level: int(CONFIG), Foreign: 6, ForwardOnly: true },
+		{ Level: int(INFO), Foreign: 6 },
+		{ Level: int(WARNING), Foreign: 4 },
+		{ Level: int(ERROR), Foreign: 3 },
+		{ Level: int(MISUSE), Foreign: 3, ForwardOnly: true },
+		{ Level: int(FATAL), Foreign: 2 },
+	},
+}
+
+var JournaldLevels = SyslogLevels
+
+var SlogLevels = &LevelMapping {
+	Name: "slog",
+	Entries: []LevelMappingEntry {
+		{ Level: int(DEBUG), Foreign: -4 },
+		{ Level: int(CONFIG), Foreign: -2 },
+		{ Level: int(INFO), Foreign: 0 },
+		{ Level: int(WARNING), Foreign: 4 },
+		{ Level: int(ERROR), Foreign: 8 },
+		{ Level: int(MISUSE), Foreign: 10 },
+		{ Level: int(FATAL), Foreign: 12 },
+	},
+}
+
+var OTelLevels = &LevelMapping {
+	Name: "otel",
+	Entries: []LevelMappingEntry {
+		{ Level: int(DEBUG), Foreign: 5 },
+		{ Level: int(CONFIG), Foreign: 8 },
+		{ Level: int(INFO), Foreign: 9 },
+		{ Level: int(WARNING), Foreign: 13 },
+		{ Level: int(ERROR), Foreign: 17 },
+		{ Level: int(MISUSE), Foreign: 18 },
+		{ Level: int(FATAL), Foreign: 21 },
+	},
+}
+
+var WindowsEventLevels = &LevelMapping {
+	Name: "windows",
+	Descending: true,
+	Entries: []LevelMappingEntry {
+		{ Level: int(DEBUG), Foreign: 5 },
+		{ Level: int(CONFIG), Foreign: 4, ForwardOnly: true },
+		{ Level: int(INFO), Foreign: 4 },
+		{ Level: int(WARNING), Foreign: 3 },
+		{ Level: int(ERROR), Foreign: 2 },
+		{ Level: int(MISUSE), Foreign: 2, ForwardOnly: true },
+		{ Level: int(FATAL), Foreign: 1 },
+	},
+}
+
+var _ error = &LevelMappingError{}
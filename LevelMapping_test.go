@@ -0,0 +1,140 @@
+package golog
+
+import (
+	"errors"
+	"testing"
+)
+
+var builtinLevelMappings = []*LevelMapping {
+	SyslogLevels,
+	JournaldLevels,
+	SlogLevels,
+	OTelLevels,
+	WindowsEventLevels,
+}
+
+func TestBuiltinLevelMappingsAreValid(t *testing.T) {
+	for _, mapping := range builtinLevelMappings {
+		if err := mapping.Validate(); err != nil {
+			t.Errorf("%s: %v", mapping.Name, err)
+		}
+	}
+	if JournaldLevels != SyslogLevels {
+		t.Error("journald mapping diverged from syslog")
+	}
+}
+
+func TestBuiltinLevelMappingsRoundTrip(t *testing.T) {
+	for _, mapping := range builtinLevelMappings {
+		for _, entry := range mapping.Entries {
+			foreign, err := mapping.ToForeignNumber(entry.Level)
+			if err != nil || foreign != entry.Foreign {
+				t.Errorf("%s: level %d maps to %d, %v", mapping.Name, entry.Level, foreign, err)
+			}
+			back, err := mapping.FromForeign(foreign)
+			if err != nil {
+				t.Errorf("%s: %v", mapping.Name, err)
+				continue
+			}
+			if !entry.ForwardOnly && back.Numerical() != entry.Level {
+				t.Errorf("%s: foreign %d maps back to %d, want %d", mapping.Name, foreign, back.Numerical(), entry.Level)
+			}
+		}
+	}
+}
+
+func TestLevelMappingConversions(t *testing.T) {
+	cases := []struct {
+		mapping *LevelMapping
+		foreign int
+		want Level
+	} {
+		{ SyslogLevels, 0, FATAL },
+		{ SyslogLevels, 5, INFO },
+		{ SyslogLevels, 4, WARNING },
+		{ SyslogLevels, 6, INFO },
+		{ SyslogLevels, 9, DEBUG },
+		{ SlogLevels, -8, DEBUG },
+		{ SlogLevels, 2, INFO },
+		{ SlogLevels, 100, FATAL },
+		{ OTelLevels, 1, DEBUG },
+		{ OTelLevels, 14, WARNING },
+		{ WindowsEventLevels, 0, FATAL },
+	}
+	for _, c := range cases {
+		if got, err := c.mapping.FromForeign(c.foreign); err != nil || got != c.want {
+			t.Errorf("%s %d: got %v, %v, want %v", c.mapping.Name, c.foreign, got, err, c.want)
+		}
+	}
+	if got, _ := SyslogLevels.ToForeign(MISUSE); got != 3 {
+		t.Errorf("MISUSE to syslog: %d", got)
+	}
+	if got, _ := SyslogLevels.ToForeign(nil); got != 7 {
+		t.Errorf("nil level to syslog: %d", got)
+	}
+	if got, _ := OTelLevels.ToForeignNumber(-5); got != 5 {
+		t.Errorf("below DEBUG: %d", got)
+	}
+	generic, err := (&LevelMapping {
+		Name: "sparse",
+		Entries: []LevelMappingEntry {
+			{ Level: 100, Foreign: 1 },
+		},
+	}).FromForeign(1)
+	if err != nil || generic.Numerical() != 100 || generic.HumanReadable(ADJ_NONE) != "sparse:1" {
+		t.Errorf("unregistered level: %v, %v", generic, err)
+	}
+}
+
+func TestLevelMappingErrors(t *testing.T) {
+	empty := &LevelMapping {
+		Name: "empty",
+	}
+	var mappingErr *LevelMappingError
+	if _, err := empty.ToForeignNumber(0); !errors.As(err, &mappingErr) {
+		t.Errorf("ToForeignNumber: %v", err)
+	}
+	if _, err := empty.ToForeign(nil); !errors.As(err, &mappingErr) {
+		t.Errorf("ToForeign: %v", err)
+	}
+	if _, err := empty.FromForeign(0); !errors.As(err, &mappingErr) {
+		t.Errorf("FromForeign: %v", err)
+	}
+	forwardOnly := &LevelMapping {
+		Name: "forward",
+		Entries: []LevelMappingEntry {
+			{ Level: 0, Foreign: 1, ForwardOnly: true },
+		},
+	}
+	if _, err := forwardOnly.FromForeignNumber(1); !errors.As(err, &mappingErr) {
+		t.Errorf("forward-only FromForeignNumber: %v", err)
+	}
+	invalid := map[string]*LevelMapping {
+		"empty": empty,
+		"forward-only": forwardOnly,
+		"unordered": {
+			Entries: []LevelMappingEntry {
+				{ Level: 2, Foreign: 1 },
+				{ Level: 1, Foreign: 2 },
+			},
+		},
+		"descending increases": {
+			Descending: true,
+			Entries: []LevelMappingEntry {
+				{ Level: 1, Foreign: 1 },
+				{ Level: 2, Foreign: 2 },
+			},
+		},
+		"ascending decreases": {
+			Entries: []LevelMappingEntry {
+				{ Level: 1, Foreign: 2 },
+				{ Level: 2, Foreign: 1 },
+			},
+		},
+	}
+	for name, mapping := range invalid {
+		if mapping.Validate() == nil {
+			t.Errorf("%s accepted", name)
+		}
+	}
+}
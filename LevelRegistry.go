@@ -0,0 +1,343 @@
+package golog
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+type RegisteredLevel struct {
+	Number int
+	Name string
+	Aliases []string
+	Left string
+	Right string
+	Nominal bool
+}
+
+func(level *RegisteredLevel) Numerical() int {
+	return level.Number
+}
+
+func(level *RegisteredLevel) HumanReadable(adjust Adjustment) string {
+	switch adjust {
+		case ADJ_LEFT:
+			if len(level.Left) > 0 {
+				return level.Left
+			}
+		case ADJ_RIGHT:
+			if len(level.Right) > 0 {
+				return level.Right
+			}
+	}
+	return AdjustLevelName(level.Name, adjust)
+}
+
+func(level *RegisteredLevel) IsNominal() bool {
+	return level.Nominal
+}
+
+type LevelRegistryError struct {
+	Name string
+	Reason string
+}
+
+func(err *LevelRegistryError) Error() string {
+	return "invalid level \"" + err.Name + "\": " + err.Reason
+}
+
+type LevelRegistry struct {
+	mutex sync.RWMutex
+	byName map[string]Level
+	levels []Level
+	width atomic.Int32
+}
+
+func NewLevelRegistry() *LevelRegistry {
+	registry := &LevelRegistry {
+		byName: make(map[string]Level),
+	}
+	for level := DEBUG; level <= FATAL; level++ {
+		registry.add(level, level.name())
+	}
+	registry.byName["WARN"] = WARNING
+	registry.width.Store(DefaultLevelWidth)
+	return registry
+}
+
+func(registry *LevelRegistry) add(level Level, names ...string) {
+	for _, name := range names {
+		registry.byName[strings.ToUpper(name)] = level
+	}
+	index := sort.Search(len(registry.levels), func(i int) bool {
+		return registry.levels[i].Numerical() > level.Numerical()
+	})
+	registry.levels = append(registry.levels, nil)
+	copy(registry.levels[index + 1:], registry.levels[index:])
+	registry.levels[index] = level
+}
+
+func(registry *LevelRegistry) Register(level *RegisteredLevel) error {
+	name := strings.TrimSpace(level.Name)
+	if len(name) == 0 {
+		return &LevelRegistryError {
+			Name: level.Name,
+			Reason: "level name must not be empty",
+		}
+	}
+	names := append([]string { name }, level.Aliases...)
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	if existing, taken := registry.byNumber(level.Number); taken {
+		return &LevelRegistryError {
+			Name: name,
+			Reason: "number " + strconv.Itoa(level.Number) + " is already registered as \"" +
+					existing.HumanReadable(ADJ_NONE) + "\"",
+		}
+	}
+	for _, candidate := range names {
+		if strings.ContainsAny(candidate, " \t\r\n") {
+			return &LevelRegistryError {
+				Name: candidate,
+				Reason: "level names must not contain whitespace",
+			}
+		}
+		if _, taken := registry.byName[strings.ToUpper(candidate)]; taken {
+			return &LevelRegistryError {
+				Name: candidate,
+				Reason: "name is already registered",
+			}
+		}
+	}
+	registry.add(level, names...)
+	width := TextWidth(name)
+	if len(level.Left) > 0 && TextWidth(level.Left) > width {
+		width = TextWidth(level.Left)
+	}
+	if len(level.Right) > 0 && TextWidth(level.Right) > width {
+		width = TextWidth(level.Right)
+	}
+	if int32(width) > registry.width.Load() {
+		registry.width.Store(int32(width))
+	}
+	return nil
+}
+
+func(registry *LevelRegistry) MustRegister(level *RegisteredLevel) *RegisteredLevel {
+	if err := registry.Register(level); err != nil {
+		panic(err)
+	}
+	return level
+}
+
+func(registry *LevelRegistry) Lookup(name string) (Level, bool) {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+	level, ok := registry.byName[strings.ToUpper(strings.TrimSpace(name))]
+	return level, ok
+}
+
+func(registry *LevelRegistry) MustLookup(name string) Level {
+	level, ok := registry.Lookup(name)
+	if !ok {
+		panic(&LevelRegistryError {
+			Name: name,
+			Reason: "unknown level",
+		})
+	}
+	return level
+}
+
+func(registry *LevelRegistry) ByNumber(number int) (Level, bool) {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+	return registry.byNumber(number)
+}
+
+func(registry *LevelRegistry) byNumber(number int) (Level, bool) {
+	index := sort.Search(len(registry.levels), func(i int) bool {
+		return registry.levels[i].Numerical() >= number
+	})
+	if index < len(registry.levels) && registry.levels[index].Numerical() == number {
+		return registry.levels[index], true
+	}
+	return nil, false
+}
+
+func(registry *LevelRegistry) Levels() []Level {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+	return append([]Level(nil), registry.levels...)
+}
+
+func(registry *LevelRegistry) Width() int {
+	return int(registry.width.Load())
+}
+
+func(registry *LevelRegistry) Parse(spec string) (Level, error) {
+	if level, ok := registry.Lookup(spec); ok {
+		return level, nil
+	}
+	trimmed := strings.TrimSpace(spec)
+	number, err := strconv.Atoi(trimmed)
+	if err != nil {
+		return nil, &LevelRegistryError {
+			Name: spec,
+			Reason: "unknown level",
+		}
+	}
+	if level, ok := registry.ByNumber(number); ok {
+		return level, nil
+	}
+	return &GenericLevel {
+		Number: number,
+		Name: trimmed,
+		Nominal: number < int(WARNING),
+	}, nil
+}
+
+func(registry *LevelRegistry) FromName(name string) (Level, error) {
+	if level, ok := registry.Lookup(name); ok {
+		return level, nil
+	}
+	return nil, &LevelRegistryError {
+		Name: name,
+		Reason: "unknown level",
+	}
+}
+
+var Levels = NewLevelRegistry()
+
+func RegisterLevel(level *RegisteredLevel) error {
+	return Levels.Register(level)
+}
+
+func ParseLevel(spec string) (Level, error) {
+	return Levels.Parse(spec)
+}
+
+func LevelFromName(name string) (Level, error) {
+	return Levels.FromName(name)
+}
+
+func LevelWidth() int {
+	return Levels.Width()
+}
+
+type adjustedNameKey struct {
+	name string
+	adjust Adjustment
+	width int
+}
+
+const maxAdjustedNames = 256
+
+var adjustedNamesMutex sync.RWMutex
+var adjustedNames = make(map[adjustedNameKey]string)
+
+func AdjustLevelName(name string, adjust Adjustment) string {
+	if adjust != ADJ_LEFT && adjust != ADJ_RIGHT {
+		return name
+	}
+	key := adjustedNameKey {
+		name: name,
+		adjust: adjust,
+		width: LevelWidth(),
+	}
+	adjustedNamesMutex.RLock()
+	adjusted, found := adjustedNames[key]
+	adjustedNamesMutex.RUnlock()
+	if found {
+		return adjusted
+	}
+	if adjust == ADJ_LEFT {
+		adjusted = fmt.Sprintf("%-*s", key.width, name)
+	} else {
+		adjusted = fmt.Sprintf("%*s", key.width, name)
+	}
+	adjustedNamesMutex.Lock()
+	if len(adjustedNames) >= maxAdjustedNames {
+		adjustedNames = make(map[adjustedNameKey]string)
+	}
+	adjustedNames[key] = adjusted
+	adjustedNamesMutex.Unlock()
+	return adjusted
+}
+
+type LevelLog struct {
+	Target *Log
+	Level Level
+}
+
+func(log *Log) At(level Level) LevelLog {
+	return LevelLog {
+		Target: log,
+		Level: level,
+	}
+}
+
+func(log *Log) Named(name string) LevelLog {
+	return log.At(Levels.MustLookup(name))
+}
+
+func(ll LevelLog) Log(src Source, msg Message) {
+	ll.Target.Log(ll.Level, src, msg)
+}
+
+func(ll LevelLog) Logv(src Source, details Structure, args ...any) {
+	ll.Target.Logv(ll.Level, src, details, args...)
+}
+
+func(ll LevelLog) Logf(src Source, details Structure, format string, args ...any) {
+	ll.Target.Logf(ll.Level, src, details, format, args...)
+}
+
+func(ll LevelLog) LogErr(src Source, err error, details Structure) {
+	ll.Target.LogErr(ll.Level, src, err, details)
+}
+
+func(ll LevelLog) Logl(src Source, details Structure, render func() string) {
+	ll.Target.Logl(ll.Level, src, details, render)
+}
+
+type BoundLevelLog struct {
+	Target *BoundLog
+	Level Level
+}
+
+func(log *BoundLog) At(level Level) BoundLevelLog {
+	return BoundLevelLog {
+		Target: log,
+		Level: level,
+	}
+}
+
+func(log *BoundLog) Named(name string) BoundLevelLog {
+	return log.At(Levels.MustLookup(name))
+}
+
+func(ll BoundLevelLog) Log(msg Message) {
+	ll.Target.Log(ll.Level, msg)
+}
+
+func(ll BoundLevelLog) Logv(details Structure, args ...any) {
+	ll.Target.Logv(ll.Level, details, args...)
+}
+
+func(ll BoundLevelLog) Logf(details Structure, format string, args ...any) {
+	ll.Target.Logf(ll.Level, details, format, args...)
+}
+
+func(ll BoundLevelLog) LogErr(err error, details Structure) {
+	ll.Target.LogErr(ll.Level, err, details)
+}
+
+func(ll BoundLevelLog) Logl(details Structure, render func() string) {
+	ll.Target.Logl(ll.Level, details, render)
+}
+
+var _ Level = &RegisteredLevel{}
+var _ error = &LevelRegistryError{}
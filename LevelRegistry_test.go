@@ -0,0 +1,143 @@
+package golog
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLevelRegistryRegister(t *testing.T) {
+	registry := NewLevelRegistry()
+	notice := &RegisteredLevel {
+		Number: 15,
+		Name: "notice",
+		Aliases: []string { "note" },
+		Nominal: true,
+	}
+	if err := registry.Register(notice); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string { "NOTICE", " note ", "Notice" } {
+		if level, ok := registry.Lookup(name); !ok || level != notice {
+			t.Errorf("%q: got %v, %v", name, level, ok)
+		}
+	}
+	if level, ok := registry.ByNumber(15); !ok || level != notice {
+		t.Errorf("ByNumber: got %v, %v", level, ok)
+	}
+	levels := registry.Levels()
+	for index := 1; index < len(levels); index++ {
+		if levels[index - 1].Numerical() >= levels[index].Numerical() {
+			t.Fatalf("levels not ordered: %v", levels)
+		}
+	}
+	if level, err := registry.Parse("15"); err != nil || level != notice {
+		t.Errorf("Parse number: got %v, %v", level, err)
+	}
+}
+
+func TestLevelRegistryRejects(t *testing.T) {
+	registry := NewLevelRegistry()
+	cases := map[string]*RegisteredLevel {
+		"empty name": {
+			Number: 100,
+			Name: " ",
+		},
+		"whitespace": {
+			Number: 101,
+			Name: "two words",
+		},
+		"taken name": {
+			Number: 102,
+			Name: "warn",
+		},
+		"taken alias": {
+			Number: 103,
+			Name: "fresh",
+			Aliases: []string { "ERROR" },
+		},
+		"taken number": {
+			Number: int(WARNING),
+			Name: "caution",
+		},
+	}
+	for name, level := range cases {
+		err := registry.Register(level)
+		var registryErr *LevelRegistryError
+		if !errors.As(err, &registryErr) {
+			t.Errorf("%s: got %v", name, err)
+		}
+		if _, ok := registry.Lookup(level.Name); ok && level.Name != "warn" {
+			t.Errorf("%s: rejected level was registered", name)
+		}
+	}
+	if _, ok := registry.ByNumber(int(WARNING)); !ok {
+		t.Fatal("WARNING lost")
+	}
+	if level, _ := registry.ByNumber(int(WARNING)); level != WARNING {
+		t.Fatalf("WARNING replaced by %v", level)
+	}
+}
+
+func TestLevelRegistryWidth(t *testing.T) {
+	registry := NewLevelRegistry()
+	before := registry.Width()
+	registry.MustRegister(&RegisteredLevel {
+		Number: 200,
+		Name: "EXTRAORDINARY",
+	})
+	if registry.Width() != len("EXTRAORDINARY") || registry.Width() <= before {
+		t.Fatalf("width %d", registry.Width())
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("MustRegister accepted a duplicate")
+		}
+	}()
+	registry.MustRegister(&RegisteredLevel {
+		Number: 201,
+		Name: "extraordinary",
+	})
+}
+
+func TestLevelFromNameRejectsUnknownNames(t *testing.T) {
+	if level, err := LevelFromName(" warn "); err != nil || level != WARNING {
+		t.Fatalf("known name: %v, %v", level, err)
+	}
+	level, err := LevelFromName("wraning")
+	var registryErr *LevelRegistryError
+	if level != nil || !errors.As(err, &registryErr) || registryErr.Name != "wraning" {
+		t.Fatalf("unknown name: %v, %v", level, err)
+	}
+}
+
+func TestNamedRejectsUnknownLevels(t *testing.T) {
+	target := newCaptureLogger()
+	log := &Log {
+		Logger: target,
+	}
+	log.Named("warn").Logv(nil, nil, "known")
+	if last := target.Last(); last.Level != WARNING {
+		t.Fatalf("got %v", last.Level)
+	}
+	expectPanic := func(name string, call func()) {
+		t.Helper()
+		defer func() {
+			err, _ := recover().(*LevelRegistryError)
+			if err == nil || err.Name != "wraning" {
+				t.Errorf("%s: got %v", name, err)
+			}
+		}()
+		call()
+	}
+	expectPanic("Log.Named", func() {
+		log.Named("wraning")
+	})
+	expectPanic("BoundLog.Named", func() {
+		(&BoundLog {
+			Logger: target,
+		}).Named("wraning")
+	})
+	if len(target.Packets()) != 1 {
+		t.Fatal("mistyped level logged")
+	}
+}
@@ -0,0 +1,140 @@
+package golog
+
+import (
+	"io"
+	"log"
+	"sync"
+	"bytes"
+)
+
+const MaxLogWriterLineSize = 64 << 10
+
+type LogWriter struct {
+	Target *Log
+	Level Level
+	Source Source
+	ParseLevels bool
+	mutex sync.Mutex
+	pending []byte
+}
+
+func NewLogWriter(logger Logger, level Level, src Source) *LogWriter {
+	return &LogWriter {
+		Target: &Log {
+			Logger: logger,
+		},
+		Level: level,
+		Source: src,
+	}
+}
+
+func ParseLevelPrefix(line []byte) (Level, []byte, bool) {
+	var name, rest []byte
+	if len(line) > 0 && line[0] == '[' {
+		end := bytes.IndexByte(line, ']')
+		if end < 0 {
+			return nil, line, false
+		}
+		name, rest = line[1:end], line[end + 1:]
+	} else {
+		end := bytes.IndexByte(line, ':')
+		if end < 0 {
+			return nil, line, false
+		}
+		name, rest = line[:end], line[end + 1:]
+	}
+	if len(name) == 0 || len(name) > 32 {
+		return nil, line, false
+	}
+	level, ok := Levels.Lookup(string(name))
+	if !ok {
+		return nil, line, false
+	}
+	return level, bytes.TrimLeft(rest, " \t"), true
+}
+
+func(writer *LogWriter) emit(line []byte) {
+	if len(line) > 0 && line[len(line) - 1] == '\r' {
+		line = line[:len(line) - 1]
+	}
+	if len(line) == 0 || writer.Target == nil {
+		return
+	}
+	level := writer.Level
+	if level == nil {
+		level = INFO
+	}
+	if writer.ParseLevels {
+		if parsed, rest, ok := ParseLevelPrefix(line); ok {
+			level, line = parsed, rest
+		}
+	}
+	if !writer.Target.Enabled(level, writer.Source) {
+		return
+	}
+	writer.Target.logText(level, writer.Source, nil, string(line))
+}
+
+func(writer *LogWriter) Write(data []byte) (int, error) {
+	count := len(data)
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	for len(data) > 0 {
+		newline := bytes.IndexByte(data, '\n')
+		if newline < 0 {
+			writer.pending = append(writer.pending, data...)
+			if len(writer.pending) >= MaxLogWriterLineSize {
+				writer.emit(writer.pending)
+				writer.pending = writer.pending[:0]
+			}
+			break
+		}
+		if len(writer.pending) > 0 {
+			writer.pending = append(writer.pending, data[:newline]...)
+			writer.emit(writer.pending)
+			writer.pending = writer.pending[:0]
+		} else {
+			writer.emit(data[:newline])
+		}
+		data = data[newline + 1:]
+	}
+	return count, nil
+}
+
+func(writer *LogWriter) Flush() {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	if len(writer.pending) > 0 {
+		writer.emit(writer.pending)
+		writer.pending = writer.pending[:0]
+	}
+}
+
+func(writer *LogWriter) Close() error {
+	writer.Flush()
+	return nil
+}
+
+func NewStdLogger(logger Logger, level Level, src Source) *log.Logger {
+	writer := NewLogWriter(logger, level, src)
+	writer.ParseLevels = true
+	return log.New(writer, "", 0)
+}
+
+func RedirectStdLog(logger Logger, level Level, src Source) func() {
+	writer := NewLogWriter(logger, level, src)
+	writer.ParseLevels = true
+	output, flags, prefix := log.Writer(), log.Flags(), log.Prefix()
+	log.SetOutput(writer)
+	log.SetFlags(0)
+	log.SetPrefix("")
+	return func() {
+		log.SetOutput(output)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+		writer.Flush()
+	}
+}
+
+var _ io.WriteCloser = &LogWriter{}
+var _ Flusher = &LogWriter{}
@@ -0,0 +1,141 @@
+package golog
+
+import (
+	"os"
+	"log"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func expectLines(t *testing.T, capture *captureLogger, want ...string) {
+	t.Helper()
+	packets := capture.Packets()
+	if len(packets) != len(want) {
+		t.Fatalf("got %d packets, want %d: %v", len(packets), len(want), packets)
+	}
+	for index, packet := range packets {
+		if len(packet.Lines) != 1 || packet.Lines[0] != want[index] {
+			t.Errorf("packet %d: got %q, want %q", index, packet.Lines, want[index])
+		}
+	}
+}
+
+func TestLogWriterSplitsLines(t *testing.T) {
+	capture := newCaptureLogger()
+	writer := NewLogWriter(capture, nil, TextSource("lib"))
+	for _, chunk := range []string { "first\nsec", "ond\r\n\n", "tail" } {
+		if count, err := writer.Write([]byte(chunk)); count != len(chunk) || err != nil {
+			t.Fatalf("Write(%q) = %d, %v", chunk, count, err)
+		}
+	}
+	expectLines(t, capture, "first", "second")
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expectLines(t, capture, "first", "second", "tail")
+	last := capture.Last()
+	if last.Level != INFO || last.Source.StringSource() != "lib" {
+		t.Errorf("header %v %v", last.Level, last.Source)
+	}
+	writer.Flush()
+	if len(capture.Packets()) != 3 {
+		t.Error("empty flush emitted a packet")
+	}
+}
+
+func TestLogWriterParsesLevelPrefixes(t *testing.T) {
+	capture := newCaptureLogger()
+	writer := NewLogWriter(capture, DEBUG, nil)
+	writer.ParseLevels = true
+	writer.Write([]byte("[warn]  disk low\nERROR: boom\nhttp: bad header\n[WARNING unterminated\n"))
+	packets := capture.Packets()
+	want := []struct {
+		level Level
+		line string
+	} {
+		{ WARNING, "disk low" },
+		{ ERROR, "boom" },
+		{ DEBUG, "http: bad header" },
+		{ DEBUG, "[WARNING unterminated" },
+	}
+	if len(packets) != len(want) {
+		t.Fatalf("got %d packets", len(packets))
+	}
+	for index, expected := range want {
+		if packets[index].Level != expected.level || packets[index].Lines[0] != expected.line {
+			t.Errorf("line %d: got %v %q, want %v %q", index, packets[index].Level, packets[index].Lines[0],
+					expected.level, expected.line)
+		}
+	}
+	writer.ParseLevels = false
+	writer.Write([]byte("ERROR: literal\n"))
+	if last := capture.Last(); last.Level != DEBUG || last.Lines[0] != "ERROR: literal" {
+		t.Errorf("prefix parsed while disabled: %v %q", last.Level, last.Lines)
+	}
+}
+
+func TestLogWriterCapsLineSize(t *testing.T) {
+	capture := newCaptureLogger()
+	writer := NewLogWriter(capture, INFO, nil)
+	writer.Write(bytes.Repeat([]byte("x"), MaxLogWriterLineSize - 1))
+	if len(capture.Packets()) != 0 {
+		t.Fatal("partial line emitted below the cap")
+	}
+	writer.Write([]byte("yz"))
+	packets := capture.Packets()
+	if len(packets) != 1 || len(packets[0].Lines[0]) != MaxLogWriterLineSize + 1 {
+		t.Fatalf("oversized line not emitted at the cap")
+	}
+	writer.Write([]byte("next\n"))
+	expectLines(t, capture, packets[0].Lines[0], "next")
+}
+
+func TestLogWriterSkipsDisabledLevels(t *testing.T) {
+	capture := newCaptureLogger()
+	dispatcher, _ := thresholdDispatcher(WARNING, capture)
+	writer := NewLogWriter(dispatcher, INFO, nil)
+	writer.ParseLevels = true
+	writer.Write([]byte("chatter\n[ERROR] kept\n"))
+	expectLines(t, capture, "kept")
+	(&LogWriter{}).Write([]byte("no target\n"))
+}
+
+func TestNewStdLogger(t *testing.T) {
+	capture := newCaptureLogger()
+	std := NewStdLogger(capture, WARNING, TextSource("std"))
+	std.Printf("[ERROR] failed %d", 3)
+	std.Print("plain")
+	expectLines(t, capture, "failed 3", "plain")
+	packets := capture.Packets()
+	if packets[0].Level != ERROR || packets[1].Level != WARNING {
+		t.Errorf("levels %v, %v", packets[0].Level, packets[1].Level)
+	}
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	var original bytes.Buffer
+	log.SetOutput(&original)
+	log.SetFlags(log.Lshortfile)
+	log.SetPrefix("app: ")
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+		log.SetPrefix("")
+	}()
+	capture := newCaptureLogger()
+	restore := RedirectStdLog(capture, INFO, nil)
+	log.Print("WARNING: redirected")
+	restore()
+	expectLines(t, capture, "redirected")
+	if capture.Last().Level != WARNING {
+		t.Errorf("level %v", capture.Last().Level)
+	}
+	if log.Writer() != &original || log.Flags() != log.Lshortfile || log.Prefix() != "app: " {
+		t.Fatal("standard logger not restored")
+	}
+	log.Print("back")
+	if !strings.HasSuffix(original.String(), "back\n") {
+		t.Errorf("restored output got %q", original.String())
+	}
+}
@@ -12,43 +12,102 @@ type Logger interface {
 	Identity() uintptr
 }
 
+type Flusher interface {
+	Flush()
+}
+
 type Log struct {
 	Logger Logger
+	StackPredicate Predicate[Level]
+	ReusePackets bool
 }
 
 func(log *Log) Logp(packet *Packet) {
 	if log.Logger == nil {
 		return
 	}
-	if packet != nil && packet.Timestamp.IsZero() {
-		packet.Timestamp = time.Now()
+	if packet != nil {
+		if packet.Timestamp.IsZero() {
+			packet.Timestamp = time.Now()
+		}
+		attachStack(packet, log.StackPredicate)
 	}
 	log.Logger.Log(packet)
 }
 
+func(log *Log) Enabled(level Level, src Source) bool {
+	return LoggerEnabled(log.Logger, level, src)
+}
+
 func(log *Log) Log(level Level, src Source, msg Message) {
-	if log.Logger != nil {
-		log.Logger.Log(&Packet {
-			Level: level,
-			Message: msg,
-			Source: src,
-			Timestamp: time.Now(),
+	if !log.Enabled(level, src) {
+		return
+	}
+	log.dispatch(level, src, msg)
+}
+
+func(log *Log) dispatch(level Level, src Source, msg Message) {
+	if log.ReusePackets {
+		packet := AcquirePacket()
+		packet.Level = level
+		packet.Message = msg
+		packet.Source = src
+		packet.Timestamp = time.Now()
+		attachStack(packet, log.StackPredicate)
+		log.Logger.Log(packet)
+		ReleasePacket(packet)
+		return
+	}
+	packet := &Packet {
+		Level: level,
+		Message: msg,
+		Source: src,
+		Timestamp: time.Now(),
+	}
+	attachStack(packet, log.StackPredicate)
+	log.Logger.Log(packet)
+}
+
+func(log *Log) logText(level Level, src Source, details Structure, text string) {
+	if !log.ReusePackets {
+		log.dispatch(level, src, &StringMessage {
+			Text: []string { text },
+			Details: details,
 		})
+		return
 	}
+	entry := acquireTextPacket(level, src, details, text)
+	attachStack(&entry.packet, log.StackPredicate)
+	log.Logger.Log(&entry.packet)
+	entry.release()
 }
 
 func(log *Log) Logv(level Level, src Source, details Structure, args ...any) {
-	log.Log(level, src, &StringMessage {
-		Text: []string { fmt.Sprint(args...) },
-		Details: details,
-	})
+	if !log.Enabled(level, src) {
+		return
+	}
+	log.logText(level, src, details, fmt.Sprint(args...))
 }
 
 func(log *Log) Logf(level Level, src Source, details Structure, format string, args ...any) {
-	log.Log(level, src, &StringMessage {
-		Text: []string { fmt.Sprintf(format, args...) },
-		Details: details,
-	})
+	if !log.Enabled(level, src) {
+		return
+	}
+	log.logText(level, src, details, fmt.Sprintf(format, args...))
+}
+
+func(log *Log) LogErr(level Level, src Source, err error, details Structure) {
+	if !log.Enabled(level, src) {
+		return
+	}
+	log.dispatch(level, src, NewErrorMessage(err, details))
+}
+
+func(log *Log) Logl(level Level, src Source, details Structure, render func() string) {
+	if !log.Enabled(level, src) {
+		return
+	}
+	log.dispatch(level, src, LazyText(details, render))
 }
 
 func(log *Log) Debug(src Source, msg Message) {
@@ -63,6 +122,14 @@ func(log *Log) Debugf(src Source, details Structure, format string, args ...any)
 	log.Logf(DEBUG, src, details, format, args...)
 }
 
+func(log *Log) DebugErr(src Source, err error, details Structure) {
+	log.LogErr(DEBUG, src, err, details)
+}
+
+func(log *Log) Debugl(src Source, details Structure, render func() string) {
+	log.Logl(DEBUG, src, details, render)
+}
+
 func(log *Log) Config(src Source, msg Message) {
 	log.Log(CONFIG, src, msg)
 }
@@ -75,6 +142,14 @@ func(log *Log) Configf(src Source, details Structure, format string, args ...any
 	log.Logf(CONFIG, src, details, format, args...)
 }
 
+func(log *Log) ConfigErr(src Source, err error, details Structure) {
+	log.LogErr(CONFIG, src, err, details)
+}
+
+func(log *Log) Configl(src Source, details Structure, render func() string) {
+	log.Logl(CONFIG, src, details, render)
+}
+
 func(log *Log) Info(src Source, msg Message) {
 	log.Log(INFO, src, msg)
 }
@@ -87,6 +162,14 @@ func(log *Log) Infof(src Source, details Structure, format string, args ...any)
 	log.Logf(INFO, src, details, format, args...)
 }
 
+func(log *Log) InfoErr(src Source, err error, details Structure) {
+	log.LogErr(INFO, src, err, details)
+}
+
+func(log *Log) Infol(src Source, details Structure, render func() string) {
+	log.Logl(INFO, src, details, render)
+}
+
 func(log *Log) Warn(src Source, msg Message) {
 	log.Log(WARNING, src, msg)
 }
@@ -99,6 +182,14 @@ func(log *Log) Warnf(src Source, details Structure, format string, args ...any)
 	log.Logf(WARNING, src, details, format, args...)
 }
 
+func(log *Log) WarnErr(src Source, err error, details Structure) {
+	log.LogErr(WARNING, src, err, details)
+}
+
+func(log *Log) Warnl(src Source, details Structure, render func() string) {
+	log.Logl(WARNING, src, details, render)
+}
+
 func(log *Log) Error(src Source, msg Message) {
 	log.Log(ERROR, src, msg)
 }
@@ -111,6 +202,14 @@ func(log *Log) Errorf(src Source, details Structure, format string, args ...any)
 	log.Logf(ERROR, src, details, format, args...)
 }
 
+func(log *Log) ErrorErr(src Source, err error, details Structure) {
+	log.LogErr(ERROR, src, err, details)
+}
+
+func(log *Log) Errorl(src Source, details Structure, render func() string) {
+	log.Logl(ERROR, src, details, render)
+}
+
 func(log *Log) Misuse(src Source, msg Message) {
 	log.Log(MISUSE, src, msg)
 }
@@ -123,6 +222,14 @@ func(log *Log) Misusef(src Source, details Structure, format string, args ...any
 	log.Logf(MISUSE, src, details, format, args...)
 }
 
+func(log *Log) MisuseErr(src Source, err error, details Structure) {
+	log.LogErr(MISUSE, src, err, details)
+}
+
+func(log *Log) Misusel(src Source, details Structure, render func() string) {
+	log.Logl(MISUSE, src, details, render)
+}
+
 func(log *Log) Fatal(src Source, msg Message) {
 	log.Log(FATAL, src, msg)
 }
@@ -135,9 +242,45 @@ func(log *Log) Fatalf(src Source, details Structure, format string, args ...any)
 	log.Logf(FATAL, src, details, format, args...)
 }
 
+func(log *Log) FatalErr(src Source, err error, details Structure) {
+	log.LogErr(FATAL, src, err, details)
+}
+
+func(log *Log) Fatall(src Source, details Structure, render func() string) {
+	log.Logl(FATAL, src, details, render)
+}
+
 type BoundLog struct {
 	Logger Logger
 	Source Source
+	StackPredicate Predicate[Level]
+	ReusePackets bool
+	fields []boundField
+}
+
+func(log *BoundLog) With(pairs ...any) *BoundLog {
+	return &BoundLog {
+		Logger: log.Logger,
+		Source: log.Source,
+		StackPredicate: log.StackPredicate,
+		ReusePackets: log.ReusePackets,
+		fields: deriveFields(log.fields, pairsToFields(pairs)...),
+	}
+}
+
+func(log *BoundLog) WithStructure(structure Structure) *BoundLog {
+	if structure == nil {
+		return log
+	}
+	return &BoundLog {
+		Logger: log.Logger,
+		Source: log.Source,
+		StackPredicate: log.StackPredicate,
+		ReusePackets: log.ReusePackets,
+		fields: deriveFields(log.fields, boundField {
+			structure: structure,
+		}),
+	}
 }
 
 func(log *BoundLog) Logp(packet *Packet) {
@@ -151,33 +294,86 @@ func(log *BoundLog) Logp(packet *Packet) {
 		if packet.Timestamp.IsZero() {
 			packet.Timestamp = time.Now()
 		}
+		packet.Message = bindMessage(packet.Message, log.fields)
+		attachStack(packet, log.StackPredicate)
 	}
 	log.Logger.Log(packet)
 }
 
+func(log *BoundLog) Enabled(level Level) bool {
+	return LoggerEnabled(log.Logger, level, log.Source)
+}
+
 func(log *BoundLog) Log(level Level, msg Message) {
-	if log.Logger != nil {
-		log.Logger.Log(&Packet {
-			Level: level,
-			Message: msg,
-			Source: log.Source,
-			Timestamp: time.Now(),
+	if !log.Enabled(level) {
+		return
+	}
+	log.dispatch(level, msg)
+}
+
+func(log *BoundLog) dispatch(level Level, msg Message) {
+	if log.ReusePackets {
+		packet := AcquirePacket()
+		packet.Level = level
+		packet.Message = bindMessage(msg, log.fields)
+		packet.Source = log.Source
+		packet.Timestamp = time.Now()
+		attachStack(packet, log.StackPredicate)
+		log.Logger.Log(packet)
+		ReleasePacket(packet)
+		return
+	}
+	packet := &Packet {
+		Level: level,
+		Message: bindMessage(msg, log.fields),
+		Source: log.Source,
+		Timestamp: time.Now(),
+	}
+	attachStack(packet, log.StackPredicate)
+	log.Logger.Log(packet)
+}
+
+func(log *BoundLog) logText(level Level, details Structure, text string) {
+	if !log.ReusePackets {
+		log.dispatch(level, &StringMessage {
+			Text: []string { text },
+			Details: details,
 		})
+		return
 	}
+	entry := acquireTextPacket(level, log.Source, details, text)
+	entry.packet.Message = bindMessage(entry.packet.Message, log.fields)
+	attachStack(&entry.packet, log.StackPredicate)
+	log.Logger.Log(&entry.packet)
+	entry.release()
 }
 
 func(log *BoundLog) Logv(level Level, details Structure, args ...any) {
-	log.Log(level, &StringMessage {
-		Text: []string { fmt.Sprint(args...) },
-		Details: details,
-	})
+	if !log.Enabled(level) {
+		return
+	}
+	log.logText(level, details, fmt.Sprint(args...))
 }
 
 func(log *BoundLog) Logf(level Level, details Structure, format string, args ...any) {
-	log.Log(level, &StringMessage {
-		Text: []string { fmt.Sprintf(format, args...) },
-		Details: details,
-	})
+	if !log.Enabled(level) {
+		return
+	}
+	log.logText(level, details, fmt.Sprintf(format, args...))
+}
+
+func(log *BoundLog) LogErr(level Level, err error, details Structure) {
+	if !log.Enabled(level) {
+		return
+	}
+	log.dispatch(level, NewErrorMessage(err, details))
+}
+
+func(log *BoundLog) Logl(level Level, details Structure, render func() string) {
+	if !log.Enabled(level) {
+		return
+	}
+	log.dispatch(level, LazyText(details, render))
 }
 
 func(log *BoundLog) Debug(msg Message) {
@@ -192,6 +388,14 @@ func(log *BoundLog) Debugf(details Structure, format string, args ...any) {
 	log.Logf(DEBUG, details, format, args...)
 }
 
+func(log *BoundLog) DebugErr(err error, details Structure) {
+	log.LogErr(DEBUG, err, details)
+}
+
+func(log *BoundLog) Debugl(details Structure, render func() string) {
+	log.Logl(DEBUG, details, render)
+}
+
 func(log *BoundLog) Config(msg Message) {
 	log.Log(CONFIG, msg)
 }
@@ -204,6 +408,14 @@ func(log *BoundLog) Configf(details Structure, format string, args ...any) {
 	log.Logf(CONFIG, details, format, args...)
 }
 
+func(log *BoundLog) ConfigErr(err error, details Structure) {
+	log.LogErr(CONFIG, err, details)
+}
+
+func(log *BoundLog) Configl(details Structure, render func() string) {
+	log.Logl(CONFIG, details, render)
+}
+
 func(log *BoundLog) Info(msg Message) {
 	log.Log(INFO, msg)
 }
@@ -216,6 +428,14 @@ func(log *BoundLog) Infof(details Structure, format string, args ...any) {
 	log.Logf(INFO, details, format, args...)
 }
 
+func(log *BoundLog) InfoErr(err error, details Structure) {
+	log.LogErr(INFO, err, details)
+}
+
+func(log *BoundLog) Infol(details Structure, render func() string) {
+	log.Logl(INFO, details, render)
+}
+
 func(log *BoundLog) Warn(msg Message) {
 	log.Log(WARNING, msg)
 }
@@ -228,6 +448,14 @@ func(log *BoundLog) Warnf(details Structure, format string, args ...any) {
 	log.Logf(WARNING, details, format, args...)
 }
 
+func(log *BoundLog) WarnErr(err error, details Structure) {
+	log.LogErr(WARNING, err, details)
+}
+
+func(log *BoundLog) Warnl(details Structure, render func() string) {
+	log.Logl(WARNING, details, render)
+}
+
 func(log *BoundLog) Error(msg Message) {
 	log.Log(ERROR, msg)
 }
@@ -240,6 +468,14 @@ func(log *BoundLog) Errorf(details Structure, format string, args ...any) {
 	log.Logf(ERROR, details, format, args...)
 }
 
+func(log *BoundLog) ErrorErr(err error, details Structure) {
+	log.LogErr(ERROR, err, details)
+}
+
+func(log *BoundLog) Errorl(details Structure, render func() string) {
+	log.Logl(ERROR, details, render)
+}
+
 func(log *BoundLog) Misuse(msg Message) {
 	log.Log(MISUSE, msg)
 }
@@ -252,6 +488,14 @@ func(log *BoundLog) Misusef(details Structure, format string, args ...any) {
 	log.Logf(MISUSE, details, format, args...)
 }
 
+func(log *BoundLog) MisuseErr(err error, details Structure) {
+	log.LogErr(MISUSE, err, details)
+}
+
+func(log *BoundLog) Misusel(details Structure, render func() string) {
+	log.Logl(MISUSE, details, render)
+}
+
 func(log *BoundLog) Fatal(msg Message) {
 	log.Log(FATAL, msg)
 }
@@ -264,6 +508,14 @@ func(log *BoundLog) Fatalf(details Structure, format string, args ...any) {
 	log.Logf(FATAL, details, format, args...)
 }
 
+func(log *BoundLog) FatalErr(err error, details Structure) {
+	log.LogErr(FATAL, err, details)
+}
+
+func(log *BoundLog) Fatall(details Structure, render func() string) {
+	log.Logl(FATAL, details, render)
+}
+
 type LoggerWalker interface {
 	EnterLogger(Logger, int, uint, bool) bool
 	LeaveLogger(Logger, int, uint, bool)
@@ -305,3 +557,24 @@ func walkLoggersRec(logger Logger, walker LoggerWalker, index int, depth uint, s
 	}
 	walker.LeaveLogger(logger, index, depth, leaf)
 }
+
+type flushWalker struct {}
+
+func(walker flushWalker) EnterLogger(logger Logger, index int, depth uint, leaf bool) bool {
+	if flusher, ok := logger.(Flusher); ok {
+		flusher.Flush()
+	}
+	return false
+}
+
+func(walker flushWalker) LeaveLogger(logger Logger, index int, depth uint, leaf bool) {}
+
+func(walker flushWalker) SkipLogger(logger Logger, index int, depth uint, leaf bool, seen bool) {}
+
+func FlushLoggers(root Logger) {
+	if root != nil {
+		WalkLoggers(root, flushWalker{})
+	}
+}
+
+var _ LoggerWalker = flushWalker{}
@@ -23,13 +23,37 @@ type Message interface {
 	Lines() []string
 }
 
+type MessageWrapper interface {
+	UnwrapMessage() Message
+}
+
+func FindMessage[MessageT any](msg Message) (MessageT, bool) {
+	for msg != nil {
+		if found, ok := msg.(MessageT); ok {
+			return found, true
+		}
+		wrapper, ok := msg.(MessageWrapper)
+		if !ok {
+			break
+		}
+		msg = wrapper.UnwrapMessage()
+	}
+	var none MessageT
+	return none, false
+}
+
 type Source interface {
 	StringSource() string
 }
 
+type AppendSource interface {
+	AppendStringSource([]byte) []byte
+}
+
 type Packet struct {
 	Level Level
 	Message Message
 	Source Source
 	Timestamp time.Time
+	Stack StackTrace
 }
@@ -3,6 +3,7 @@ package golog
 type MultiLogger struct {
 	ID uintptr
 	Children []Logger
+	enabled EnabledCache
 }
 
 func(logger *MultiLogger) Log(packet *Packet) {
@@ -13,6 +14,25 @@ func(logger *MultiLogger) Log(packet *Packet) {
 	}
 }
 
+func(logger *MultiLogger) Enabled(level Level, source Source) bool {
+	return logger.enabled.Lookup(level, source, func() bool {
+		for _, child := range logger.Children {
+			if child != nil && LoggerEnabled(child, level, source) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func(logger *MultiLogger) enabledCache() *EnabledCache {
+	return &logger.enabled
+}
+
+func(logger *MultiLogger) Invalidate() {
+	InvalidateLoggers(logger)
+}
+
 func(logger *MultiLogger) Close() {
 	for _, child := range logger.Children {
 		if child != nil {
@@ -36,3 +56,5 @@ func(logger *MultiLogger) Identity() uintptr {
 }
 
 var _ Logger = &MultiLogger{}
+var _ EnabledChecker = &MultiLogger{}
+var _ EnabledInvalidator = &MultiLogger{}
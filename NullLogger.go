@@ -8,6 +8,10 @@ func(logger *NullLogger) Log(*Packet) {}
 
 func(logger *NullLogger) Close() {}
 
+func(logger *NullLogger) Enabled(Level, Source) bool {
+	return false
+}
+
 func(logger *NullLogger) SubLoggers() []Logger {
 	return nil
 }
@@ -17,3 +21,4 @@ func(logger *NullLogger) Identity() uintptr {
 }
 
 var _ Logger = &NullLogger{}
+var _ EnabledChecker = &NullLogger{}
@@ -0,0 +1,192 @@
+package golog
+
+import (
+	"time"
+	"strconv"
+)
+
+const (
+	PacketTimeKey = "time"
+	PacketLevelKey = "level"
+	PacketLevelNumberKey = "levelNumber"
+	PacketNominalKey = "nominal"
+	PacketSourceKey = "source"
+	PacketLinesKey = "lines"
+	PacketDetailsKey = "details"
+	PacketStackKey = StackStructKey
+)
+
+type PacketStructure struct {
+	Packet *Packet
+}
+
+func(ps PacketStructure) PutStruct(sink StructSink) {
+	packet := ps.Packet
+	if packet == nil {
+		return
+	}
+	m := sink.Map()
+	if !packet.Timestamp.IsZero() {
+		PutTimeProperty(m, PacketTimeKey, packet.Timestamp)
+	}
+	if packet.Level != nil {
+		m.StringProperty(PacketLevelKey, packet.Level.HumanReadable(ADJ_NONE))
+		m.IntProperty(PacketLevelNumberKey, int64(packet.Level.Numerical()))
+		m.BoolProperty(PacketNominalKey, packet.Level.IsNominal())
+	}
+	if packet.Source != nil {
+		m.StringProperty(PacketSourceKey, packet.Source.StringSource())
+	}
+	if packet.Message != nil {
+		lines := m.ListProperty(PacketLinesKey)
+		for _, line := range packet.Message.Lines() {
+			lines.String(line)
+		}
+		lines.EndList()
+		if !IsEmptyStructure(packet.Message) {
+			packet.Message.PutStruct(&propertySink {
+				target: m,
+				name: PacketDetailsKey,
+			})
+		}
+	}
+	if len(packet.Stack) > 0 {
+		packet.Stack.PutStruct(&propertySink {
+			target: m,
+			name: PacketStackKey,
+		})
+	}
+	m.EndMap()
+}
+
+type PacketDecodeError struct {
+	Key string
+	Reason string
+}
+
+func(err *PacketDecodeError) Error() string {
+	if len(err.Key) == 0 {
+		return "invalid packet: " + err.Reason
+	}
+	return "invalid packet property " + strconv.Quote(err.Key) + ": " + err.Reason
+}
+
+func PacketFromValue(value *Value) (*Packet, error) {
+	if value == nil || value.Kind != VAL_MAP {
+		return nil, &PacketDecodeError {
+			Reason: "not a map",
+		}
+	}
+	packet := &Packet{}
+	if stamp := value.Field(PacketTimeKey); stamp != nil {
+		switch stamp.Kind {
+			case VAL_TIME:
+				packet.Timestamp = stamp.Time
+			case VAL_STRING:
+				parsed, err := time.Parse(time.RFC3339Nano, stamp.String)
+				if err != nil {
+					return nil, &PacketDecodeError {
+						Key: PacketTimeKey,
+						Reason: err.Error(),
+					}
+				}
+				packet.Timestamp = parsed
+			default:
+				return nil, &PacketDecodeError {
+					Key: PacketTimeKey,
+					Reason: "not a timestamp",
+				}
+		}
+	}
+	level, err := levelFromValue(value)
+	if err != nil {
+		return nil, err
+	}
+	packet.Level = level
+	if source := value.Field(PacketSourceKey); source != nil && source.Kind == VAL_STRING {
+		packet.Source = TextSource(source.String)
+	}
+	lines := value.Field(PacketLinesKey)
+	details := value.Field(PacketDetailsKey)
+	if lines != nil || details != nil {
+		message := &StringMessage{}
+		if lines != nil {
+			if lines.Kind != VAL_LIST {
+				return nil, &PacketDecodeError {
+					Key: PacketLinesKey,
+					Reason: "not a list",
+				}
+			}
+			message.Text = make([]string, 0, len(lines.Items))
+			for _, line := range lines.Items {
+				if line == nil || line.Kind != VAL_STRING {
+					return nil, &PacketDecodeError {
+						Key: PacketLinesKey,
+						Reason: "line is not a string",
+					}
+				}
+				message.Text = append(message.Text, line.String)
+			}
+		}
+		if details != nil {
+			message.Details = details
+		}
+		packet.Message = message
+	}
+	if stack := value.Field(PacketStackKey); stack != nil {
+		trace, ok := stackFromValue(stack)
+		if !ok {
+			return nil, &PacketDecodeError {
+				Key: PacketStackKey,
+				Reason: "not a stack trace",
+			}
+		}
+		packet.Stack = trace
+	}
+	return packet, nil
+}
+
+func levelFromValue(value *Value) (Level, error) {
+	name := value.Field(PacketLevelKey)
+	number := value.Field(PacketLevelNumberKey)
+	if number == nil {
+		if name == nil || name.Kind != VAL_STRING {
+			return nil, nil
+		}
+		level, err := LevelFromName(name.String)
+		if err != nil {
+			return nil, &PacketDecodeError {
+				Key: PacketLevelKey,
+				Reason: "unknown level " + strconv.Quote(name.String),
+			}
+		}
+		return level, nil
+	}
+	level := &GenericLevel{}
+	switch number.Kind {
+		case VAL_INT:
+			level.Number = int(number.Int)
+		case VAL_UINT:
+			level.Number = int(number.Uint)
+		default:
+			return nil, &PacketDecodeError {
+				Key: PacketLevelNumberKey,
+				Reason: "not an integer",
+			}
+	}
+	if name != nil && name.Kind == VAL_STRING {
+		if known, ok := Levels.Lookup(name.String); ok && known.Numerical() == level.Number {
+			return known, nil
+		}
+		level.Name = name.String
+	}
+	if nominal := value.Field(PacketNominalKey); nominal != nil && nominal.Kind == VAL_BOOL {
+		level.Nominal = nominal.Bool
+	} else {
+		level.Nominal = level.Number < int(WARNING)
+	}
+	return level, nil
+}
+
+var _ Structure = PacketStructure{}
+var _ error = &PacketDecodeError{}
@@ -0,0 +1,145 @@
+package golog
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+type Alignment uint
+
+const (
+	ALIGN_LEFT Alignment = iota
+	ALIGN_RIGHT
+	ALIGN_CENTER
+)
+
+type TruncateMode uint
+
+const (
+	TRUNC_END TruncateMode = iota
+	TRUNC_START
+)
+
+type PaddedLineFormatter struct {
+	Formatter LineFormatter
+	MinWidth int
+	MaxWidth int
+	Alignment Alignment
+	Truncate TruncateMode
+	Ellipsis string
+	Padding rune
+}
+
+func(form *PaddedLineFormatter) PacketToLine(packet *Packet, builder *strings.Builder) {
+	var inner strings.Builder
+	if form.Formatter != nil {
+		form.Formatter.PacketToLine(packet, &inner)
+	}
+	text := inner.String()
+	width := TextWidth(text)
+	if form.MaxWidth > 0 && width > form.MaxWidth {
+		text = TruncateText(text, form.MaxWidth, form.Truncate, form.Ellipsis)
+		width = TextWidth(text)
+	}
+	missing := form.MinWidth - width
+	if missing <= 0 {
+		builder.WriteString(text)
+		return
+	}
+	padding := form.Padding
+	if padding == 0 {
+		padding = ' '
+	}
+	var before, after int
+	switch form.Alignment {
+		case ALIGN_RIGHT:
+			before = missing
+		case ALIGN_CENTER:
+			before = missing / 2
+			after = missing - before
+		default:
+			after = missing
+	}
+	builder.WriteString(string(RepeatRune(padding, before)))
+	builder.WriteString(text)
+	builder.WriteString(string(RepeatRune(padding, after)))
+}
+
+type textToken struct {
+	text string
+	width int
+	escape bool
+}
+
+func tokenizeText(text string) []textToken {
+	var tokens []textToken
+	for index := 0; index < len(text); {
+		if text[index] == '\x1b' {
+			length := escapeSequenceLength(text, index)
+			tokens = append(tokens, textToken {
+				text: text[index:index + length],
+				escape: true,
+			})
+			index += length
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(text[index:])
+		tokens = append(tokens, textToken {
+			text: text[index:index + size],
+			width: RuneWidth(r),
+		})
+		index += size
+	}
+	return tokens
+}
+
+func TruncateText(text string, maxWidth int, mode TruncateMode, ellipsis string) string {
+	if maxWidth <= 0 || TextWidth(text) <= maxWidth {
+		return text
+	}
+	budget := maxWidth
+	ellipsisWidth := TextWidth(ellipsis)
+	if ellipsisWidth < budget {
+		budget -= ellipsisWidth
+	} else {
+		ellipsis = ""
+	}
+	tokens := tokenizeText(text)
+	keep := make([]bool, len(tokens))
+	used := 0
+	visit := func(index int) {
+		token := &tokens[index]
+		if token.escape {
+			keep[index] = true
+		} else if used + token.width <= budget {
+			keep[index] = true
+			used += token.width
+		} else {
+			budget = used
+		}
+	}
+	if mode == TRUNC_START {
+		for index := len(tokens) - 1; index >= 0; index-- {
+			visit(index)
+		}
+	} else {
+		for index := range tokens {
+			visit(index)
+		}
+	}
+	var builder strings.Builder
+	if mode == TRUNC_START {
+		builder.WriteString(ellipsis)
+	}
+	for index, token := range tokens {
+		if keep[index] {
+			builder.WriteString(token.text)
+		}
+	}
+	if mode != TRUNC_START {
+		builder.WriteString(ellipsis)
+	}
+	return builder.String()
+}
+
+var _ LineFormatter = &PaddedLineFormatter{}
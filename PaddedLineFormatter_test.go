@@ -0,0 +1,64 @@
+package golog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTruncateText(t *testing.T) {
+	cases := []struct {
+		text string
+		width int
+		mode TruncateMode
+		ellipsis string
+		want string
+	}{
+		{ "abcdef", 4, TRUNC_END, "", "abcd" },
+		{ "abcdef", 4, TRUNC_START, "", "cdef" },
+		{ "abcdef", 4, TRUNC_END, "…", "abc…" },
+		{ "abcdef", 4, TRUNC_START, "...", "...f" },
+		{ "abcdef", 2, TRUNC_END, "...", "ab" },
+		{ "abc", 4, TRUNC_END, "...", "abc" },
+		{ "日本語", 4, TRUNC_END, "", "日本" },
+		{ "日本語", 3, TRUNC_END, "", "日" },
+		{ "\x1b[31mabcdef\x1b[0m", 3, TRUNC_END, "", "\x1b[31mabc\x1b[0m" },
+	}
+	for _, c := range cases {
+		if got := TruncateText(c.text, c.width, c.mode, c.ellipsis); got != c.want {
+			t.Errorf("TruncateText(%q, %d, %d, %q) = %q, want %q", c.text, c.width, c.mode, c.ellipsis, got, c.want)
+		}
+	}
+}
+
+func TestPaddedLineFormatter(t *testing.T) {
+	packet := &Packet {
+		Message: &StringMessage {
+			Text: []string { "abc" },
+		},
+	}
+	cases := []struct {
+		form PaddedLineFormatter
+		want string
+	}{
+		{ PaddedLineFormatter { MinWidth: 6 }, "abc   " },
+		{ PaddedLineFormatter { MinWidth: 6, Alignment: ALIGN_RIGHT }, "   abc" },
+		{ PaddedLineFormatter { MinWidth: 6, Alignment: ALIGN_CENTER, Padding: '.' }, ".abc.." },
+		{ PaddedLineFormatter { MaxWidth: 2 }, "ab" },
+		{ PaddedLineFormatter { MinWidth: 2 }, "abc" },
+	}
+	for _, c := range cases {
+		form := c.form
+		form.Formatter = &GenericMessageLineFormatter{}
+		var builder strings.Builder
+		form.PacketToLine(packet, &builder)
+		if got := builder.String(); got != c.want {
+			t.Errorf("%+v: got %q, want %q", c.form, got, c.want)
+		}
+	}
+}
+
+func TestTextWidthIgnoresEscapes(t *testing.T) {
+	if got := TextWidth("\x1b[1mab\x1b[0m日"); got != 4 {
+		t.Fatalf("width %d", got)
+	}
+}
@@ -0,0 +1,76 @@
+package golog
+
+import (
+	"fmt"
+	"bytes"
+	"strconv"
+	"runtime"
+	"strings"
+)
+
+const (
+	PanicStructKey = "panic"
+	PanicTypeStructKey = "panicType"
+	GoroutineStructKey = "goroutine"
+	PanicSummary = "panic"
+)
+
+type PanicMessage struct {
+	Value any
+	Goroutine uint64
+	Details Structure
+}
+
+func(msg *PanicMessage) Lines() []string {
+	if err, ok := msg.Value.(error); ok {
+		return CausesToLines(PanicSummary, CollectErrorCauses(err), DefaultCauseIndent, "")
+	}
+	return strings.Split(fmt.Sprintf("%s: %v", PanicSummary, msg.Value), "\n")
+}
+
+func(msg *PanicMessage) PutStruct(sink StructSink) {
+	m := sink.Map()
+	var seen map[string]bool
+	if msg.Details != nil {
+		seen = make(map[string]bool)
+		msg.Details.PutStruct(&inlineSink {
+			target: m,
+			seen: seen,
+		})
+	}
+	if !seen[PanicStructKey] {
+		DumbStructEncoder.PutProperty(m, PanicStructKey, msg.Value)
+	}
+	if !seen[PanicTypeStructKey] {
+		m.StringProperty(PanicTypeStructKey, fmt.Sprintf("%T", msg.Value))
+	}
+	if msg.Goroutine != 0 && !seen[GoroutineStructKey] {
+		PutUintProperty(m, GoroutineStructKey, msg.Goroutine)
+	}
+	m.EndMap()
+}
+
+func CurrentGoroutineID() uint64 {
+	var buffer [64]byte
+	header := buffer[:runtime.Stack(buffer[:], false)]
+	header = bytes.TrimPrefix(header, []byte("goroutine "))
+	end := bytes.IndexByte(header, ' ')
+	if end < 0 {
+		return 0
+	}
+	id, err := strconv.ParseUint(string(header[:end]), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+func CapturePanicStack(skip int) StackTrace {
+	trace := CaptureStack(skip + 1)
+	for len(trace) > 0 && strings.HasPrefix(trace[0].Function, "runtime.") {
+		trace = trace[1:]
+	}
+	return trace
+}
+
+var _ Message = &PanicMessage{}
@@ -21,6 +21,13 @@ func(pred *LevelPredicate) Match(packet *Packet) bool {
 	}
 }
 
+func(pred *LevelPredicate) MatchEnabled(level Level, source Source) (bool, bool) {
+	if level == nil || pred.Predicate == nil {
+		return pred.MissingResult, true
+	}
+	return pred.Predicate.Match(level), true
+}
+
 type MessagePredicate struct {
 	Predicate Predicate[Message]
 	MissingResult bool
@@ -34,6 +41,13 @@ func(pred *MessagePredicate) Match(packet *Packet) bool {
 	}
 }
 
+func(pred *MessagePredicate) MatchEnabled(level Level, source Source) (bool, bool) {
+	if pred.Predicate == nil {
+		return pred.MissingResult, true
+	}
+	return false, false
+}
+
 type SourcePredicate struct {
 	Predicate Predicate[Source]
 	MissingResult bool
@@ -47,6 +61,13 @@ func(pred *SourcePredicate) Match(packet *Packet) bool {
 	}
 }
 
+func(pred *SourcePredicate) MatchEnabled(level Level, source Source) (bool, bool) {
+	if source == nil || pred.Predicate == nil {
+		return pred.MissingResult, true
+	}
+	return pred.Predicate.Match(source), true
+}
+
 type TimestampPredicate struct {
 	Predicate Predicate[time.Time]
 	MissingResult bool
@@ -91,12 +112,20 @@ func(pred TruePredicate[SubjectT]) Match(SubjectT) bool {
 	return true
 }
 
+func(pred TruePredicate[SubjectT]) MatchEnabled(Level, Source) (bool, bool) {
+	return true, true
+}
+
 type FalsePredicate[SubjectT any] struct {}
 
 func(pred FalsePredicate[SubjectT]) Match(SubjectT) bool {
 	return false
 }
 
+func(pred FalsePredicate[SubjectT]) MatchEnabled(Level, Source) (bool, bool) {
+	return false, true
+}
+
 type AllPredicate[SubjectT any] struct {
 	Children []Predicate[SubjectT]
 }
@@ -113,6 +142,21 @@ func(pred AllPredicate[SubjectT]) Match(subject SubjectT) bool {
 	return true
 }
 
+func(pred AllPredicate[SubjectT]) MatchEnabled(level Level, source Source) (bool, bool) {
+	allKnown := true
+	for _, child := range pred.Children {
+		if child == nil {
+			continue
+		}
+		result, known := PredicateEnabled(child, level, source)
+		if known && !result {
+			return false, true
+		}
+		allKnown = allKnown && known
+	}
+	return allKnown, allKnown
+}
+
 type AnyPredicate[SubjectT any] struct {
 	Children []Predicate[SubjectT]
 }
@@ -129,6 +173,21 @@ func(pred AnyPredicate[SubjectT]) Match(subject SubjectT) bool {
 	return false
 }
 
+func(pred AnyPredicate[SubjectT]) MatchEnabled(level Level, source Source) (bool, bool) {
+	allKnown := true
+	for _, child := range pred.Children {
+		if child == nil {
+			continue
+		}
+		result, known := PredicateEnabled(child, level, source)
+		if known && result {
+			return true, true
+		}
+		allKnown = allKnown && known
+	}
+	return false, allKnown
+}
+
 type NonePredicate[SubjectT any] struct {
 	Children []Predicate[SubjectT]
 }
@@ -145,10 +204,23 @@ func(pred NonePredicate[SubjectT]) Match(subject SubjectT) bool {
 	return true
 }
 
+func(pred NonePredicate[SubjectT]) MatchEnabled(level Level, source Source) (bool, bool) {
+	result, known := AnyPredicate[SubjectT] {
+		Children: pred.Children,
+	}.MatchEnabled(level, source)
+	return !result, known
+}
+
 var _ Predicate[*Packet] = &LevelPredicate{}
 var _ Predicate[*Packet] = &MessagePredicate{}
 var _ Predicate[*Packet] = &SourcePredicate{}
 var _ Predicate[*Packet] = &TimestampPredicate{}
+var _ EnabledPredicate = &LevelPredicate{}
+var _ EnabledPredicate = &MessagePredicate{}
+var _ EnabledPredicate = &SourcePredicate{}
+var _ EnabledPredicate = AllPredicate[*Packet]{}
+var _ EnabledPredicate = AnyPredicate[*Packet]{}
+var _ EnabledPredicate = NonePredicate[*Packet]{}
 
 var _ Predicate[Level] = &LevelOrderPredicate{}
 
@@ -0,0 +1,94 @@
+package golog
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+const (
+	StackStructKey = "stack"
+	FrameFunctionStructKey = "function"
+	FrameFileStructKey = "file"
+	FrameLineStructKey = "line"
+	MaxStackDepth = 64
+	DefaultStackIndent = "  at "
+)
+
+type StackFrame struct {
+	Function string
+	File string
+	Line int
+}
+
+func(frame *StackFrame) String() string {
+	return fmt.Sprintf("%s (%s:%d)", frame.Function, frame.File, frame.Line)
+}
+
+type StackTrace []StackFrame
+
+func(trace StackTrace) PutStruct(sink StructSink) {
+	list := sink.List()
+	for _, frame := range trace {
+		m := list.Map()
+		m.StringProperty(FrameFunctionStructKey, frame.Function)
+		m.StringProperty(FrameFileStructKey, frame.File)
+		m.IntProperty(FrameLineStructKey, int64(frame.Line))
+		m.EndMap()
+	}
+	list.EndList()
+}
+
+var packagePrefix = reflect.TypeOf(Packet{}).PkgPath() + "."
+
+func isGologFunction(function string) bool {
+	return strings.HasPrefix(function, packagePrefix)
+}
+
+func CaptureStack(skip int) StackTrace {
+	pcs := make([]uintptr, MaxStackDepth)
+	count := runtime.Callers(skip + 2, pcs)
+	if count == 0 {
+		return nil
+	}
+	var trace StackTrace
+	frames := runtime.CallersFrames(pcs[:count])
+	for {
+		frame, more := frames.Next()
+		if len(frame.Function) > 0 && !isGologFunction(frame.Function) {
+			trace = append(trace, StackFrame {
+				Function: frame.Function,
+				File: frame.File,
+				Line: frame.Line,
+			})
+		}
+		if !more {
+			break
+		}
+	}
+	return trace
+}
+
+var DumbStackPredicate Predicate[Level] = &LevelOrderPredicate {
+	Threshold: int(ERROR),
+	Relation: ORDR_GREATER_EQUAL,
+}
+
+var NoStackPredicate Predicate[Level] = FalsePredicate[Level]{}
+
+func wantStack(level Level, predicate Predicate[Level]) bool {
+	if predicate == nil {
+		predicate = DumbStackPredicate
+	}
+	return predicate != nil && level != nil && predicate.Match(level)
+}
+
+func attachStack(packet *Packet, predicate Predicate[Level]) {
+	if packet.Stack == nil && wantStack(packet.Level, predicate) {
+		packet.Stack = CaptureStack(1)
+	}
+}
+
+var _ Structure = StackTrace{}
+var _ fmt.Stringer = &StackFrame{}
@@ -0,0 +1,61 @@
+package golog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStackCaptureDefaults(t *testing.T) {
+	sink := newCaptureLogger()
+	log := &Log {
+		Logger: sink,
+	}
+	log.Warnv(nil, nil, "below threshold")
+	if stack := sink.Last().Packet.Stack; stack != nil {
+		t.Fatalf("stack captured below threshold: %v", stack)
+	}
+	log.Errorv(nil, nil, "with stack")
+	stack := sink.Last().Packet.Stack
+	if len(stack) == 0 {
+		t.Fatal("default Log captured no stack at ERROR")
+	}
+	for _, frame := range stack {
+		if strings.HasPrefix(frame.Function, packagePrefix) {
+			t.Fatalf("golog frame leaked into trace: %s", frame.Function)
+		}
+	}
+	log.StackPredicate = NoStackPredicate
+	log.Fatalv(nil, nil, "opted out")
+	if stack := sink.Last().Packet.Stack; stack != nil {
+		t.Fatalf("stack captured despite NoStackPredicate: %v", stack)
+	}
+}
+
+func TestBoundLogStackPredicate(t *testing.T) {
+	sink := newCaptureLogger()
+	log := (&BoundLog {
+		Logger: sink,
+		StackPredicate: DumbStackPredicate,
+	}).With("k", "v")
+	log.Fatalv(nil, "boom")
+	if len(sink.Last().Packet.Stack) == 0 {
+		t.Fatal("derived BoundLog lost its stack predicate")
+	}
+}
+
+func TestStackTraceStructure(t *testing.T) {
+	trace := StackTrace {
+		{
+			Function: "main.run",
+			File: "main.go",
+			Line: 12,
+		},
+	}
+	value := CaptureValue(trace)
+	if len(value.Items) != 1 || value.Items[0].Field(FrameLineStructKey).Int != 12 {
+		t.Fatalf("unexpected structure %v", value.ToAny())
+	}
+	if got := trace[0].String(); got != "main.run (main.go:12)" {
+		t.Fatalf("frame rendered %q", got)
+	}
+}
@@ -20,68 +20,31 @@ func(level DefaultLevel) Numerical() int {
 	return int(level)
 }
 
-func(level DefaultLevel) HumanReadable(adjust Adjustment) string {
-	switch adjust {
-		case ADJ_LEFT:
-			switch level {
-				case DEBUG:
-					return "DEBUG  "
-				case CONFIG:
-					return "CONFIG "
-				case INFO:
-					return "INFO   "
-				case WARNING:
-					return "WARNING"
-				case ERROR:
-					return "ERROR  "
-				case MISUSE:
-					return "MISUSE "
-				case FATAL:
-					return "FATAL  "
-				default:
-					return "???    "
-			}
-		case ADJ_RIGHT:
-			switch level {
-				case DEBUG:
-					return "  DEBUG"
-				case CONFIG:
-					return " CONFIG"
-				case INFO:
-					return "   INFO"
-				case WARNING:
-					return "WARNING"
-				case ERROR:
-					return "  ERROR"
-				case MISUSE:
-					return " MISUSE"
-				case FATAL:
-					return "  FATAL"
-				default:
-					return "    ???"
-			}
+func(level DefaultLevel) name() string {
+	switch level {
+		case DEBUG:
+			return "DEBUG"
+		case CONFIG:
+			return "CONFIG"
+		case INFO:
+			return "INFO"
+		case WARNING:
+			return "WARNING"
+		case ERROR:
+			return "ERROR"
+		case MISUSE:
+			return "MISUSE"
+		case FATAL:
+			return "FATAL"
 		default:
-			switch level {
-				case DEBUG:
-					return "DEBUG"
-				case CONFIG:
-					return "CONFIG"
-				case INFO:
-					return "INFO"
-				case WARNING:
-					return "WARNING"
-				case ERROR:
-					return "ERROR"
-				case MISUSE:
-					return "MISUSE"
-				case FATAL:
-					return "FATAL"
-				default:
-					return "???"
-			}
+			return "???"
 	}
 }
 
+func(level DefaultLevel) HumanReadable(adjust Adjustment) string {
+	return AdjustLevelName(level.name(), adjust)
+}
+
 func(level DefaultLevel) IsNominal() bool {
 	return level < WARNING
 }
@@ -107,6 +70,26 @@ func ParseDefaultLevel(spec string) Level {
 	}
 }
 
+const DefaultLevelWidth = 7
+
+type GenericLevel struct {
+	Number int
+	Name string
+	Nominal bool
+}
+
+func(level *GenericLevel) Numerical() int {
+	return level.Number
+}
+
+func(level *GenericLevel) HumanReadable(adjust Adjustment) string {
+	return AdjustLevelName(level.Name, adjust)
+}
+
+func(level *GenericLevel) IsNominal() bool {
+	return level.Nominal
+}
+
 type StringMessage struct {
 	Text []string
 	Details Structure
@@ -132,29 +115,47 @@ func(src *DefaultSource) StringSource() string {
 	if src == nil {
 		return ""
 	}
-	var builder strings.Builder
+	return string(src.AppendStringSource(nil))
+}
+
+func(src *DefaultSource) AppendStringSource(dst []byte) []byte {
+	if src == nil {
+		return dst
+	}
 	var needDot bool
 	if len(src.Module) > 0 {
-		builder.WriteString(src.Module)
+		dst = append(dst, src.Module...)
 		needDot = true
 	}
 	if len(src.Type) > 0 {
 		if needDot {
-			builder.WriteRune('.')
+			dst = append(dst, '.')
 		} else {
 			needDot = true
 		}
-		builder.WriteString(src.Type)
+		dst = append(dst, src.Type...)
 	}
 	if len(src.Function) > 0 {
 		if needDot {
-			builder.WriteRune('.')
+			dst = append(dst, '.')
 		}
-		builder.WriteString(src.Function)
+		dst = append(dst, src.Function...)
 	}
-	return builder.String()
+	return dst
+}
+
+func(src TextSource) AppendStringSource(dst []byte) []byte {
+	return append(dst, src...)
+}
+
+type TextSource string
+
+func(src TextSource) StringSource() string {
+	return string(src)
 }
 
 var _ Level = INFO
+var _ Level = &GenericLevel{}
+var _ Source = TextSource("")
 var _ Message = &StringMessage{}
 var _ Source = &DefaultSource{}
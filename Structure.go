@@ -1,8 +1,9 @@
 package golog
 
 import (
-	"fmt"
-	"strings"
+	"time"
+	"strconv"
+	"encoding/base64"
 )
 
 type StructSink interface {
@@ -29,19 +30,123 @@ type StructList interface {
 	EndList()
 }
 
+type ExtendedStructMap interface {
+	StructMap
+	TimeProperty(string, time.Time)
+	DurationProperty(string, time.Duration)
+	BytesProperty(string, []byte)
+	UintProperty(string, uint64)
+	NullProperty(string)
+}
+
+type ExtendedStructList interface {
+	StructList
+	Time(time.Time)
+	Duration(time.Duration)
+	Bytes([]byte)
+	Uint(uint64)
+	Null()
+}
+
 type Structure interface {
 	PutStruct(StructSink)
 }
 
+const NullText = "<nil>"
+
+func PutTimeProperty(m StructMap, name string, value time.Time) {
+	if ext, ok := m.(ExtendedStructMap); ok {
+		ext.TimeProperty(name, value)
+	} else {
+		m.StringProperty(name, value.Format(time.RFC3339Nano))
+	}
+}
+
+func PutDurationProperty(m StructMap, name string, value time.Duration) {
+	if ext, ok := m.(ExtendedStructMap); ok {
+		ext.DurationProperty(name, value)
+	} else {
+		m.StringProperty(name, value.String())
+	}
+}
+
+func PutBytesProperty(m StructMap, name string, value []byte) {
+	if ext, ok := m.(ExtendedStructMap); ok {
+		ext.BytesProperty(name, value)
+	} else {
+		m.StringProperty(name, base64.StdEncoding.EncodeToString(value))
+	}
+}
+
+func PutUintProperty(m StructMap, name string, value uint64) {
+	if ext, ok := m.(ExtendedStructMap); ok {
+		ext.UintProperty(name, value)
+	} else if value <= uint64(1 << 63 - 1) {
+		m.IntProperty(name, int64(value))
+	} else {
+		m.StringProperty(name, strconv.FormatUint(value, 10))
+	}
+}
+
+func PutNullProperty(m StructMap, name string) {
+	if ext, ok := m.(ExtendedStructMap); ok {
+		ext.NullProperty(name)
+	} else {
+		m.StringProperty(name, NullText)
+	}
+}
+
+func PutTime(l StructList, value time.Time) {
+	if ext, ok := l.(ExtendedStructList); ok {
+		ext.Time(value)
+	} else {
+		l.String(value.Format(time.RFC3339Nano))
+	}
+}
+
+func PutDuration(l StructList, value time.Duration) {
+	if ext, ok := l.(ExtendedStructList); ok {
+		ext.Duration(value)
+	} else {
+		l.String(value.String())
+	}
+}
+
+func PutBytes(l StructList, value []byte) {
+	if ext, ok := l.(ExtendedStructList); ok {
+		ext.Bytes(value)
+	} else {
+		l.String(base64.StdEncoding.EncodeToString(value))
+	}
+}
+
+func PutUint(l StructList, value uint64) {
+	if ext, ok := l.(ExtendedStructList); ok {
+		ext.Uint(value)
+	} else if value <= uint64(1 << 63 - 1) {
+		l.Int(int64(value))
+	} else {
+		l.String(strconv.FormatUint(value, 10))
+	}
+}
+
+func PutNull(l StructList) {
+	if ext, ok := l.(ExtendedStructList); ok {
+		ext.Null()
+	} else {
+		l.String(NullText)
+	}
+}
+
 type TextStructSink struct {
-	builder strings.Builder
+	buffer []byte
 	stack BoolStack
 	KeepOutermostParens bool
 }
 
 func(sink *TextStructSink) Map() StructMap {
 	if !sink.enterElement() || sink.KeepOutermostParens {
-		sink.builder.WriteRune('{')
+		sink.buffer = append(sink.buffer, '{')
 	}
 	sink.stack.Push(false)
 	return sink
@@ -49,7 +154,7 @@ func(sink *TextStructSink) Map() StructMap {
 
 func(sink *TextStructSink) List() StructList {
 	if !sink.enterElement() || sink.KeepOutermostParens {
-		sink.builder.WriteRune('[')
+		sink.buffer = append(sink.buffer, '[')
 	}
 	sink.stack.Push(false)
 	return sink
@@ -60,43 +165,74 @@ func(sink *TextStructSink) enterElement() bool {
 		return true
 	}
 	if sink.stack.Top() {
-		sink.builder.WriteString(", ")
+		sink.buffer = append(sink.buffer, ", "...)
 	} else {
 		sink.stack.Replace(true)
 	}
 	return false
 }
 
-func(sink *TextStructSink) BoolProperty(name string, value bool) {
+func(sink *TextStructSink) key(name string) {
 	sink.enterElement()
-	sink.builder.WriteString(fmt.Sprintf("%s: %v", name, value))
+	sink.buffer = append(sink.buffer, name...)
+	sink.buffer = append(sink.buffer, ": "...)
+}
+
+func(sink *TextStructSink) BoolProperty(name string, value bool) {
+	sink.key(name)
+	sink.buffer = strconv.AppendBool(sink.buffer, value)
 }
 
 func(sink *TextStructSink) StringProperty(name string, value string) {
-	sink.enterElement()
-	sink.builder.WriteString(fmt.Sprintf("%s: %q", name, value))
+	sink.key(name)
+	sink.buffer = strconv.AppendQuote(sink.buffer, value)
 }
 
 func(sink *TextStructSink) IntProperty(name string, value int64) {
-	sink.enterElement()
-	sink.builder.WriteString(fmt.Sprintf("%s: %d", name, value))
+	sink.key(name)
+	sink.buffer = strconv.AppendInt(sink.buffer, value, 10)
 }
 
 func(sink *TextStructSink) FloatProperty(name string, value float64) {
-	sink.enterElement()
-	sink.builder.WriteString(fmt.Sprintf("%s: %G", name, value))
+	sink.key(name)
+	sink.buffer = strconv.AppendFloat(sink.buffer, value, 'G', -1, 64)
+}
+
+func(sink *TextStructSink) TimeProperty(name string, value time.Time) {
+	sink.key(name)
+	sink.buffer = value.AppendFormat(sink.buffer, time.RFC3339Nano)
+}
+
+func(sink *TextStructSink) DurationProperty(name string, value time.Duration) {
+	sink.key(name)
+	sink.buffer = append(sink.buffer, value.String()...)
+}
+
+func(sink *TextStructSink) BytesProperty(name string, value []byte) {
+	sink.key(name)
+	sink.buffer = appendHex(append(sink.buffer, "0x"...), value)
+}
+
+func(sink *TextStructSink) UintProperty(name string, value uint64) {
+	sink.key(name)
+	sink.buffer = strconv.AppendUint(sink.buffer, value, 10)
+}
+
+func(sink *TextStructSink) NullProperty(name string) {
+	sink.key(name)
+	sink.buffer = append(sink.buffer, "null"...)
 }
 
 func(sink *TextStructSink) MapProperty(name string) StructMap {
-	sink.enterElement()
-	sink.builder.WriteString(fmt.Sprintf("%s: {", name))
+	sink.key(name)
+	sink.buffer = append(sink.buffer, '{')
 	sink.stack.Push(false)
 	return sink
 }
 
 func(sink *TextStructSink) ListProperty(name string) StructList {
-	sink.enterElement()
-	sink.builder.WriteString(fmt.Sprintf("%s: [", name))
+	sink.key(name)
+	sink.buffer = append(sink.buffer, '[')
 	sink.stack.Push(false)
 	return sink
 }
@@ -104,43 +240,84 @@ func(sink *TextStructSink) ListProperty(name string) StructList {
 func(sink *TextStructSink) EndMap() {
 	sink.stack.Pop()
 	if sink.KeepOutermostParens || !sink.stack.IsEmpty() {
-		sink.builder.WriteRune('}')
+		sink.buffer = append(sink.buffer, '}')
 	}
 }
 
 func(sink *TextStructSink) Bool(value bool) {
 	sink.enterElement()
-	if value {
-		sink.builder.WriteString("true")
-	} else {
-		sink.builder.WriteString("false")
-	}
+	sink.buffer = strconv.AppendBool(sink.buffer, value)
 }
 
 func(sink *TextStructSink) String(value string) {
 	sink.enterElement()
-	sink.builder.WriteString(fmt.Sprintf("%q", value))
+	sink.buffer = strconv.AppendQuote(sink.buffer, value)
 }
 
 func(sink *TextStructSink) Int(value int64) {
 	sink.enterElement()
-	sink.builder.WriteString(fmt.Sprintf("%d", value))
+	sink.buffer = strconv.AppendInt(sink.buffer, value, 10)
 }
 
 func(sink *TextStructSink) Float(value float64) {
 	sink.enterElement()
-	sink.builder.WriteString(fmt.Sprintf("%G", value))
+	sink.buffer = strconv.AppendFloat(sink.buffer, value, 'G', -1, 64)
+}
+
+func(sink *TextStructSink) Time(value time.Time) {
+	sink.enterElement()
+	sink.buffer = value.AppendFormat(sink.buffer, time.RFC3339Nano)
+}
+
+func(sink *TextStructSink) Duration(value time.Duration) {
+	sink.enterElement()
+	sink.buffer = append(sink.buffer, value.String()...)
+}
+
+func(sink *TextStructSink) Bytes(value []byte) {
+	sink.enterElement()
+	sink.buffer = appendHex(append(sink.buffer, "0x"...), value)
+}
+
+func(sink *TextStructSink) Uint(value uint64) {
+	sink.enterElement()
+	sink.buffer = strconv.AppendUint(sink.buffer, value, 10)
+}
+
+func(sink *TextStructSink) Null() {
+	sink.enterElement()
+	sink.buffer = append(sink.buffer, "null"...)
 }
 
 func(sink *TextStructSink) EndList() {
 	sink.stack.Pop()
 	if sink.KeepOutermostParens || !sink.stack.IsEmpty() {
-		sink.builder.WriteRune(']')
+		sink.buffer = append(sink.buffer, ']')
 	}
 }
 
 func(sink *TextStructSink) ToString() string {
-	return sink.builder.String()
+	return string(sink.buffer)
+}
+
+func(sink *TextStructSink) ToBytes() []byte {
+	return sink.buffer
+}
+
+func(sink *TextStructSink) Reset() {
+	sink.buffer = sink.buffer[:0]
+	sink.stack = BoolStack {
+		scalars: sink.stack.scalars[:0],
+	}
+}
+
+func appendHex(dst []byte, value []byte) []byte {
+	for _, b := range value {
+		dst = append(dst, hexDigits[b >> 4], hexDigits[b & 0xF])
+	}
+	return dst
 }
 
 var _ StructSink = &TextStructSink{}
+var _ ExtendedStructMap = &TextStructSink{}
+var _ ExtendedStructList = &TextStructSink{}
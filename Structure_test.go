@@ -0,0 +1,93 @@
+package golog
+
+import (
+	"math"
+	"time"
+	"testing"
+)
+
+type basicStructMap struct {
+	strings map[string]string
+	ints map[string]int64
+}
+
+func newBasicStructMap() *basicStructMap {
+	return &basicStructMap {
+		strings: make(map[string]string),
+		ints: make(map[string]int64),
+	}
+}
+
+func(m *basicStructMap) BoolProperty(name string, value bool) {}
+
+func(m *basicStructMap) StringProperty(name string, value string) {
+	m.strings[name] = value
+}
+
+func(m *basicStructMap) IntProperty(name string, value int64) {
+	m.ints[name] = value
+}
+
+func(m *basicStructMap) FloatProperty(name string, value float64) {}
+
+func(m *basicStructMap) MapProperty(name string) StructMap {
+	return m
+}
+
+func(m *basicStructMap) ListProperty(name string) StructList {
+	return nil
+}
+
+func(m *basicStructMap) EndMap() {}
+
+func TestExtendedPropertiesFallBackOnBasicMaps(t *testing.T) {
+	m := newBasicStructMap()
+	when := time.Date(2024, 5, 6, 7, 8, 9, 10, time.UTC)
+	PutTimeProperty(m, "time", when)
+	PutDurationProperty(m, "duration", 1500 * time.Millisecond)
+	PutBytesProperty(m, "bytes", []byte { 0xDE, 0xAD })
+	PutUintProperty(m, "small", 7)
+	PutUintProperty(m, "huge", math.MaxUint64)
+	PutNullProperty(m, "null")
+	expected := map[string]string {
+		"time": "2024-05-06T07:08:09.00000001Z",
+		"duration": "1.5s",
+		"bytes": "3q0=",
+		"huge": "18446744073709551615",
+		"null": NullText,
+	}
+	for name, want := range expected {
+		if got := m.strings[name]; got != want {
+			t.Errorf("%s: got %q, want %q", name, got, want)
+		}
+	}
+	if m.ints["small"] != 7 {
+		t.Errorf("small uint should fall back to int, got %v", m.ints)
+	}
+}
+
+func TestTextStructSinkRendersExtendedTypes(t *testing.T) {
+	value := MapValue()
+	value.Set("s", StringValue("a\"b"))
+	value.Set("i", IntValue(-3))
+	value.Set("u", UintValue(math.MaxUint64))
+	value.Set("f", FloatValue(2.5))
+	value.Set("inf", FloatValue(math.Inf(1)))
+	value.Set("d", DurationValue(time.Minute))
+	value.Set("b", BytesValue([]byte { 0x01, 0xAB }))
+	value.Set("n", NullValue())
+	value.Set("l", ListValue(BoolValue(true), NullValue(), TimeValue(time.Unix(0, 0).UTC())))
+	sink := &TextStructSink{}
+	value.PutStruct(sink)
+	want := `s: "a\"b", i: -3, u: 18446744073709551615, f: 2.5, inf: +Inf, d: 1m0s, b: 0x01ab, n: null, ` +
+			`l: [true, null, 1970-01-01T00:00:00Z]`
+	if got := sink.ToString(); got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+	sink.Reset()
+	sink.KeepOutermostParens = true
+	ListValue(IntValue(1), MapValue()).PutStruct(sink)
+	if got := sink.ToString(); got != "[1, {}]" {
+		t.Fatalf("reset sink rendered %q", got)
+	}
+}
@@ -1,6 +1,7 @@
 package golog
 
 import (
+	"io"
 	"os"
 	"fmt"
 	"sync"
@@ -10,44 +11,69 @@ type TextLogger struct {
 	ID uintptr
 	WriteInfo func(string)
 	WriteError func(string)
+	InfoWriter io.Writer
+	ErrorWriter io.Writer
 	CloseStream func()
+	OnError func(error)
 	Formatter TextFormatter
+	Plain bool
 	mutex sync.Mutex
+	scratch []byte
+}
+
+func(logger *TextLogger) reportError(err error) {
+	if err != nil && logger.OnError != nil {
+		logger.OnError(err)
+	}
+}
+
+func(logger *TextLogger) output(nominal bool) (io.Writer, func(string)) {
+	hasInfo := logger.InfoWriter != nil || logger.WriteInfo != nil
+	hasError := logger.ErrorWriter != nil || logger.WriteError != nil
+	if nominal && hasInfo || !nominal && !hasError {
+		return logger.InfoWriter, logger.WriteInfo
+	}
+	return logger.ErrorWriter, logger.WriteError
 }
 
 func(logger *TextLogger) Log(packet *Packet) {
 	if packet == nil || packet.Message == nil {
 		return
 	}
+	stream, write := logger.output(packet.Level == nil || packet.Level.IsNominal())
+	if stream != nil {
+		logger.mutex.Lock()
+		logger.scratch = AppendPacketText(logger.scratch[:0], packet, logger.Formatter)
+		text := logger.scratch
+		if logger.Plain {
+			text = StripStyleBytes(text)
+		}
+		var err error
+		if len(text) > 0 {
+			_, err = stream.Write(text)
+		}
+		if cap(logger.scratch) > maxPooledBufferSize {
+			logger.scratch = nil
+		}
+		logger.mutex.Unlock()
+		logger.reportError(err)
+		return
+	}
+	if write == nil {
+		return
+	}
 	var lines []string
 	if logger.Formatter == nil {
 		lines = packet.Message.Lines()
 	} else {
 		lines = logger.Formatter.PacketToText(packet)
 	}
-	if len(lines) == 0 {
-		return
-	}
-	var writer func(string)
-	if packet.Level == nil || packet.Level.IsNominal() {
-		if logger.WriteInfo != nil {
-			writer = logger.WriteInfo
-		} else {
-			writer = logger.WriteError
-		}
-	} else {
-		if logger.WriteError != nil {
-			writer = logger.WriteError
-		} else {
-			writer = logger.WriteInfo
-		}
-	}
-	if writer == nil {
-		return
-	}
 	logger.mutex.Lock()
 	for _, line := range lines {
-		writer(line)
+		if logger.Plain {
+			line = StripStyles(line)
+		}
+		write(line)
 	}
 	logger.mutex.Unlock()
 }
@@ -67,21 +93,25 @@ func(logger *TextLogger) Identity() uintptr {
 	return logger.ID
 }
 
+func TextWriterLogger(writer io.Writer, formatter TextFormatter) *TextLogger {
+	return &TextLogger {
+		ID: NewLoggerID(),
+		InfoWriter: writer,
+		Formatter: formatter,
+		Plain: plainFallback(writer),
+	}
+}
+
 func TextFileLogger(path string, formatter TextFormatter) (*TextLogger, error) {
 	f, err := os.OpenFile(path, os.O_WRONLY | os.O_CREATE | os.O_APPEND, 0644)
 	if err != nil {
 		return nil, err
 	}
-	return &TextLogger {
-		ID: NewLoggerID(),
-		WriteInfo: func(line string) {
-			f.WriteString(line)
-		},
-		CloseStream: func() {
-			f.Close()
-		},
-		Formatter: formatter,
-	}, nil
+	logger := TextWriterLogger(f, formatter)
+	logger.CloseStream = func() {
+		logger.reportError(f.Close())
+	}
+	return logger, nil
 }
 
 func WriteLineToStdout(line string) {
@@ -94,6 +124,7 @@ func WriteLineToStderr(line string) {
 
 var DumbLogger Logger = &TextLogger {
 	ID: NewLoggerID(),
-	WriteInfo: WriteLineToStdout,
-	WriteError: WriteLineToStderr,
+	InfoWriter: os.Stdout,
+	ErrorWriter: os.Stderr,
+	Plain: plainFallback(os.Stdout) || plainFallback(os.Stderr),
 }
@@ -0,0 +1,83 @@
+package golog
+
+import (
+	"os"
+	"testing"
+	"path/filepath"
+)
+
+func TestTextWriterLoggerWritesWholePackets(t *testing.T) {
+	writer := &recordingWriter{}
+	logger := TextWriterLogger(writer, nil)
+	logger.Plain = true
+	logger.Log(levelPacket(INFO, "\x1b[1mone\x1b[0m", "two"))
+	logger.Log(levelPacket(ERROR, "three"))
+	logger.Log(levelPacket(INFO))
+	logger.Log(nil)
+	if got := writer.String(); got != "one\ntwo\nthree\n" {
+		t.Fatalf("wrote %q", got)
+	}
+	if writer.writes != 2 {
+		t.Fatalf("%d writes for 2 packets", writer.writes)
+	}
+}
+
+func TestTextLoggerRoutesByLevel(t *testing.T) {
+	info, errors := &recordingWriter{}, &recordingWriter{}
+	logger := &TextLogger {
+		ID: NewLoggerID(),
+		InfoWriter: info,
+		ErrorWriter: errors,
+	}
+	logger.Log(levelPacket(INFO, "fine"))
+	logger.Log(levelPacket(WARNING, "careful"))
+	logger.Log(levelPacket(nil, "unleveled"))
+	if info.String() != "fine\nunleveled\n" || errors.String() != "careful\n" {
+		t.Fatalf("info %q, errors %q", info.String(), errors.String())
+	}
+	var lines []string
+	callbacks := &TextLogger {
+		ID: NewLoggerID(),
+		WriteError: func(line string) {
+			lines = append(lines, line)
+		},
+	}
+	callbacks.Log(levelPacket(INFO, "fallback"))
+	if len(lines) != 1 || lines[0] != "fallback" {
+		t.Fatalf("callback lines %q", lines)
+	}
+}
+
+func TestTextLoggerReportsWriteErrors(t *testing.T) {
+	logger := TextWriterLogger(failingWriter{}, nil)
+	var errs []error
+	logger.OnError = func(err error) {
+		errs = append(errs, err)
+	}
+	logger.Log(levelPacket(ERROR, "lost"))
+	logger.Log(levelPacket(INFO))
+	if len(errs) != 1 || errs[0] != errFailingWriter {
+		t.Fatalf("errors %v", errs)
+	}
+	TextWriterLogger(failingWriter{}, nil).Log(levelPacket(INFO, "unreported"))
+}
+
+func TestTextFileLoggerAppendsLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	for _, line := range []string { "first", "second" } {
+		logger, err := TextFileLogger(path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.Log(levelPacket(INFO, line, line + " again"))
+		logger.Close()
+		logger.Close()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "first\nfirst again\nsecond\nsecond again\n" {
+		t.Fatalf("file holds %q", data)
+	}
+}
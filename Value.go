@@ -0,0 +1,737 @@
+package golog
+
+import (
+	"sort"
+	"time"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type ValueKind uint
+
+const (
+	VAL_NULL ValueKind = iota
+	VAL_BOOL
+	VAL_STRING
+	VAL_INT
+	VAL_UINT
+	VAL_FLOAT
+	VAL_TIME
+	VAL_DURATION
+	VAL_BYTES
+	VAL_MAP
+	VAL_LIST
+)
+
+type ValueField struct {
+	Name string
+	Value *Value
+}
+
+type Value struct {
+	Kind ValueKind
+	Bool bool
+	String string
+	Int int64
+	Uint uint64
+	Float float64
+	Time time.Time
+	Duration time.Duration
+	Bytes []byte
+	Fields []ValueField
+	Items []*Value
+}
+
+func NullValue() *Value {
+	return &Value {
+		Kind: VAL_NULL,
+	}
+}
+
+func BoolValue(value bool) *Value {
+	return &Value {
+		Kind: VAL_BOOL,
+		Bool: value,
+	}
+}
+
+func StringValue(value string) *Value {
+	return &Value {
+		Kind: VAL_STRING,
+		String: value,
+	}
+}
+
+func IntValue(value int64) *Value {
+	return &Value {
+		Kind: VAL_INT,
+		Int: value,
+	}
+}
+
+func UintValue(value uint64) *Value {
+	return &Value {
+		Kind: VAL_UINT,
+		Uint: value,
+	}
+}
+
+func FloatValue(value float64) *Value {
+	return &Value {
+		Kind: VAL_FLOAT,
+		Float: value,
+	}
+}
+
+func TimeValue(value time.Time) *Value {
+	return &Value {
+		Kind: VAL_TIME,
+		Time: value,
+	}
+}
+
+func DurationValue(value time.Duration) *Value {
+	return &Value {
+		Kind: VAL_DURATION,
+		Duration: value,
+	}
+}
+
+func BytesValue(value []byte) *Value {
+	return &Value {
+		Kind: VAL_BYTES,
+		Bytes: value,
+	}
+}
+
+func MapValue() *Value {
+	return &Value {
+		Kind: VAL_MAP,
+	}
+}
+
+func ListValue(items ...*Value) *Value {
+	return &Value {
+		Kind: VAL_LIST,
+		Items: items,
+	}
+}
+
+func(value *Value) Field(name string) *Value {
+	if value == nil || value.Kind != VAL_MAP {
+		return nil
+	}
+	for index := range value.Fields {
+		if value.Fields[index].Name == name {
+			return value.Fields[index].Value
+		}
+	}
+	return nil
+}
+
+func(value *Value) Set(name string, child *Value) {
+	if value.Kind != VAL_MAP {
+		return
+	}
+	for index := range value.Fields {
+		if value.Fields[index].Name == name {
+			value.Fields[index].Value = child
+			return
+		}
+	}
+	value.Fields = append(value.Fields, ValueField {
+		Name: name,
+		Value: child,
+	})
+}
+
+func(value *Value) Remove(name string) bool {
+	if value == nil || value.Kind != VAL_MAP {
+		return false
+	}
+	for index := range value.Fields {
+		if value.Fields[index].Name == name {
+			value.Fields = append(value.Fields[:index], value.Fields[index + 1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func(value *Value) SetPath(path []string, child *Value) {
+	current := value
+	for index, name := range path {
+		if index == len(path) - 1 {
+			current.Set(name, child)
+			return
+		}
+		next := current.Field(name)
+		if next == nil || next.Kind != VAL_MAP {
+			next = MapValue()
+			current.Set(name, next)
+		}
+		current = next
+	}
+}
+
+func(value *Value) Append(item *Value) {
+	if value.Kind == VAL_LIST {
+		value.Items = append(value.Items, item)
+	}
+}
+
+func(value *Value) PutStruct(sink StructSink) {
+	if value == nil {
+		return
+	}
+	switch value.Kind {
+		case VAL_MAP:
+			m := sink.Map()
+			value.putFields(m)
+			m.EndMap()
+		case VAL_LIST:
+			l := sink.List()
+			value.putItems(l)
+			l.EndList()
+		default:
+			m := sink.Map()
+			value.PutProperty(m, ScalarStructKey)
+			m.EndMap()
+	}
+}
+
+func(value *Value) putFields(m StructMap) {
+	for _, field := range value.Fields {
+		field.Value.PutProperty(m, field.Name)
+	}
+}
+
+func(value *Value) putItems(l StructList) {
+	for _, item := range value.Items {
+		item.PutElement(l)
+	}
+}
+
+func(value *Value) PutProperty(m StructMap, name string) {
+	if value == nil {
+		PutNullProperty(m, name)
+		return
+	}
+	switch value.Kind {
+		case VAL_BOOL:
+			m.BoolProperty(name, value.Bool)
+		case VAL_STRING:
+			m.StringProperty(name, value.String)
+		case VAL_INT:
+			m.IntProperty(name, value.Int)
+		case VAL_UINT:
+			PutUintProperty(m, name, value.Uint)
+		case VAL_FLOAT:
+			m.FloatProperty(name, value.Float)
+		case VAL_TIME:
+			PutTimeProperty(m, name, value.Time)
+		case VAL_DURATION:
+			PutDurationProperty(m, name, value.Duration)
+		case VAL_BYTES:
+			PutBytesProperty(m, name, value.Bytes)
+		case VAL_MAP:
+			child := m.MapProperty(name)
+			value.putFields(child)
+			child.EndMap()
+		case VAL_LIST:
+			child := m.ListProperty(name)
+			value.putItems(child)
+			child.EndList()
+		default:
+			PutNullProperty(m, name)
+	}
+}
+
+func(value *Value) PutElement(l StructList) {
+	if value == nil {
+		PutNull(l)
+		return
+	}
+	switch value.Kind {
+		case VAL_BOOL:
+			l.Bool(value.Bool)
+		case VAL_STRING:
+			l.String(value.String)
+		case VAL_INT:
+			l.Int(value.Int)
+		case VAL_UINT:
+			PutUint(l, value.Uint)
+		case VAL_FLOAT:
+			l.Float(value.Float)
+		case VAL_TIME:
+			PutTime(l, value.Time)
+		case VAL_DURATION:
+			PutDuration(l, value.Duration)
+		case VAL_BYTES:
+			PutBytes(l, value.Bytes)
+		case VAL_MAP:
+			child := l.Map()
+			value.putFields(child)
+			child.EndMap()
+		case VAL_LIST:
+			child := l.List()
+			value.putItems(child)
+			child.EndList()
+		default:
+			PutNull(l)
+	}
+}
+
+func(value *Value) Clone() *Value {
+	if value == nil {
+		return nil
+	}
+	clone := *value
+	if value.Bytes != nil {
+		clone.Bytes = append([]byte(nil), value.Bytes...)
+	}
+	if value.Fields != nil {
+		clone.Fields = make([]ValueField, len(value.Fields))
+		for index, field := range value.Fields {
+			clone.Fields[index] = ValueField {
+				Name: field.Name,
+				Value: field.Value.Clone(),
+			}
+		}
+	}
+	if value.Items != nil {
+		clone.Items = make([]*Value, len(value.Items))
+		for index, item := range value.Items {
+			clone.Items[index] = item.Clone()
+		}
+	}
+	return &clone
+}
+
+func(value *Value) Equal(other *Value) bool {
+	if value == nil || other == nil {
+		return (value == nil || value.Kind == VAL_NULL) && (other == nil || other.Kind == VAL_NULL)
+	}
+	if value.Kind != other.Kind {
+		return false
+	}
+	switch value.Kind {
+		case VAL_NULL:
+			return true
+		case VAL_BOOL:
+			return value.Bool == other.Bool
+		case VAL_STRING:
+			return value.String == other.String
+		case VAL_INT:
+			return value.Int == other.Int
+		case VAL_UINT:
+			return value.Uint == other.Uint
+		case VAL_FLOAT:
+			return value.Float == other.Float
+		case VAL_TIME:
+			return value.Time.Equal(other.Time)
+		case VAL_DURATION:
+			return value.Duration == other.Duration
+		case VAL_BYTES:
+			return string(value.Bytes) == string(other.Bytes)
+		case VAL_MAP:
+			if len(value.Fields) != len(other.Fields) {
+				return false
+			}
+			for _, field := range value.Fields {
+				if !other.hasField(field.Name) || !field.Value.Equal(other.Field(field.Name)) {
+					return false
+				}
+			}
+			return true
+		case VAL_LIST:
+			if len(value.Items) != len(other.Items) {
+				return false
+			}
+			for index, item := range value.Items {
+				if !item.Equal(other.Items[index]) {
+					return false
+				}
+			}
+			return true
+		default:
+			return false
+	}
+}
+
+func(value *Value) hasField(name string) bool {
+	for index := range value.Fields {
+		if value.Fields[index].Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func(value *Value) Lookup(path string) *Value {
+	if len(path) == 0 {
+		return value
+	}
+	return value.LookupPath(strings.Split(path, "."))
+}
+
+func(value *Value) LookupPath(path []string) *Value {
+	current := value
+	for _, name := range path {
+		if current == nil {
+			return nil
+		}
+		switch current.Kind {
+			case VAL_MAP:
+				current = current.Field(name)
+			case VAL_LIST:
+				index, err := strconv.Atoi(name)
+				if err != nil || index < 0 || index >= len(current.Items) {
+					return nil
+				}
+				current = current.Items[index]
+			default:
+				return nil
+		}
+	}
+	return current
+}
+
+func(value *Value) Merge(other *Value) {
+	if value == nil || other == nil || value.Kind != VAL_MAP || other.Kind != VAL_MAP {
+		return
+	}
+	for _, field := range other.Fields {
+		existing := value.Field(field.Name)
+		if existing != nil && existing.Kind == VAL_MAP && field.Value != nil && field.Value.Kind == VAL_MAP {
+			existing.Merge(field.Value)
+		} else {
+			value.Set(field.Name, field.Value.Clone())
+		}
+	}
+}
+
+func(value *Value) ToAny() any {
+	if value == nil {
+		return nil
+	}
+	switch value.Kind {
+		case VAL_BOOL:
+			return value.Bool
+		case VAL_STRING:
+			return value.String
+		case VAL_INT:
+			return value.Int
+		case VAL_UINT:
+			return value.Uint
+		case VAL_FLOAT:
+			return value.Float
+		case VAL_TIME:
+			return value.Time
+		case VAL_DURATION:
+			return value.Duration
+		case VAL_BYTES:
+			return value.Bytes
+		case VAL_MAP:
+			return value.ToMap()
+		case VAL_LIST:
+			items := make([]any, len(value.Items))
+			for index, item := range value.Items {
+				items[index] = item.ToAny()
+			}
+			return items
+		default:
+			return nil
+	}
+}
+
+func(value *Value) ToMap() map[string]any {
+	if value == nil || value.Kind != VAL_MAP {
+		return nil
+	}
+	m := make(map[string]any, len(value.Fields))
+	for _, field := range value.Fields {
+		m[field.Name] = field.Value.ToAny()
+	}
+	return m
+}
+
+var unlimitedStructEncoder *StructEncoder = &StructEncoder{}
+
+func FromAny(native any) *Value {
+	return fromAny(native, nil)
+}
+
+func fromAny(native any, active map[uintptr]bool) *Value {
+	switch v := native.(type) {
+		case nil:
+			return NullValue()
+		case *Value:
+			if v == nil {
+				return NullValue()
+			}
+			return v.Clone()
+		case bool:
+			return BoolValue(v)
+		case string:
+			return StringValue(v)
+		case int:
+			return IntValue(int64(v))
+		case int8:
+			return IntValue(int64(v))
+		case int16:
+			return IntValue(int64(v))
+		case int32:
+			return IntValue(int64(v))
+		case int64:
+			return IntValue(v)
+		case uint:
+			return UintValue(uint64(v))
+		case uint8:
+			return UintValue(uint64(v))
+		case uint16:
+			return UintValue(uint64(v))
+		case uint32:
+			return UintValue(uint64(v))
+		case uint64:
+			return UintValue(v)
+		case float32:
+			return FloatValue(float64(v))
+		case float64:
+			return FloatValue(v)
+		case time.Time:
+			return TimeValue(v)
+		case time.Duration:
+			return DurationValue(v)
+		case []byte:
+			return BytesValue(append([]byte(nil), v...))
+		case map[string]any:
+			if v == nil {
+				return NullValue()
+			}
+			identity := reflect.ValueOf(v).Pointer()
+			if active[identity] {
+				return StringValue(CycleText)
+			}
+			active = enterActive(active, identity)
+			defer delete(active, identity)
+			names := make([]string, 0, len(v))
+			for name := range v {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			result := &Value {
+				Kind: VAL_MAP,
+				Fields: make([]ValueField, len(names)),
+			}
+			for index, name := range names {
+				result.Fields[index] = ValueField {
+					Name: name,
+					Value: fromAny(v[name], active),
+				}
+			}
+			return result
+		case []any:
+			if v == nil {
+				return NullValue()
+			}
+			var identity uintptr
+			if len(v) > 0 {
+				identity = reflect.ValueOf(v).Pointer()
+				if active[identity] {
+					return StringValue(CycleText)
+				}
+				active = enterActive(active, identity)
+				defer delete(active, identity)
+			}
+			result := &Value {
+				Kind: VAL_LIST,
+				Items: make([]*Value, len(v)),
+			}
+			for index, item := range v {
+				result.Items[index] = fromAny(item, active)
+			}
+			return result
+		case Structure:
+			if value := CaptureValue(v); value != nil {
+				return value
+			}
+			return NullValue()
+		default:
+			holder := MapValue()
+			unlimitedStructEncoder.PutProperty(&captureMap {
+				value: holder,
+			}, ScalarStructKey, v)
+			if value := holder.Field(ScalarStructKey); value != nil {
+				return value
+			}
+			return NullValue()
+	}
+}
+
+func enterActive(active map[uintptr]bool, identity uintptr) map[uintptr]bool {
+	if active == nil {
+		active = make(map[uintptr]bool)
+	}
+	active[identity] = true
+	return active
+}
+
+func FromMap(m map[string]any) *Value {
+	if m == nil {
+		return MapValue()
+	}
+	return FromAny(m)
+}
+
+type CaptureSink struct {
+	Root *Value
+}
+
+func CaptureValue(structure Structure) *Value {
+	if structure == nil {
+		return nil
+	}
+	if value, ok := structure.(*Value); ok {
+		return value
+	}
+	sink := &CaptureSink{}
+	structure.PutStruct(sink)
+	return sink.Root
+}
+
+func(sink *CaptureSink) Map() StructMap {
+	sink.Root = MapValue()
+	return &captureMap {
+		value: sink.Root,
+	}
+}
+
+func(sink *CaptureSink) List() StructList {
+	sink.Root = ListValue()
+	return &captureList {
+		value: sink.Root,
+	}
+}
+
+type captureMap struct {
+	value *Value
+}
+
+func(m *captureMap) BoolProperty(name string, value bool) {
+	m.value.Set(name, BoolValue(value))
+}
+
+func(m *captureMap) StringProperty(name string, value string) {
+	m.value.Set(name, StringValue(value))
+}
+
+func(m *captureMap) IntProperty(name string, value int64) {
+	m.value.Set(name, IntValue(value))
+}
+
+func(m *captureMap) FloatProperty(name string, value float64) {
+	m.value.Set(name, FloatValue(value))
+}
+
+func(m *captureMap) TimeProperty(name string, value time.Time) {
+	m.value.Set(name, TimeValue(value))
+}
+
+func(m *captureMap) DurationProperty(name string, value time.Duration) {
+	m.value.Set(name, DurationValue(value))
+}
+
+func(m *captureMap) BytesProperty(name string, value []byte) {
+	m.value.Set(name, BytesValue(append([]byte(nil), value...)))
+}
+
+func(m *captureMap) UintProperty(name string, value uint64) {
+	m.value.Set(name, UintValue(value))
+}
+
+func(m *captureMap) NullProperty(name string) {
+	m.value.Set(name, NullValue())
+}
+
+func(m *captureMap) MapProperty(name string) StructMap {
+	child := MapValue()
+	m.value.Set(name, child)
+	return &captureMap {
+		value: child,
+	}
+}
+
+func(m *captureMap) ListProperty(name string) StructList {
+	child := ListValue()
+	m.value.Set(name, child)
+	return &captureList {
+		value: child,
+	}
+}
+
+func(m *captureMap) EndMap() {}
+
+type captureList struct {
+	value *Value
+}
+
+func(l *captureList) Map() StructMap {
+	child := MapValue()
+	l.value.Append(child)
+	return &captureMap {
+		value: child,
+	}
+}
+
+func(l *captureList) List() StructList {
+	child := ListValue()
+	l.value.Append(child)
+	return &captureList {
+		value: child,
+	}
+}
+
+func(l *captureList) Bool(value bool) {
+	l.value.Append(BoolValue(value))
+}
+
+func(l *captureList) String(value string) {
+	l.value.Append(StringValue(value))
+}
+
+func(l *captureList) Int(value int64) {
+	l.value.Append(IntValue(value))
+}
+
+func(l *captureList) Float(value float64) {
+	l.value.Append(FloatValue(value))
+}
+
+func(l *captureList) Time(value time.Time) {
+	l.value.Append(TimeValue(value))
+}
+
+func(l *captureList) Duration(value time.Duration) {
+	l.value.Append(DurationValue(value))
+}
+
+func(l *captureList) Bytes(value []byte) {
+	l.value.Append(BytesValue(append([]byte(nil), value...)))
+}
+
+func(l *captureList) Uint(value uint64) {
+	l.value.Append(UintValue(value))
+}
+
+func(l *captureList) Null() {
+	l.value.Append(NullValue())
+}
+
+func(l *captureList) EndList() {}
+
+var _ Structure = &Value{}
+var _ StructSink = &CaptureSink{}
+var _ ExtendedStructMap = &captureMap{}
+var _ ExtendedStructList = &captureList{}
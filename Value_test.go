@@ -0,0 +1,179 @@
+package golog
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestFromAnyDoesNotTruncate(t *testing.T) {
+	wide := make(map[string]any)
+	for i := 0; i < 300; i++ {
+		wide["k" + strconv.Itoa(i)] = i
+	}
+	value := FromMap(wide)
+	if len(value.Fields) != 300 {
+		t.Fatalf("got %d fields, want 300", len(value.Fields))
+	}
+	if field := value.Field("k299"); field == nil || field.Int != 299 {
+		t.Errorf("k299: %v", field)
+	}
+	var deep any = "bottom"
+	for i := 0; i < 40; i++ {
+		deep = []any { deep }
+	}
+	current := FromAny(deep)
+	for i := 0; i < 40; i++ {
+		if current.Kind != VAL_LIST || len(current.Items) != 1 {
+			t.Fatalf("level %d: %#v", i, current.ToAny())
+		}
+		current = current.Items[0]
+	}
+	if current.Kind != VAL_STRING || current.String != "bottom" {
+		t.Fatalf("bottom: %#v", current.ToAny())
+	}
+	long := make([]int, 1000)
+	if list := FromAny(long); len(list.Items) != 1000 {
+		t.Fatalf("typed slice truncated to %d", len(list.Items))
+	}
+}
+
+func TestFromAnyKinds(t *testing.T) {
+	stamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	value := FromMap(map[string]any {
+		"b": true,
+		"i": int8(-3),
+		"u": uint64(1 << 63),
+		"f": float32(1.5),
+		"t": stamp,
+		"d": time.Second,
+		"raw": []byte { 1, 2 },
+		"nil": nil,
+		"nested": map[string]any {
+			"list": []any { "x", 2 },
+		},
+		"value": StringValue("kept"),
+	})
+	if value.Fields[0].Name != "b" || value.Fields[len(value.Fields) - 1].Name != "value" {
+		t.Errorf("fields not sorted: %v", value.Fields)
+	}
+	checks := map[string]ValueKind {
+		"b": VAL_BOOL,
+		"i": VAL_INT,
+		"u": VAL_UINT,
+		"f": VAL_FLOAT,
+		"t": VAL_TIME,
+		"d": VAL_DURATION,
+		"raw": VAL_BYTES,
+		"nil": VAL_NULL,
+		"nested": VAL_MAP,
+		"value": VAL_STRING,
+	}
+	for name, kind := range checks {
+		if field := value.Field(name); field == nil || field.Kind != kind {
+			t.Errorf("%s: got %v, want kind %d", name, field, kind)
+		}
+	}
+	if value.Field("u").Uint != 1 << 63 || !value.Field("t").Time.Equal(stamp) {
+		t.Errorf("scalars: %v", value.ToAny())
+	}
+	if item := value.LookupPath([]string { "nested", "list" }); item == nil || len(item.Items) != 2 || item.Items[1].Int != 2 {
+		t.Errorf("nested list: %v", item)
+	}
+	if FromMap(nil).Kind != VAL_MAP {
+		t.Error("nil map is not an empty map")
+	}
+}
+
+func TestFromAnyCopiesInput(t *testing.T) {
+	source := StringValue("a")
+	raw := []byte { 1 }
+	value := FromAny(map[string]any {
+		"v": source,
+		"raw": raw,
+	})
+	source.String = "b"
+	raw[0] = 9
+	if value.Field("v").String != "a" || value.Field("raw").Bytes[0] != 1 {
+		t.Fatalf("converted value aliases its input: %v", value.ToAny())
+	}
+}
+
+func TestFromAnyCycles(t *testing.T) {
+	m := map[string]any {
+		"name": "root",
+	}
+	m["self"] = m
+	value := FromAny(m)
+	if self := value.Field("self"); self == nil || self.Kind != VAL_STRING || self.String != CycleText {
+		t.Fatalf("self: %v", self)
+	}
+	l := []any { 1, nil }
+	l[1] = l
+	if list := FromAny(l); list.Items[1].String != CycleText {
+		t.Fatalf("list: %v", list.ToAny())
+	}
+	shared := map[string]any {
+		"x": 1,
+	}
+	twice := FromAny([]any { shared, shared })
+	if twice.Items[0].Kind != VAL_MAP || twice.Items[1].Kind != VAL_MAP {
+		t.Fatalf("shared non-cyclic map reported as cycle: %v", twice.ToAny())
+	}
+}
+
+func TestFromAnyStructs(t *testing.T) {
+	type item struct {
+		Name string
+		Tags []string
+	}
+	tags := make([]string, 500)
+	value := FromAny(&item {
+		Name: "widget",
+		Tags: tags,
+	})
+	if value.Field("Name").String != "widget" || len(value.Field("Tags").Items) != 500 {
+		t.Fatalf("struct: %v", value.Field("Name"))
+	}
+}
+
+func TestValueCloneIsDeep(t *testing.T) {
+	original := MapValue()
+	original.Set("list", ListValue(StringValue("a")))
+	original.Set("raw", BytesValue([]byte { 1 }))
+	clone := original.Clone()
+	clone.Field("list").Items[0].String = "b"
+	clone.Field("raw").Bytes[0] = 2
+	clone.Set("extra", NullValue())
+	if !original.Equal(original.Clone()) || original.Equal(clone) {
+		t.Fatal("clone equality")
+	}
+	if original.Field("list").Items[0].String != "a" || original.Field("raw").Bytes[0] != 1 || original.Field("extra") != nil {
+		t.Fatalf("clone shares state: %v", original.ToAny())
+	}
+}
+
+func TestValueMergeAndPaths(t *testing.T) {
+	base := FromMap(map[string]any {
+		"db": map[string]any {
+			"host": "a",
+			"port": 1,
+		},
+	})
+	base.Merge(FromMap(map[string]any {
+		"db": map[string]any {
+			"port": 2,
+		},
+		"user": "bob",
+	}))
+	if base.Lookup("db.host").String != "a" || base.Lookup("db.port").Int != 2 || base.Field("user").String != "bob" {
+		t.Fatalf("merge: %v", base.ToAny())
+	}
+	base.SetPath([]string { "a", "b" }, IntValue(3))
+	if base.LookupPath([]string { "a", "b" }).Int != 3 {
+		t.Fatalf("SetPath: %v", base.ToAny())
+	}
+	if !base.Remove("user") || base.Remove("user") {
+		t.Fatal("Remove")
+	}
+}
@@ -0,0 +1,547 @@
+package golog
+
+import (
+	"math"
+	"time"
+	"strconv"
+	"unicode/utf8"
+	"encoding/binary"
+)
+
+const (
+	cborUnsigned byte = 0 << 5
+	cborNegative byte = 1 << 5
+	cborBytes byte = 2 << 5
+	cborText byte = 3 << 5
+	cborArray byte = 4 << 5
+	cborMap byte = 5 << 5
+	cborTag byte = 6 << 5
+	cborSimple byte = 7 << 5
+)
+
+const (
+	cborFalse byte = cborSimple | 20
+	cborTrue byte = cborSimple | 21
+	cborNull byte = cborSimple | 22
+	cborFloat32 byte = cborSimple | 26
+	cborFloat64 byte = cborSimple | 27
+	cborBreak byte = cborSimple | 31
+	cborIndefinite byte = 31
+)
+
+const (
+	CBOR_TAG_DATE_TIME uint64 = 0
+	CBOR_TAG_EPOCH_TIME uint64 = 1
+	CBOR_TAG_EXTENDED_TIME uint64 = 1001
+	CBOR_TAG_DURATION uint64 = 1002
+)
+
+const (
+	cborSecondsKey int64 = 1
+	cborNanosecondsKey int64 = -9
+)
+
+const CBORMaxDepth = 512
+
+type CBORStructSink struct {
+	buffer []byte
+}
+
+func(sink *CBORStructSink) head(major byte, argument uint64) {
+	switch {
+		case argument < 24:
+			sink.buffer = append(sink.buffer, major | byte(argument))
+		case argument <= math.MaxUint8:
+			sink.buffer = append(sink.buffer, major | 24, byte(argument))
+		case argument <= math.MaxUint16:
+			sink.buffer = append(sink.buffer, major | 25)
+			sink.buffer = binary.BigEndian.AppendUint16(sink.buffer, uint16(argument))
+		case argument <= math.MaxUint32:
+			sink.buffer = append(sink.buffer, major | 26)
+			sink.buffer = binary.BigEndian.AppendUint32(sink.buffer, uint32(argument))
+		default:
+			sink.buffer = append(sink.buffer, major | 27)
+			sink.buffer = binary.BigEndian.AppendUint64(sink.buffer, argument)
+	}
+}
+
+func(sink *CBORStructSink) text(value string) {
+	sink.head(cborText, uint64(len(value)))
+	sink.buffer = append(sink.buffer, value...)
+}
+
+func(sink *CBORStructSink) integer(value int64) {
+	if value >= 0 {
+		sink.head(cborUnsigned, uint64(value))
+	} else {
+		sink.head(cborNegative, uint64(-1 - value))
+	}
+}
+
+func(sink *CBORStructSink) float(value float64) {
+	if narrow := float32(value); float64(narrow) == value || math.IsNaN(value) {
+		sink.buffer = append(sink.buffer, cborFloat32)
+		sink.buffer = binary.BigEndian.AppendUint32(sink.buffer, math.Float32bits(narrow))
+	} else {
+		sink.buffer = append(sink.buffer, cborFloat64)
+		sink.buffer = binary.BigEndian.AppendUint64(sink.buffer, math.Float64bits(value))
+	}
+}
+
+func(sink *CBORStructSink) time(value time.Time) {
+	seconds := value.Unix()
+	nanos := value.Nanosecond()
+	if nanos == 0 {
+		sink.head(cborTag, CBOR_TAG_EPOCH_TIME)
+		sink.integer(seconds)
+		return
+	}
+	sink.head(cborTag, CBOR_TAG_EXTENDED_TIME)
+	sink.head(cborMap, 2)
+	sink.integer(cborSecondsKey)
+	sink.integer(seconds)
+	sink.integer(cborNanosecondsKey)
+	sink.integer(int64(nanos))
+}
+
+func(sink *CBORStructSink) duration(value time.Duration) {
+	sink.head(cborTag, CBOR_TAG_DURATION)
+	sink.head(cborMap, 2)
+	sink.integer(cborSecondsKey)
+	sink.integer(int64(value / time.Second))
+	sink.integer(cborNanosecondsKey)
+	sink.integer(int64(value % time.Second))
+}
+
+func(sink *CBORStructSink) Map() StructMap {
+	sink.buffer = append(sink.buffer, cborMap | cborIndefinite)
+	return sink
+}
+
+func(sink *CBORStructSink) List() StructList {
+	sink.buffer = append(sink.buffer, cborArray | cborIndefinite)
+	return sink
+}
+
+func(sink *CBORStructSink) BoolProperty(name string, value bool) {
+	sink.text(name)
+	sink.Bool(value)
+}
+
+func(sink *CBORStructSink) StringProperty(name string, value string) {
+	sink.text(name)
+	sink.text(value)
+}
+
+func(sink *CBORStructSink) IntProperty(name string, value int64) {
+	sink.text(name)
+	sink.integer(value)
+}
+
+func(sink *CBORStructSink) FloatProperty(name string, value float64) {
+	sink.text(name)
+	sink.float(value)
+}
+
+func(sink *CBORStructSink) TimeProperty(name string, value time.Time) {
+	sink.text(name)
+	sink.time(value)
+}
+
+func(sink *CBORStructSink) DurationProperty(name string, value time.Duration) {
+	sink.text(name)
+	sink.duration(value)
+}
+
+func(sink *CBORStructSink) BytesProperty(name string, value []byte) {
+	sink.text(name)
+	sink.Bytes(value)
+}
+
+func(sink *CBORStructSink) UintProperty(name string, value uint64) {
+	sink.text(name)
+	sink.head(cborUnsigned, value)
+}
+
+func(sink *CBORStructSink) NullProperty(name string) {
+	sink.text(name)
+	sink.buffer = append(sink.buffer, cborNull)
+}
+
+func(sink *CBORStructSink) MapProperty(name string) StructMap {
+	sink.text(name)
+	return sink.Map()
+}
+
+func(sink *CBORStructSink) ListProperty(name string) StructList {
+	sink.text(name)
+	return sink.List()
+}
+
+func(sink *CBORStructSink) EndMap() {
+	sink.buffer = append(sink.buffer, cborBreak)
+}
+
+func(sink *CBORStructSink) Bool(value bool) {
+	if value {
+		sink.buffer = append(sink.buffer, cborTrue)
+	} else {
+		sink.buffer = append(sink.buffer, cborFalse)
+	}
+}
+
+func(sink *CBORStructSink) String(value string) {
+	sink.text(value)
+}
+
+func(sink *CBORStructSink) Int(value int64) {
+	sink.integer(value)
+}
+
+func(sink *CBORStructSink) Float(value float64) {
+	sink.float(value)
+}
+
+func(sink *CBORStructSink) Time(value time.Time) {
+	sink.time(value)
+}
+
+func(sink *CBORStructSink) Duration(value time.Duration) {
+	sink.duration(value)
+}
+
+func(sink *CBORStructSink) Bytes(value []byte) {
+	sink.head(cborBytes, uint64(len(value)))
+	sink.buffer = append(sink.buffer, value...)
+}
+
+func(sink *CBORStructSink) Uint(value uint64) {
+	sink.head(cborUnsigned, value)
+}
+
+func(sink *CBORStructSink) Null() {
+	sink.buffer = append(sink.buffer, cborNull)
+}
+
+func(sink *CBORStructSink) EndList() {
+	sink.buffer = append(sink.buffer, cborBreak)
+}
+
+func(sink *CBORStructSink) ToBytes() []byte {
+	return sink.buffer
+}
+
+func(sink *CBORStructSink) Reset() {
+	sink.buffer = sink.buffer[:0]
+}
+
+func EncodeCBOR(structure Structure) []byte {
+	sink := &CBORStructSink{}
+	if structure != nil {
+		structure.PutStruct(sink)
+	}
+	return sink.ToBytes()
+}
+
+type CBORError struct {
+	Offset int
+	Reason string
+}
+
+func(err *CBORError) Error() string {
+	return "invalid CBOR at offset " + strconv.Itoa(err.Offset) + ": " + err.Reason
+}
+
+type cborDecoder struct {
+	data []byte
+	offset int
+	depth int
+}
+
+func DecodeCBOR(data []byte) (*Value, error) {
+	decoder := &cborDecoder {
+		data: data,
+	}
+	value, err := decoder.item()
+	if err != nil {
+		return nil, err
+	}
+	if decoder.offset != len(data) {
+		return nil, decoder.fail("trailing data after item")
+	}
+	return value, nil
+}
+
+func(decoder *cborDecoder) fail(reason string) error {
+	return &CBORError {
+		Offset: decoder.offset,
+		Reason: reason,
+	}
+}
+
+func(decoder *cborDecoder) take(count uint64) ([]byte, error) {
+	if count > uint64(len(decoder.data) - decoder.offset) {
+		return nil, decoder.fail("unexpected end of data")
+	}
+	chunk := decoder.data[decoder.offset:decoder.offset + int(count)]
+	decoder.offset += int(count)
+	return chunk, nil
+}
+
+func(decoder *cborDecoder) head() (byte, byte, uint64, error) {
+	lead, err := decoder.take(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major := lead[0] & 0xE0
+	info := lead[0] & 0x1F
+	var width uint64
+	switch {
+		case info < 24:
+			return major, info, uint64(info), nil
+		case info == 24:
+			width = 1
+		case info == 25:
+			width = 2
+		case info == 26:
+			width = 4
+		case info == 27:
+			width = 8
+		case info == cborIndefinite:
+			return major, info, 0, nil
+		default:
+			return 0, 0, 0, decoder.fail("reserved additional information")
+	}
+	raw, err := decoder.take(width)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	var argument uint64
+	for _, b := range raw {
+		argument = argument << 8 | uint64(b)
+	}
+	return major, info, argument, nil
+}
+
+func(decoder *cborDecoder) atBreak() bool {
+	if decoder.offset < len(decoder.data) && decoder.data[decoder.offset] == cborBreak {
+		decoder.offset++
+		return true
+	}
+	return false
+}
+
+func(decoder *cborDecoder) chunked(major byte, info byte, argument uint64) ([]byte, error) {
+	if info != cborIndefinite {
+		return decoder.take(argument)
+	}
+	var joined []byte
+	for !decoder.atBreak() {
+		chunkMajor, chunkInfo, chunkLength, err := decoder.head()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || chunkInfo == cborIndefinite {
+			return nil, decoder.fail("malformed indefinite-length string chunk")
+		}
+		chunk, err := decoder.take(chunkLength)
+		if err != nil {
+			return nil, err
+		}
+		joined = append(joined, chunk...)
+	}
+	return joined, nil
+}
+
+func(decoder *cborDecoder) item() (*Value, error) {
+	decoder.depth++
+	defer func() {
+		decoder.depth--
+	}()
+	if decoder.depth > CBORMaxDepth {
+		return nil, decoder.fail("nesting too deep")
+	}
+	major, info, argument, err := decoder.head()
+	if err != nil {
+		return nil, err
+	}
+	if info == cborIndefinite && (major == cborUnsigned || major == cborNegative || major == cborTag) {
+		return nil, decoder.fail("indefinite length on item that has none")
+	}
+	switch major {
+		case cborUnsigned:
+			if argument <= math.MaxInt64 {
+				return IntValue(int64(argument)), nil
+			}
+			return UintValue(argument), nil
+		case cborNegative:
+			if argument > math.MaxInt64 {
+				return nil, decoder.fail("negative integer out of range")
+			}
+			return IntValue(-1 - int64(argument)), nil
+		case cborBytes:
+			raw, err := decoder.chunked(major, info, argument)
+			if err != nil {
+				return nil, err
+			}
+			return BytesValue(append([]byte(nil), raw...)), nil
+		case cborText:
+			raw, err := decoder.chunked(major, info, argument)
+			if err != nil {
+				return nil, err
+			}
+			if !utf8.Valid(raw) {
+				return nil, decoder.fail("text string is not valid UTF-8")
+			}
+			return StringValue(string(raw)), nil
+		case cborArray:
+			list := ListValue()
+			for index := uint64(0); info == cborIndefinite || index < argument; index++ {
+				if info == cborIndefinite && decoder.atBreak() {
+					break
+				}
+				child, err := decoder.item()
+				if err != nil {
+					return nil, err
+				}
+				list.Append(child)
+			}
+			return list, nil
+		case cborMap:
+			m := MapValue()
+			for index := uint64(0); info == cborIndefinite || index < argument; index++ {
+				if info == cborIndefinite && decoder.atBreak() {
+					break
+				}
+				key, err := decoder.item()
+				if err != nil {
+					return nil, err
+				}
+				var name string
+				switch key.Kind {
+					case VAL_STRING:
+						name = key.String
+					case VAL_INT:
+						name = strconv.FormatInt(key.Int, 10)
+					case VAL_UINT:
+						name = strconv.FormatUint(key.Uint, 10)
+					default:
+						return nil, decoder.fail("map key is neither text nor integer")
+				}
+				child, err := decoder.item()
+				if err != nil {
+					return nil, err
+				}
+				m.Set(name, child)
+			}
+			return m, nil
+		case cborTag:
+			content, err := decoder.item()
+			if err != nil {
+				return nil, err
+			}
+			return decoder.tagged(argument, content)
+		default:
+			return decoder.simple(info, argument)
+	}
+}
+
+func(decoder *cborDecoder) tagged(tag uint64, content *Value) (*Value, error) {
+	switch tag {
+		case CBOR_TAG_DATE_TIME:
+			if content.Kind != VAL_STRING {
+				return nil, decoder.fail("date/time tag on non-text item")
+			}
+			stamp, err := time.Parse(time.RFC3339Nano, content.String)
+			if err != nil {
+				return nil, decoder.fail("malformed date/time: " + err.Error())
+			}
+			return TimeValue(stamp), nil
+		case CBOR_TAG_EPOCH_TIME:
+			switch content.Kind {
+				case VAL_INT:
+					return TimeValue(time.Unix(content.Int, 0)), nil
+				case VAL_FLOAT:
+					seconds, fraction := math.Modf(content.Float)
+					return TimeValue(time.Unix(int64(seconds), int64(fraction * 1e9))), nil
+				default:
+					return nil, decoder.fail("epoch time tag on non-numeric item")
+			}
+		case CBOR_TAG_EXTENDED_TIME, CBOR_TAG_DURATION:
+			seconds, nanos, ok := cborSecondsAndNanos(content)
+			if !ok {
+				return nil, decoder.fail("malformed extended time or duration")
+			}
+			if tag == CBOR_TAG_DURATION {
+				return DurationValue(time.Duration(seconds) * time.Second + time.Duration(nanos)), nil
+			}
+			return TimeValue(time.Unix(seconds, nanos)), nil
+		default:
+			return content, nil
+	}
+}
+
+func cborSecondsAndNanos(content *Value) (int64, int64, bool) {
+	if content.Kind != VAL_MAP {
+		return 0, 0, false
+	}
+	seconds := content.Field(strconv.FormatInt(cborSecondsKey, 10))
+	if seconds == nil || seconds.Kind != VAL_INT {
+		return 0, 0, false
+	}
+	var nanos int64
+	if fraction := content.Field(strconv.FormatInt(cborNanosecondsKey, 10)); fraction != nil {
+		if fraction.Kind != VAL_INT {
+			return 0, 0, false
+		}
+		nanos = fraction.Int
+	}
+	return seconds.Int, nanos, true
+}
+
+func(decoder *cborDecoder) simple(info byte, argument uint64) (*Value, error) {
+	switch info {
+		case 20:
+			return BoolValue(false), nil
+		case 21:
+			return BoolValue(true), nil
+		case 22, 23:
+			return NullValue(), nil
+		case 25:
+			return FloatValue(halfToFloat(uint16(argument))), nil
+		case 26:
+			return FloatValue(float64(math.Float32frombits(uint32(argument)))), nil
+		case 27:
+			return FloatValue(math.Float64frombits(argument)), nil
+		case cborIndefinite:
+			return nil, decoder.fail("unexpected break")
+		default:
+			return nil, decoder.fail("unsupported simple value")
+	}
+}
+
+func halfToFloat(half uint16) float64 {
+	exponent := int(half >> 10 & 0x1F)
+	mantissa := float64(half & 0x3FF)
+	var magnitude float64
+	switch exponent {
+		case 0:
+			magnitude = math.Ldexp(mantissa, -24)
+		case 31:
+			if mantissa == 0 {
+				magnitude = math.Inf(1)
+			} else {
+				magnitude = math.NaN()
+			}
+		default:
+			magnitude = math.Ldexp(mantissa + 1024, exponent - 25)
+	}
+	if half & 0x8000 != 0 {
+		return -magnitude
+	}
+	return magnitude
+}
+
+var _ ExtendedStructMap = &CBORStructSink{}
+var _ ExtendedStructList = &CBORStructSink{}
+var _ error = &CBORError{}
@@ -0,0 +1,83 @@
+package golog
+
+import (
+	"math"
+	"time"
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestCBORKnownEncodings(t *testing.T) {
+	cases := []struct {
+		value *Value
+		want []byte
+	}{
+		{ ListValue(IntValue(1), IntValue(-1), IntValue(24), IntValue(-500)), []byte { 0x9F, 0x01, 0x20, 0x18, 0x18, 0x39, 0x01, 0xF3, 0xFF } },
+		{ ListValue(StringValue("a"), BoolValue(true), BoolValue(false), NullValue()), []byte { 0x9F, 0x61, 0x61, 0xF5, 0xF4, 0xF6, 0xFF } },
+		{ ListValue(BytesValue([]byte { 1, 2 })), []byte { 0x9F, 0x42, 0x01, 0x02, 0xFF } },
+		{ ListValue(UintValue(math.MaxUint64)), []byte { 0x9F, 0x1B, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF } },
+	}
+	for _, c := range cases {
+		if got := EncodeCBOR(c.value); !bytes.Equal(got, c.want) {
+			t.Errorf("%v: got % x, want % x", c.value.ToAny(), got, c.want)
+		}
+	}
+	single := MapValue()
+	single.Set("a", IntValue(1))
+	if got := EncodeCBOR(single); !bytes.Equal(got, []byte { 0xBF, 0x61, 0x61, 0x01, 0xFF }) {
+		t.Errorf("map encoded as % x", got)
+	}
+}
+
+func TestDecodeCBORDefiniteLengths(t *testing.T) {
+	decoded, err := DecodeCBOR([]byte { 0xA1, 0x61, 0x6B, 0x82, 0x01, 0x20 })
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := MapValue()
+	want.Set("k", ListValue(IntValue(1), IntValue(-1)))
+	if !decoded.Equal(want) {
+		t.Fatalf("got %#v", decoded.ToAny())
+	}
+}
+
+func TestCBORRoundTrip(t *testing.T) {
+	value := MapValue()
+	value.Set("bool", BoolValue(true))
+	value.Set("string", StringValue("héllo"))
+	value.Set("int", IntValue(math.MinInt64))
+	value.Set("uint", UintValue(math.MaxUint64))
+	value.Set("float", FloatValue(1.25))
+	value.Set("time", TimeValue(time.Date(2024, 2, 3, 4, 5, 6, 7, time.UTC)))
+	value.Set("duration", DurationValue(-1500 * time.Millisecond))
+	value.Set("bytes", BytesValue([]byte { 0, 255 }))
+	value.Set("null", NullValue())
+	value.Set("list", ListValue(MapValue(), ListValue()))
+	decoded, err := DecodeCBOR(EncodeCBOR(value))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Equal(value) {
+		t.Fatalf("got %#v\nwant %#v", decoded.ToAny(), value.ToAny())
+	}
+}
+
+func TestDecodeCBORErrors(t *testing.T) {
+	deep := bytes.Repeat([]byte { 0x81 }, CBORMaxDepth + 1)
+	cases := [][]byte {
+		{},
+		{ 0x82, 0x01 },
+		{ 0x01, 0x02 },
+		{ 0x62, 0xFF, 0xFE },
+		{ 0x1C },
+		append(deep, 0x01),
+	}
+	for _, data := range cases {
+		_, err := DecodeCBOR(data)
+		var cborErr *CBORError
+		if !errors.As(err, &cborErr) {
+			t.Errorf("% x: expected CBORError, got %v", data, err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"fmt"
+	"flag"
+	"strconv"
+	"crypto/ed25519"
+
+	"github.com/UncleSniper/golog"
+)
+
+const usageText = `Usage: golog-audit-verify [options] file...
+
+Verifies the hash chain of golog audit logs and reports the first broken
+link in each file. Keys may be given raw, hex- or base64-encoded.
+
+Options:
+`
+
+func verify(name string, verifier *golog.AuditVerifier, quiet bool) bool {
+	file, err := os.Open(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "golog-audit-verify: %s\n", err)
+		return false
+	}
+	defer file.Close()
+	report, err := verifier.Verify(file)
+	if err != nil {
+		fmt.Printf("%s: BROKEN: %s\n", name, err)
+		return false
+	}
+	if report.TornLine > 0 {
+		fmt.Printf("%s: TORN: line %d is incomplete (%d bytes) and was not verified\n", name, report.TornLine,
+				len(report.TornTail))
+	}
+	if !quiet {
+		fmt.Printf("%s: OK: %d entries, %d checkpoints", name, report.Entries, report.Checkpoints)
+		if verifier.PublicKey != nil && report.Unsigned > 0 {
+			fmt.Print(", " + strconv.FormatUint(report.Unsigned, 10) + " entries after last checkpoint")
+		}
+		fmt.Println()
+	}
+	return true
+}
+
+func run(args []string) int {
+	flags := flag.NewFlagSet("golog-audit-verify", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usageText)
+		flags.PrintDefaults()
+	}
+	hmacKey := flags.String("hmac-key", "", "`file` containing the HMAC key the chain was written with")
+	publicKey := flags.String("public-key", "", "`file` containing the Ed25519 public key for checkpoint signatures")
+	quiet := flags.Bool("q", false, "only report broken files")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+	verifier := &golog.AuditVerifier{}
+	if len(*hmacKey) > 0 {
+		key, err := golog.ReadKeyFile(*hmacKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "golog-audit-verify: %s\n", err)
+			return 2
+		}
+		verifier.HashKey = key
+	}
+	if len(*publicKey) > 0 {
+		key, err := golog.ReadKeyFile(*publicKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "golog-audit-verify: %s\n", err)
+			return 2
+		}
+		if len(key) != ed25519.PublicKeySize {
+			fmt.Fprintf(os.Stderr, "golog-audit-verify: %s: public key must be %d bytes\n", *publicKey, ed25519.PublicKeySize)
+			return 2
+		}
+		verifier.PublicKey = key
+	}
+	status := 0
+	for _, name := range flags.Args() {
+		if !verify(name, verifier, *quiet) {
+			status = 1
+		}
+	}
+	return status
+}
+
+func main() {
+	status := run(os.Args[1:])
+	os.Exit(status)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"io"
+	"os"
+	"time"
+	"errors"
+)
+
+var errFollowIdle = errors.New("no new data yet")
+
+type followReader struct {
+	path string
+	file *os.File
+	offset int64
+	poll time.Duration
+	reportIdle bool
+	idle bool
+}
+
+func openFollower(path string, poll time.Duration) (*followReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &followReader {
+		path: path,
+		file: file,
+		poll: poll,
+	}, nil
+}
+
+func(reader *followReader) Read(buffer []byte) (int, error) {
+	for {
+		count, err := reader.file.Read(buffer)
+		if count > 0 {
+			reader.offset += int64(count)
+			reader.idle = false
+			return count, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		if reader.reopenIfRotated() {
+			continue
+		}
+		if reader.reportIdle && !reader.idle {
+			reader.idle = true
+			return 0, errFollowIdle
+		}
+		time.Sleep(reader.poll)
+	}
+}
+
+func(reader *followReader) reopenIfRotated() bool {
+	current, err := reader.file.Stat()
+	if err != nil {
+		return false
+	}
+	if current.Size() < reader.offset {
+		if _, err := reader.file.Seek(0, io.SeekStart); err == nil {
+			reader.offset = 0
+			return true
+		}
+		return false
+	}
+	latest, err := os.Stat(reader.path)
+	if err != nil || os.SameFile(current, latest) {
+		return false
+	}
+	replacement, err := os.Open(reader.path)
+	if err != nil {
+		return false
+	}
+	reader.file.Close()
+	reader.file = replacement
+	reader.offset = 0
+	return true
+}
+
+func(reader *followReader) enableIdleReports() {
+	reader.reportIdle = true
+}
+
+func(reader *followReader) Close() error {
+	return reader.file.Close()
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"io"
+	"os"
+	"errors"
+	"bufio"
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/UncleSniper/golog"
+)
+
+type packetSource interface {
+	Next() (*golog.Packet, error)
+}
+
+type inputFormat uint
+
+const (
+	inAuto inputFormat = iota
+	inJSON
+	inLogfmt
+	inBinary
+	inText
+)
+
+func parseInputFormat(name string) (inputFormat, bool) {
+	switch strings.ToLower(name) {
+		case "", "auto":
+			return inAuto, true
+		case "json", "jsonl", "ndjson":
+			return inJSON, true
+		case "logfmt":
+			return inLogfmt, true
+		case "binary", "cbor":
+			return inBinary, true
+		case "text":
+			return inText, true
+		default:
+			return inAuto, false
+	}
+}
+
+func keyDirectory(dir string) golog.EncryptionKeyFunc {
+	return func(keyID string) ([]byte, error) {
+		if len(dir) == 0 {
+			return nil, errors.New("log is encrypted; use -keys to name the key directory")
+		}
+		if len(keyID) == 0 || strings.ContainsAny(keyID, "/\\") || keyID == "." || keyID == ".." {
+			return nil, errors.New("invalid key ID \"" + keyID + "\"")
+		}
+		return golog.ReadKeyFile(dir + string(os.PathSeparator) + keyID)
+	}
+}
+
+type lineError struct {
+	Line int
+	Err error
+}
+
+func(err *lineError) Error() string {
+	return "line " + strconv.Itoa(err.Line) + ": " + err.Err.Error()
+}
+
+func(err *lineError) Unwrap() error {
+	return err.Err
+}
+
+func openSource(reader io.Reader, format inputFormat, keys golog.EncryptionKeyFunc) packetSource {
+	follower, _ := reader.(*followReader)
+	return openFollowedSource(reader, follower, format, keys)
+}
+
+func openFollowedSource(reader io.Reader, follower *followReader, format inputFormat,
+		keys golog.EncryptionKeyFunc) packetSource {
+	buffered := bufio.NewReader(reader)
+	peeked, _ := buffered.Peek(len(golog.EncryptedLogMagic))
+	if bytes.Equal(peeked, []byte(golog.EncryptedLogMagic)) {
+		return openFollowedSource(golog.NewEncryptedLogReader(buffered, keys), follower, format, keys)
+	}
+	if format == inAuto && bytes.Equal(peeked, []byte(golog.BinaryLogMagic)) {
+		format = inBinary
+	}
+	if format == inBinary {
+		return golog.NewBinaryPacketReader(buffered)
+	}
+	if follower != nil {
+		follower.enableIdleReports()
+	}
+	return &lineSource {
+		reader: buffered,
+		format: format,
+	}
+}
+
+type lineSource struct {
+	reader *bufio.Reader
+	format inputFormat
+	lineNumber int
+	pending string
+	hasPending bool
+	partial string
+	text *textState
+}
+
+func(source *lineSource) readLine() (string, error) {
+	if source.hasPending {
+		source.hasPending = false
+		return source.pending, nil
+	}
+	line, err := source.reader.ReadString('\n')
+	if err == errFollowIdle {
+		source.partial += line
+		return "", err
+	}
+	if len(source.partial) > 0 {
+		line = source.partial + line
+		source.partial = ""
+	}
+	if len(line) == 0 && err != nil {
+		return "", err
+	}
+	source.lineNumber++
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func(source *lineSource) unreadLine(line string) {
+	source.pending = line
+	source.hasPending = true
+}
+
+func(source *lineSource) Next() (*golog.Packet, error) {
+	for {
+		line, err := source.readLine()
+		if err == errFollowIdle {
+			if len(source.partial) == 0 && source.text != nil {
+				if packet := source.text.flush(); packet != nil {
+					return packet, nil
+				}
+			}
+			continue
+		}
+		if err != nil {
+			if source.text != nil {
+				if packet := source.text.flush(); packet != nil {
+					return packet, nil
+				}
+			}
+			return nil, err
+		}
+		if source.format == inAuto {
+			if len(strings.TrimSpace(line)) == 0 {
+				continue
+			}
+			source.format = detectLineFormat(line)
+		}
+		switch source.format {
+			case inJSON:
+				if len(strings.TrimSpace(line)) == 0 {
+					continue
+				}
+				value, err := golog.DecodeJSON([]byte(line))
+				if err == nil {
+					var packet *golog.Packet
+					packet, err = golog.PacketFromValue(value)
+					if err == nil {
+						return packet, nil
+					}
+				}
+				return nil, &lineError {
+					Line: source.lineNumber,
+					Err: err,
+				}
+			case inLogfmt:
+				if len(strings.TrimSpace(line)) == 0 {
+					continue
+				}
+				packet, err := golog.ParseLogfmt(line)
+				if err != nil {
+					return nil, &lineError {
+						Line: source.lineNumber,
+						Err: err,
+					}
+				}
+				return packet, nil
+			default:
+				if source.text == nil {
+					source.text = &textState{}
+				}
+				packet, complete, err := source.text.feed(line)
+				if complete {
+					source.unreadLine(line)
+					return packet, nil
+				}
+				if err != nil {
+					return nil, &lineError {
+						Line: source.lineNumber,
+						Err: err,
+					}
+				}
+		}
+	}
+}
+
+func detectLineFormat(line string) inputFormat {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "{") {
+		return inJSON
+	}
+	if textHeader.MatchString(line) {
+		return inText
+	}
+	pairs, err := golog.ScanLogfmt(trimmed)
+	if err == nil {
+		for _, pair := range pairs {
+			switch pair.Key {
+				case golog.LogfmtTimeKey, golog.LogfmtLevelKey, golog.LogfmtMessageKey:
+					return inLogfmt
+			}
+		}
+	}
+	return inText
+}
+
+var textHeader = regexp.MustCompile(`^(\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{9}(?:Z|[+-]\d\d:\d\d)) (\S+) +` +
+		`(?:\[([^\]]*)\] )?(.*)$`)
+
+type textState struct {
+	packet *golog.Packet
+	lines []string
+	indent int
+}
+
+func(state *textState) flush() *golog.Packet {
+	if state.packet == nil {
+		return nil
+	}
+	packet := state.packet
+	packet.Message = &golog.StringMessage {
+		Text: state.lines,
+	}
+	state.packet = nil
+	state.lines = nil
+	return packet
+}
+
+func(state *textState) feed(line string) (*golog.Packet, bool, error) {
+	groups := textHeader.FindStringSubmatch(line)
+	if groups == nil {
+		if state.packet == nil {
+			state.packet = &golog.Packet{}
+		}
+		trimmed := line
+		for skipped := 0; skipped < state.indent && strings.HasPrefix(trimmed, " "); skipped++ {
+			trimmed = trimmed[1:]
+		}
+		state.lines = append(state.lines, trimmed)
+		return nil, false, nil
+	}
+	if state.packet != nil {
+		return state.flush(), true, nil
+	}
+	stamp, err := time.Parse(golog.DefaultLayoutTimeFormat, groups[1])
+	if err != nil {
+		stamp = time.Time{}
+	}
+	level, err := golog.LevelFromName(groups[2])
+	state.packet = &golog.Packet {
+		Timestamp: stamp,
+		Level: level,
+	}
+	if len(groups[3]) > 0 {
+		state.packet.Source = golog.TextSource(groups[3])
+	}
+	state.lines = []string { groups[4] }
+	state.indent = golog.TextWidth(line[:len(line) - len(groups[4])])
+	return nil, false, err
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"os"
+	"errors"
+	"bytes"
+	"time"
+	"strings"
+	"testing"
+	"path/filepath"
+
+	"github.com/UncleSniper/golog"
+)
+
+const textSample = "2024-01-02T03:04:05.000000000Z INFO    [app.db] query failed\n" +
+		"                                       retrying\n" +
+		"2024-01-02T03:04:06.000000000Z ERROR   gave up\n"
+
+func TestParseInputFormat(t *testing.T) {
+	for name, want := range map[string]inputFormat {
+		"": inAuto,
+		"JSONL": inJSON,
+		"logfmt": inLogfmt,
+		"cbor": inBinary,
+		"text": inText,
+	} {
+		if got, ok := parseInputFormat(name); !ok || got != want {
+			t.Errorf("%q: got %v, %v", name, got, ok)
+		}
+	}
+	if _, ok := parseInputFormat("xml"); ok {
+		t.Error("unknown format accepted")
+	}
+}
+
+func TestDetectLineFormat(t *testing.T) {
+	cases := map[string]inputFormat {
+		`{"level":"INFO"}`: inJSON,
+		`time=2024-01-02T03:04:05Z level=info msg=hi`: inLogfmt,
+		"2024-01-02T03:04:05.000000000Z INFO    hi": inText,
+		"plain words": inText,
+	}
+	for line, want := range cases {
+		if got := detectLineFormat(line); got != want {
+			t.Errorf("%q: got %v, want %v", line, got, want)
+		}
+	}
+}
+
+func TestTextSourceMultiLine(t *testing.T) {
+	source := openSource(strings.NewReader(textSample), inAuto, nil)
+	first, err := source.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := first.Message.Lines(); len(lines) != 2 || lines[0] != "query failed" || lines[1] != "retrying" {
+		t.Errorf("first lines: %q", lines)
+	}
+	if first.Level != golog.INFO || first.Source.StringSource() != "app.db" {
+		t.Errorf("first header: %v %v", first.Level, first.Source)
+	}
+	second, err := source.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Level != golog.ERROR || second.Source != nil || second.Message.Lines()[0] != "gave up" {
+		t.Errorf("second: %v %v %q", second.Level, second.Source, second.Message.Lines())
+	}
+	if _, err := source.Next(); err == nil {
+		t.Error("expected end of input")
+	}
+}
+
+func expectFollowedPackets(t *testing.T, path string, keys golog.EncryptionKeyFunc, appendText func(string)) {
+	t.Helper()
+	follower, err := openFollower(path, 5 * time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer follower.Close()
+	source := openSource(follower, inText, keys)
+	packets := make(chan *golog.Packet)
+	go func() {
+		for {
+			packet, err := source.Next()
+			if err != nil {
+				close(packets)
+				return
+			}
+			packets <- packet
+		}
+	}()
+	for i, want := range []string { "query failed", "gave up" } {
+		select {
+			case packet := <-packets:
+				if got := packet.Message.Lines()[0]; got != want {
+					t.Fatalf("packet %d: got %q, want %q", i, got, want)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatalf("packet %d (%q) held back while following", i, want)
+		}
+	}
+	appendText("2024-01-02T03:04:07.000000000Z WARNING partial")
+	time.Sleep(50 * time.Millisecond)
+	appendText(" line\n")
+	select {
+		case packet := <-packets:
+			if got := packet.Message.Lines(); len(got) != 1 || got[0] != "partial line" {
+				t.Fatalf("appended packet: %q", got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("appended packet held back while following")
+	}
+}
+
+func TestTextSourceReportsUnknownLevels(t *testing.T) {
+	source := openSource(strings.NewReader("2024-01-02T03:04:05.000000000Z WRANING disk low\n" +
+			"2024-01-02T03:04:06.000000000Z INFO    fine\n"), inText, nil)
+	_, err := source.Next()
+	var lineErr *lineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 1 || !strings.Contains(err.Error(), "WRANING") {
+		t.Fatalf("got %v", err)
+	}
+	kept, err := source.Next()
+	if err != nil || kept.Level != nil || kept.Message.Lines()[0] != "disk low" {
+		t.Fatalf("packet with unknown level: %v, %v", kept, err)
+	}
+	if next, err := source.Next(); err != nil || next.Level != golog.INFO {
+		t.Fatalf("following packet: %v, %v", next, err)
+	}
+}
+
+func TestFollowFlushesPendingTextPacket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte(textSample), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY | os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	expectFollowedPackets(t, path, nil, func(text string) {
+		file.WriteString(text)
+	})
+}
+
+func TestFollowFlushesPendingEncryptedTextPacket(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "k1"), bytes.Repeat([]byte { 1 }, 32), 0600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "app.log")
+	file, err := os.OpenFile(path, os.O_WRONLY | os.O_CREATE | os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	logger := &golog.EncryptedLogger {
+		ID: golog.NewLoggerID(),
+		Writer: file,
+		Formatter: golog.MessageTextFormatter{},
+		ChunkSize: -1,
+	}
+	if err := logger.Rotate("k1", bytes.Repeat([]byte { 1 }, 32)); err != nil {
+		t.Fatal(err)
+	}
+	var partial string
+	write := func(text string) {
+		partial += text
+		if !strings.HasSuffix(partial, "\n") {
+			return
+		}
+		logger.Log(&golog.Packet {
+			Message: &golog.StringMessage {
+				Text: strings.Split(strings.TrimSuffix(partial, "\n"), "\n"),
+			},
+		})
+		partial = ""
+	}
+	write(textSample)
+	expectFollowedPackets(t, path, keyDirectory(dir), write)
+}
+
+func TestKeyDirectoryRejectsPaths(t *testing.T) {
+	keys := keyDirectory(t.TempDir())
+	for _, id := range []string { "", ".", "..", "../etc/passwd", "a\\b" } {
+		if _, err := keys(id); err == nil {
+			t.Errorf("%q accepted", id)
+		}
+	}
+	if _, err := keyDirectory("")("k1"); err == nil {
+		t.Error("missing key directory accepted")
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"io"
+	"os"
+	"fmt"
+	"flag"
+	"sync"
+	"time"
+	"bufio"
+	"errors"
+
+	"github.com/UncleSniper/golog"
+)
+
+const usageText = `Usage: golog-cat [options] [file...]
+
+Reads golog-produced log files (JSON lines, logfmt, binary or the default
+text layout, optionally encrypted), filters the packets and renders them
+again. Without files,
+or with "-", standard input is read.
+
+Filter expressions compare fields against values, e.g.
+    level >= warning && source =~ '^db\.' && details.user == "bob"
+Fields are level, source, msg, time, nominal and any (dotted) path into
+the packet details. Operators are == != < <= > >= =~ !~ contains, joined
+by && (and), || (or) and ! (not).
+
+Options:
+`
+
+type usageError struct {
+	Reason string
+}
+
+func(err *usageError) Error() string {
+	return err.Reason
+}
+
+type options struct {
+	input inputFormat
+	formatter golog.TextFormatter
+	colors *colorizer
+	filter golog.Predicate[*golog.Packet]
+	keys golog.EncryptionKeyFunc
+	follow bool
+	poll time.Duration
+}
+
+type timeRange struct {
+	since time.Time
+	until time.Time
+}
+
+func(pred *timeRange) Match(stamp time.Time) bool {
+	if !pred.since.IsZero() && stamp.Before(pred.since) {
+		return false
+	}
+	return pred.until.IsZero() || stamp.Before(pred.until)
+}
+
+func parseTimeSpec(spec string, now time.Time) (time.Time, error) {
+	if duration, err := time.ParseDuration(spec); err == nil {
+		if duration < 0 {
+			duration = -duration
+		}
+		return now.Add(-duration), nil
+	}
+	return golog.ParseFilterTime(spec)
+}
+
+func parseThreshold(spec string) (golog.Level, error) {
+	level, err := golog.ParseLevel(spec)
+	if err != nil {
+		return nil, &usageError {
+			Reason: "unknown level \"" + spec + "\"",
+		}
+	}
+	return level, nil
+}
+
+func parseOptions(args []string) (*options, []string, error) {
+	flags := flag.NewFlagSet("golog-cat", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usageText)
+		flags.PrintDefaults()
+	}
+	input := flags.String("input", "auto", "input `format`: auto, json, logfmt, binary or text")
+	format := flags.String("format", "text", "output `format`: text, json, logfmt, gelf, ecs or otel")
+	layout := flags.String("layout", golog.DefaultLayout, "`layout` for text output")
+	color := flags.String("color", "auto", "colorize output by level: auto, always or never")
+	filter := flags.String("filter", "", "only show packets matching this `expression`")
+	level := flags.String("level", "", "only show packets at or above this `level`")
+	since := flags.String("since", "", "only show packets at or after this `time` (or duration ago)")
+	until := flags.String("until", "", "only show packets before this `time` (or duration ago)")
+	keys := flags.String("keys", "", "`directory` holding decryption keys, one file per key ID")
+	follow := flags.Bool("f", false, "keep reading as the files grow, surviving rotation")
+	poll := flags.Duration("poll", 250 * time.Millisecond, "polling `interval` in follow mode")
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	opts := &options {
+		keys: keyDirectory(*keys),
+		follow: *follow,
+		poll: *poll,
+	}
+	var ok bool
+	if opts.input, ok = parseInputFormat(*input); !ok {
+		return nil, nil, &usageError {
+			Reason: "unknown input format \"" + *input + "\" (expected auto, json, logfmt, binary or text)",
+		}
+	}
+	var err error
+	if opts.formatter, err = outputFormatter(*format, *layout); err != nil {
+		return nil, nil, err
+	}
+	if opts.colors, err = newColorizer(*color); err != nil {
+		return nil, nil, err
+	}
+	var predicates []golog.Predicate[*golog.Packet]
+	if len(*filter) > 0 {
+		pred, err := golog.CompileFilter(*filter)
+		if err != nil {
+			return nil, nil, err
+		}
+		predicates = append(predicates, pred)
+	}
+	if len(*level) > 0 {
+		threshold, err := parseThreshold(*level)
+		if err != nil {
+			return nil, nil, err
+		}
+		predicates = append(predicates, &golog.LevelPredicate {
+			Predicate: &golog.LevelOrderPredicate {
+				Threshold: threshold.Numerical(),
+				Relation: golog.ORDR_GREATER_EQUAL,
+			},
+		})
+	}
+	if len(*since) > 0 || len(*until) > 0 {
+		now := time.Now()
+		span := &timeRange{}
+		if len(*since) > 0 {
+			if span.since, err = parseTimeSpec(*since, now); err != nil {
+				return nil, nil, &usageError {
+					Reason: "invalid -since time \"" + *since + "\"",
+				}
+			}
+		}
+		if len(*until) > 0 {
+			if span.until, err = parseTimeSpec(*until, now); err != nil {
+				return nil, nil, &usageError {
+					Reason: "invalid -until time \"" + *until + "\"",
+				}
+			}
+		}
+		predicates = append(predicates, &golog.TimestampPredicate {
+			Predicate: span,
+		})
+	}
+	opts.filter = golog.AllPredicate[*golog.Packet] {
+		Children: predicates,
+	}
+	return opts, flags.Args(), nil
+}
+
+type printer struct {
+	opts *options
+	out *bufio.Writer
+	mutex sync.Mutex
+}
+
+func(p *printer) print(packet *golog.Packet) {
+	if !p.opts.filter.Match(packet) {
+		return
+	}
+	lines := p.opts.colors.wrap(packet, p.opts.formatter.PacketToText(packet))
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for _, line := range lines {
+		p.out.WriteString(line)
+		p.out.WriteByte('\n')
+	}
+	if p.opts.follow {
+		p.out.Flush()
+	}
+}
+
+func warn(name string, err error) {
+	fmt.Fprintf(os.Stderr, "golog-cat: %s: %s\n", name, err)
+}
+
+func drain(name string, source packetSource, p *printer) bool {
+	for {
+		packet, err := source.Next()
+		switch {
+			case err == nil:
+				p.print(packet)
+			case err == io.EOF:
+				return true
+			default:
+				warn(name, err)
+				var lineErr *lineError
+				if !errors.As(err, &lineErr) {
+					return false
+				}
+		}
+	}
+}
+
+func open(name string, opts *options) (io.Reader, func(), error) {
+	if name == "-" {
+		return os.Stdin, func() {}, nil
+	}
+	if opts.follow {
+		follower, err := openFollower(name, opts.poll)
+		if err != nil {
+			return nil, nil, err
+		}
+		return follower, func() {
+			follower.Close()
+		}, nil
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, func() {
+		file.Close()
+	}, nil
+}
+
+func run(args []string) int {
+	opts, files, err := parseOptions(args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "golog-cat: %s\n", err)
+		return 2
+	}
+	if len(files) == 0 {
+		files = []string { "-" }
+	}
+	p := &printer {
+		opts: opts,
+		out: bufio.NewWriter(os.Stdout),
+	}
+	defer p.out.Flush()
+	status := 0
+	var group sync.WaitGroup
+	var statusMutex sync.Mutex
+	process := func(name string) {
+		reader, closer, err := open(name, opts)
+		if err != nil {
+			warn(name, err)
+			statusMutex.Lock()
+			status = 1
+			statusMutex.Unlock()
+			return
+		}
+		defer closer()
+		if !drain(name, openSource(reader, opts.input, opts.keys), p) {
+			statusMutex.Lock()
+			status = 1
+			statusMutex.Unlock()
+		}
+	}
+	for _, name := range files {
+		if opts.follow && len(files) > 1 {
+			group.Add(1)
+			go func(name string) {
+				defer group.Done()
+				process(name)
+			}(name)
+		} else {
+			process(name)
+		}
+	}
+	group.Wait()
+	return status
+}
+
+func main() {
+	status := run(os.Args[1:])
+	os.Exit(status)
+}
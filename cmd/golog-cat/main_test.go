@@ -0,0 +1,55 @@
+package main
+
+import (
+	"time"
+	"testing"
+
+	"github.com/UncleSniper/golog"
+)
+
+func TestParseOptionsFilters(t *testing.T) {
+	opts, files, err := parseOptions([]string { "-level", "warning", "-since", "2024-01-02T00:00:00Z", "a.log" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0] != "a.log" {
+		t.Errorf("files: %q", files)
+	}
+	stamp := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		packet *golog.Packet
+		want bool
+	} {
+		{ &golog.Packet { Level: golog.ERROR, Timestamp: stamp }, true },
+		{ &golog.Packet { Level: golog.INFO, Timestamp: stamp }, false },
+		{ &golog.Packet { Level: golog.ERROR, Timestamp: stamp.Add(-24 * time.Hour) }, false },
+	}
+	for i, c := range cases {
+		if got := opts.filter.Match(c.packet); got != c.want {
+			t.Errorf("case %d: got %v, want %v", i, got, c.want)
+		}
+	}
+}
+
+func TestParseOptionsRejectsBadInput(t *testing.T) {
+	for _, args := range [][]string {
+		{ "-input", "xml" },
+		{ "-level", "loud" },
+		{ "-since", "yesterday-ish" },
+		{ "-filter", "level >=" },
+	} {
+		if _, _, err := parseOptions(args); err == nil {
+			t.Errorf("%q accepted", args)
+		}
+	}
+}
+
+func TestParseTimeSpecDuration(t *testing.T) {
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	for _, spec := range []string { "1h", "-1h" } {
+		got, err := parseTimeSpec(spec, now)
+		if err != nil || !got.Equal(now.Add(-time.Hour)) {
+			t.Errorf("%q: got %v, %v", spec, got, err)
+		}
+	}
+}
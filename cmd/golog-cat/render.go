@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/UncleSniper/golog"
+)
+
+func outputFormatter(format string, layout string) (golog.TextFormatter, error) {
+	switch strings.ToLower(format) {
+		case "", "text":
+			return golog.CompileLayout(layout, golog.PFX_THEN_SPACES)
+		case "json":
+			return golog.JSONPacketEncoder{}, nil
+		case "logfmt":
+			return &golog.LogfmtTextFormatter {
+				IncludeStack: true,
+			}, nil
+		case "gelf":
+			return &golog.GELFEncoder {
+				IncludeStack: true,
+			}, nil
+		case "ecs":
+			return &golog.ECSEncoder{}, nil
+		case "otel":
+			return &golog.OTelEncoder{}, nil
+		default:
+			return nil, &usageError {
+				Reason: "unknown output format \"" + format + "\" (expected text, json, logfmt, gelf, ecs or otel)",
+			}
+	}
+}
+
+type colorizer struct {
+	enabled bool
+	depth golog.ColorDepth
+	palette golog.Palette
+}
+
+func newColorizer(mode string) (*colorizer, error) {
+	c := &colorizer {
+		depth: golog.DetectColorDepth(),
+		palette: golog.DumbPalette,
+	}
+	switch strings.ToLower(mode) {
+		case "", "auto":
+			c.enabled = golog.IsTerminal(os.Stdout) && !golog.NoColorRequested()
+		case "always":
+			c.enabled = true
+		case "never":
+		default:
+			return nil, &usageError {
+				Reason: "unknown color mode \"" + mode + "\" (expected auto, always or never)",
+			}
+	}
+	return c, nil
+}
+
+func(c *colorizer) wrap(packet *golog.Packet, lines []string) []string {
+	if !c.enabled {
+		return lines
+	}
+	style := c.palette.StyleFor(packet.Level)
+	if style == nil {
+		return lines
+	}
+	sequence := style.Sequence(c.depth)
+	if len(sequence) == 0 {
+		return lines
+	}
+	styled := make([]string, len(lines))
+	for index, line := range lines {
+		styled[index] = sequence + line + golog.StyleReset
+	}
+	return styled
+}
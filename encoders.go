@@ -0,0 +1,307 @@
+package golog
+
+import (
+	"os"
+	"fmt"
+	"time"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+func SyslogSeverity(level Level) int {
+	severity, _ := SyslogLevels.ToForeign(level)
+	return severity
+}
+
+func OTelSeverityNumber(level Level) int {
+	number, _ := OTelLevels.ToForeign(level)
+	return number
+}
+
+func stackText(trace StackTrace) string {
+	lines := make([]string, 0, len(trace))
+	for index := range trace {
+		lines = append(lines, trace[index].String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func messageText(packet *Packet) string {
+	if packet.Message == nil {
+		return ""
+	}
+	return strings.Join(packet.Message.Lines(), "\n")
+}
+
+func putInline(structure Structure, target StructMap, drop map[string]bool) {
+	if structure != nil {
+		structure.PutStruct(&inlineSink {
+			target: target,
+			drop: drop,
+		})
+	}
+}
+
+const GELFVersion = "1.1"
+
+var hostnameOnce sync.Once
+var hostname string
+
+func defaultHostname() string {
+	hostnameOnce.Do(func() {
+		name, err := os.Hostname()
+		if err != nil || len(name) == 0 {
+			name = "localhost"
+		}
+		hostname = name
+	})
+	return hostname
+}
+
+type GELFEncoder struct {
+	Host string
+	Extra Structure
+	IncludeStack bool
+}
+
+func(enc *GELFEncoder) EncodePacket(packet *Packet) ([]byte, error) {
+	return []byte(enc.encode(packet)), nil
+}
+
+func(enc *GELFEncoder) PacketToText(packet *Packet) []string {
+	return []string { enc.encode(packet) }
+}
+
+func(enc *GELFEncoder) encode(packet *Packet) string {
+	sink := &JSONStructSink{}
+	m := sink.Map()
+	m.StringProperty("version", GELFVersion)
+	host := enc.Host
+	if len(host) == 0 {
+		host = defaultHostname()
+	}
+	m.StringProperty("host", host)
+	var lines []string
+	if packet.Message != nil {
+		lines = packet.Message.Lines()
+	}
+	short := "-"
+	if len(lines) > 0 && len(lines[0]) > 0 {
+		short = lines[0]
+	}
+	m.StringProperty("short_message", short)
+	full := strings.Join(lines, "\n")
+	if enc.IncludeStack && len(packet.Stack) > 0 {
+		full += "\n" + stackText(packet.Stack)
+	}
+	if full != short {
+		m.StringProperty("full_message", full)
+	}
+	if !packet.Timestamp.IsZero() {
+		m.FloatProperty("timestamp", float64(packet.Timestamp.UnixMicro()) / 1e6)
+	}
+	m.IntProperty("level", int64(SyslogSeverity(packet.Level)))
+	seen := make(map[string]bool)
+	emit := func(key string, value *Value) {
+		key = gelfFieldName(key)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		switch value.Kind {
+			case VAL_NULL:
+			case VAL_INT:
+				m.IntProperty(key, value.Int)
+			case VAL_UINT:
+				PutUintProperty(m, key, value.Uint)
+			case VAL_FLOAT:
+				m.FloatProperty(key, value.Float)
+			default:
+				m.StringProperty(key, FormatScalarValue(value))
+		}
+	}
+	if packet.Level != nil {
+		emit("level_name", StringValue(packet.Level.HumanReadable(ADJ_NONE)))
+	}
+	if packet.Source != nil {
+		emit("source", StringValue(packet.Source.StringSource()))
+	}
+	if packet.Message != nil {
+		packet.Message.PutStruct(&FlatteningSink {
+			Emit: emit,
+		})
+	}
+	if enc.Extra != nil {
+		enc.Extra.PutStruct(&FlatteningSink {
+			Emit: emit,
+		})
+	}
+	m.EndMap()
+	return sink.ToString()
+}
+
+func gelfFieldName(key string) string {
+	var builder strings.Builder
+	builder.WriteByte('_')
+	for _, r := range key {
+		switch {
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+				builder.WriteRune(r)
+			default:
+				builder.WriteByte('_')
+		}
+	}
+	name := builder.String()
+	if name == "_id" {
+		name = "__id"
+	}
+	return name
+}
+
+const ECSVersion = "8.11.0"
+
+var ecsReservedKeys = map[string]bool {
+	"@timestamp": true,
+	"message": true,
+	"log.level": true,
+	"log.logger": true,
+	"ecs.version": true,
+	"error.stack_trace": true,
+}
+
+type ECSEncoder struct {
+	Static Structure
+	FieldsKey string
+}
+
+func(enc *ECSEncoder) EncodePacket(packet *Packet) ([]byte, error) {
+	return []byte(enc.encode(packet)), nil
+}
+
+func(enc *ECSEncoder) PacketToText(packet *Packet) []string {
+	return []string { enc.encode(packet) }
+}
+
+func(enc *ECSEncoder) encode(packet *Packet) string {
+	sink := &JSONStructSink{}
+	m := sink.Map()
+	timestamp := packet.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	m.StringProperty("@timestamp", timestamp.UTC().Format(time.RFC3339Nano))
+	if packet.Level != nil {
+		m.StringProperty("log.level", strings.ToLower(packet.Level.HumanReadable(ADJ_NONE)))
+	}
+	m.StringProperty("message", messageText(packet))
+	if packet.Source != nil {
+		m.StringProperty("log.logger", packet.Source.StringSource())
+	}
+	m.StringProperty("ecs.version", ECSVersion)
+	if len(packet.Stack) > 0 {
+		m.StringProperty("error.stack_trace", stackText(packet.Stack))
+	}
+	drop := make(map[string]bool, len(ecsReservedKeys))
+	for key := range ecsReservedKeys {
+		drop[key] = true
+	}
+	if packet.Message != nil {
+		if len(enc.FieldsKey) > 0 {
+			if !IsEmptyStructure(packet.Message) {
+				packet.Message.PutStruct(&propertySink {
+					target: m,
+					name: enc.FieldsKey,
+				})
+			}
+			drop[enc.FieldsKey] = true
+		} else {
+			putInline(packet.Message, m, ecsReservedKeys)
+			CollectTopLevelKeys(packet.Message, drop)
+		}
+	}
+	putInline(enc.Static, m, drop)
+	m.EndMap()
+	return sink.ToString()
+}
+
+const (
+	OTelTimestampKey = "Timestamp"
+	OTelSeverityTextKey = "SeverityText"
+	OTelSeverityNumberKey = "SeverityNumber"
+	OTelBodyKey = "Body"
+	OTelResourceKey = "Resource"
+	OTelScopeKey = "InstrumentationScope"
+	OTelScopeNameKey = "Name"
+	OTelAttributesKey = "Attributes"
+)
+
+type OTelEncoder struct {
+	Resource Structure
+	Scope string
+}
+
+func(enc *OTelEncoder) EncodePacket(packet *Packet) ([]byte, error) {
+	return []byte(enc.encode(packet)), nil
+}
+
+func(enc *OTelEncoder) PacketToText(packet *Packet) []string {
+	return []string { enc.encode(packet) }
+}
+
+func(enc *OTelEncoder) encode(packet *Packet) string {
+	sink := &JSONStructSink{}
+	m := sink.Map()
+	if !packet.Timestamp.IsZero() {
+		m.StringProperty(OTelTimestampKey, strconv.FormatInt(packet.Timestamp.UnixNano(), 10))
+	}
+	if packet.Level != nil {
+		m.StringProperty(OTelSeverityTextKey, packet.Level.HumanReadable(ADJ_NONE))
+	}
+	m.IntProperty(OTelSeverityNumberKey, int64(OTelSeverityNumber(packet.Level)))
+	m.StringProperty(OTelBodyKey, messageText(packet))
+	if enc.Resource != nil && !IsEmptyStructure(enc.Resource) {
+		resource := m.MapProperty(OTelResourceKey)
+		putInline(enc.Resource, resource, nil)
+		resource.EndMap()
+	}
+	scope := enc.Scope
+	if len(scope) == 0 && packet.Source != nil {
+		scope = packet.Source.StringSource()
+	}
+	if len(scope) > 0 {
+		scopeMap := m.MapProperty(OTelScopeKey)
+		scopeMap.StringProperty(OTelScopeNameKey, scope)
+		scopeMap.EndMap()
+	}
+	attributes := m.MapProperty(OTelAttributesKey)
+	taken := make(map[string]bool)
+	if packet.Message != nil {
+		CollectTopLevelKeys(packet.Message, taken)
+		putInline(packet.Message, attributes, nil)
+	}
+	if packet.Source != nil && len(enc.Scope) > 0 && !taken["code.namespace"] {
+		attributes.StringProperty("code.namespace", packet.Source.StringSource())
+	}
+	if errMsg, ok := FindMessage[*ErrorMessage](packet.Message); ok && errMsg.Err != nil {
+		if !taken["exception.type"] {
+			attributes.StringProperty("exception.type", fmt.Sprintf("%T", errMsg.Err))
+		}
+		if !taken["exception.message"] {
+			attributes.StringProperty("exception.message", errMsg.Err.Error())
+		}
+	}
+	if len(packet.Stack) > 0 && !taken["exception.stacktrace"] {
+		attributes.StringProperty("exception.stacktrace", stackText(packet.Stack))
+	}
+	attributes.EndMap()
+	m.EndMap()
+	return sink.ToString()
+}
+
+var _ PacketEncoder = &GELFEncoder{}
+var _ TextFormatter = &GELFEncoder{}
+var _ PacketEncoder = &ECSEncoder{}
+var _ TextFormatter = &ECSEncoder{}
+var _ PacketEncoder = &OTelEncoder{}
+var _ TextFormatter = &OTelEncoder{}
@@ -0,0 +1,119 @@
+package golog
+
+import (
+	"time"
+	"errors"
+	"testing"
+)
+
+func encoderPacket() *Packet {
+	details := MapValue()
+	details.Set("user", StringValue("bob"))
+	details.Set("message", StringValue("shadow"))
+	return &Packet {
+		Level: ERROR,
+		Source: TextSource("app.db"),
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 500000, time.UTC),
+		Message: &StringMessage {
+			Text: []string { "query failed", "retrying" },
+			Details: details,
+		},
+		Stack: StackTrace {
+			{
+				Function: "main.run",
+				File: "main.go",
+				Line: 3,
+			},
+		},
+	}
+}
+
+func decodeEncoded(t *testing.T, text string) *Value {
+	t.Helper()
+	value, err := DecodeJSON([]byte(text))
+	if err != nil {
+		t.Fatalf("%s: %v", text, err)
+	}
+	return value
+}
+
+func expectField(t *testing.T, value *Value, want any, path ...string) {
+	t.Helper()
+	field := value.LookupPath(path)
+	if field == nil {
+		t.Errorf("%s missing in %v", path, value.ToAny())
+		return
+	}
+	if got := field.ToAny(); got != want {
+		t.Errorf("%s: got %#v, want %#v", path, got, want)
+	}
+}
+
+func TestJSONPacketEncoderRoundTrip(t *testing.T) {
+	packet := encoderPacket()
+	text := JSONPacketEncoder{}.PacketToText(packet)[0]
+	decoded, err := PacketFromValue(decodeEncoded(t, text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Level != ERROR || decoded.Source.StringSource() != "app.db" {
+		t.Fatalf("header %v %v", decoded.Level, decoded.Source)
+	}
+	if !decoded.Timestamp.Equal(packet.Timestamp) || len(decoded.Stack) != 1 {
+		t.Fatalf("timestamp %v stack %v", decoded.Timestamp, decoded.Stack)
+	}
+	if got := CaptureValue(decoded.Message).Field("user"); got == nil || got.String != "bob" {
+		t.Fatalf("details lost: %s", text)
+	}
+}
+
+func TestGELFEncoder(t *testing.T) {
+	value := decodeEncoded(t, (&GELFEncoder {
+		Host: "h1",
+		IncludeStack: true,
+	}).PacketToText(encoderPacket())[0])
+	expectField(t, value, GELFVersion, "version")
+	expectField(t, value, "h1", "host")
+	expectField(t, value, "query failed", "short_message")
+	expectField(t, value, "query failed\nretrying\nmain.run (main.go:3)", "full_message")
+	expectField(t, value, int64(3), "level")
+	expectField(t, value, "ERROR", "_level_name")
+	expectField(t, value, "bob", "_user")
+	expectField(t, value, 1704164645.0005, "timestamp")
+}
+
+func TestECSEncoderKeepsReservedKeys(t *testing.T) {
+	value := decodeEncoded(t, (&ECSEncoder{}).PacketToText(encoderPacket())[0])
+	expectField(t, value, "query failed\nretrying", "message")
+	expectField(t, value, "error", "log.level")
+	expectField(t, value, "app.db", "log.logger")
+	expectField(t, value, "bob", "user")
+	nested := decodeEncoded(t, (&ECSEncoder {
+		FieldsKey: "labels",
+	}).PacketToText(encoderPacket())[0])
+	expectField(t, nested, "shadow", "labels", "message")
+	expectField(t, nested, "query failed\nretrying", "message")
+}
+
+func TestOTelEncoder(t *testing.T) {
+	packet := encoderPacket()
+	packet.Message = NewErrorMessage(errors.New("connection reset"), nil)
+	value := decodeEncoded(t, (&OTelEncoder {
+		Scope: "db",
+	}).PacketToText(packet)[0])
+	expectField(t, value, int64(17), OTelSeverityNumberKey)
+	expectField(t, value, "ERROR", OTelSeverityTextKey)
+	expectField(t, value, "db", OTelScopeKey, OTelScopeNameKey)
+	expectField(t, value, "app.db", OTelAttributesKey, "code.namespace")
+	expectField(t, value, "connection reset", OTelAttributesKey, "exception.message")
+	expectField(t, value, "*errors.errorString", OTelAttributesKey, "exception.type")
+}
+
+func TestSeverityHelpers(t *testing.T) {
+	if got := SyslogSeverity(WARNING); got != 4 {
+		t.Errorf("syslog WARNING = %d", got)
+	}
+	if got := OTelSeverityNumber(nil); got != OTelLevels.Entries[0].Foreign {
+		t.Errorf("OTel nil level = %d", got)
+	}
+}
@@ -0,0 +1,494 @@
+package golog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	BoundListKey = "details"
+	BadFieldKey = "!BADKEY"
+)
+
+type boundField struct {
+	key string
+	value any
+	structure Structure
+	shadowed map[string]bool
+}
+
+func(field *boundField) shadow(key string) {
+	if field.shadowed == nil {
+		field.shadowed = make(map[string]bool)
+	}
+	field.shadowed[key] = true
+}
+
+func deriveFields(parent []boundField, extra ...boundField) []boundField {
+	fields := make([]boundField, 0, len(parent) + len(extra))
+	fields = append(fields, parent...)
+	fields = append(fields, extra...)
+	taken := make(map[string]bool)
+	for index := len(fields) - 1; index >= 0; index-- {
+		field := &fields[index]
+		field.shadowed = nil
+		if field.structure == nil {
+			if taken[field.key] {
+				field.shadow(field.key)
+			}
+			taken[field.key] = true
+			continue
+		}
+		keys := make(map[string]bool)
+		CollectTopLevelKeys(field.structure, keys)
+		for key := range keys {
+			if taken[key] {
+				field.shadow(key)
+			}
+			taken[key] = true
+		}
+	}
+	return fields
+}
+
+func pairsToFields(pairs []any) []boundField {
+	var fields []boundField
+	for index := 0; index < len(pairs); index += 2 {
+		if index + 1 == len(pairs) {
+			fields = append(fields, boundField {
+				key: BadFieldKey,
+				value: pairs[index],
+			})
+			break
+		}
+		var key string
+		switch k := pairs[index].(type) {
+			case string:
+				key = k
+			default:
+				key = fmt.Sprint(k)
+		}
+		fields = append(fields, boundField {
+			key: key,
+			value: pairs[index + 1],
+		})
+	}
+	return fields
+}
+
+type boundMessage struct {
+	message Message
+	fields []boundField
+	keysOnce sync.Once
+	messageKeys map[string]bool
+}
+
+func bindMessage(msg Message, fields []boundField) Message {
+	if len(fields) == 0 {
+		return msg
+	}
+	return &boundMessage {
+		message: msg,
+		fields: fields,
+	}
+}
+
+func(msg *boundMessage) Lines() []string {
+	if msg.message == nil {
+		return nil
+	}
+	return msg.message.Lines()
+}
+
+func(msg *boundMessage) UnwrapMessage() Message {
+	return msg.message
+}
+
+func(msg *boundMessage) takenKeys() map[string]bool {
+	msg.keysOnce.Do(func() {
+		if msg.message != nil {
+			msg.messageKeys = make(map[string]bool)
+			CollectTopLevelKeys(msg.message, msg.messageKeys)
+		}
+	})
+	return msg.messageKeys
+}
+
+func(msg *boundMessage) PutStruct(sink StructSink) {
+	merge := &mergeSink {
+		sink: sink,
+		fields: msg.fields,
+		taken: msg.takenKeys(),
+	}
+	if msg.message != nil {
+		msg.message.PutStruct(merge)
+	}
+	if !merge.opened {
+		merge.open().EndMap()
+	} else if merge.wrapper != nil {
+		merge.wrapper.EndMap()
+	}
+}
+
+type mergeSink struct {
+	sink StructSink
+	fields []boundField
+	taken map[string]bool
+	opened bool
+	wrapper StructMap
+}
+
+func(sink *mergeSink) open() StructMap {
+	sink.opened = true
+	m := sink.sink.Map()
+	for _, field := range sink.fields {
+		if field.structure == nil {
+			if !field.shadowed[field.key] && !sink.taken[field.key] {
+				PutValueProperty(m, field.key, field.value)
+			}
+		} else {
+			field.structure.PutStruct(&inlineSink {
+				target: m,
+				drop: field.shadowed,
+				taken: sink.taken,
+			})
+		}
+	}
+	return m
+}
+
+func(sink *mergeSink) Map() StructMap {
+	if sink.opened {
+		return sink.sink.Map()
+	}
+	return sink.open()
+}
+
+func(sink *mergeSink) List() StructList {
+	if sink.opened {
+		return sink.sink.List()
+	}
+	sink.wrapper = sink.open()
+	return sink.wrapper.ListProperty(BoundListKey)
+}
+
+type inlineSink struct {
+	target StructMap
+	drop map[string]bool
+	taken map[string]bool
+	seen map[string]bool
+}
+
+func(sink *inlineSink) Map() StructMap {
+	return &inlineMap {
+		target: sink.target,
+		drop: sink.drop,
+		taken: sink.taken,
+		seen: sink.seen,
+	}
+}
+
+func(sink *inlineSink) List() StructList {
+	if sink.drop[BoundListKey] || sink.taken[BoundListKey] {
+		return discardSink{}
+	}
+	if sink.seen != nil {
+		sink.seen[BoundListKey] = true
+	}
+	return sink.target.ListProperty(BoundListKey)
+}
+
+type inlineMap struct {
+	target StructMap
+	drop map[string]bool
+	taken map[string]bool
+	seen map[string]bool
+}
+
+func(m *inlineMap) keep(name string) bool {
+	if m.drop[name] || m.taken[name] {
+		return false
+	}
+	if m.seen != nil {
+		m.seen[name] = true
+	}
+	return true
+}
+
+func(m *inlineMap) BoolProperty(name string, value bool) {
+	if m.keep(name) {
+		m.target.BoolProperty(name, value)
+	}
+}
+
+func(m *inlineMap) StringProperty(name string, value string) {
+	if m.keep(name) {
+		m.target.StringProperty(name, value)
+	}
+}
+
+func(m *inlineMap) IntProperty(name string, value int64) {
+	if m.keep(name) {
+		m.target.IntProperty(name, value)
+	}
+}
+
+func(m *inlineMap) FloatProperty(name string, value float64) {
+	if m.keep(name) {
+		m.target.FloatProperty(name, value)
+	}
+}
+
+func(m *inlineMap) TimeProperty(name string, value time.Time) {
+	if m.keep(name) {
+		PutTimeProperty(m.target, name, value)
+	}
+}
+
+func(m *inlineMap) DurationProperty(name string, value time.Duration) {
+	if m.keep(name) {
+		PutDurationProperty(m.target, name, value)
+	}
+}
+
+func(m *inlineMap) BytesProperty(name string, value []byte) {
+	if m.keep(name) {
+		PutBytesProperty(m.target, name, value)
+	}
+}
+
+func(m *inlineMap) UintProperty(name string, value uint64) {
+	if m.keep(name) {
+		PutUintProperty(m.target, name, value)
+	}
+}
+
+func(m *inlineMap) NullProperty(name string) {
+	if m.keep(name) {
+		PutNullProperty(m.target, name)
+	}
+}
+
+func(m *inlineMap) MapProperty(name string) StructMap {
+	if !m.keep(name) {
+		return discardSink{}
+	}
+	return m.target.MapProperty(name)
+}
+
+func(m *inlineMap) ListProperty(name string) StructList {
+	if !m.keep(name) {
+		return discardSink{}
+	}
+	return m.target.ListProperty(name)
+}
+
+func(m *inlineMap) EndMap() {}
+
+type propertySink struct {
+	target StructMap
+	name string
+}
+
+func(sink *propertySink) Map() StructMap {
+	return sink.target.MapProperty(sink.name)
+}
+
+func(sink *propertySink) List() StructList {
+	return sink.target.ListProperty(sink.name)
+}
+
+type discardSink struct {}
+
+func(sink discardSink) Map() StructMap {
+	return sink
+}
+
+func(sink discardSink) List() StructList {
+	return sink
+}
+
+func(sink discardSink) BoolProperty(string, bool) {}
+
+func(sink discardSink) StringProperty(string, string) {}
+
+func(sink discardSink) IntProperty(string, int64) {}
+
+func(sink discardSink) FloatProperty(string, float64) {}
+
+func(sink discardSink) TimeProperty(string, time.Time) {}
+
+func(sink discardSink) DurationProperty(string, time.Duration) {}
+
+func(sink discardSink) BytesProperty(string, []byte) {}
+
+func(sink discardSink) UintProperty(string, uint64) {}
+
+func(sink discardSink) NullProperty(string) {}
+
+func(sink discardSink) MapProperty(string) StructMap {
+	return sink
+}
+
+func(sink discardSink) ListProperty(string) StructList {
+	return sink
+}
+
+func(sink discardSink) EndMap() {}
+
+func(sink discardSink) Bool(bool) {}
+
+func(sink discardSink) String(string) {}
+
+func(sink discardSink) Int(int64) {}
+
+func(sink discardSink) Float(float64) {}
+
+func(sink discardSink) Time(time.Time) {}
+
+func(sink discardSink) Duration(time.Duration) {}
+
+func(sink discardSink) Bytes([]byte) {}
+
+func(sink discardSink) Uint(uint64) {}
+
+func(sink discardSink) Null() {}
+
+func(sink discardSink) EndList() {}
+
+type keySink struct {
+	keys map[string]bool
+	depth int
+}
+
+func CollectTopLevelKeys(structure Structure, keys map[string]bool) {
+	if structure == nil || keys == nil {
+		return
+	}
+	structure.PutStruct(&keySink {
+		keys: keys,
+	})
+}
+
+func(sink *keySink) Map() StructMap {
+	if sink.depth > 0 {
+		return discardSink{}
+	}
+	sink.depth++
+	return sink
+}
+
+func(sink *keySink) List() StructList {
+	if sink.depth == 0 {
+		sink.keys[BoundListKey] = true
+	}
+	return discardSink{}
+}
+
+func(sink *keySink) BoolProperty(name string, value bool) {
+	sink.keys[name] = true
+}
+
+func(sink *keySink) StringProperty(name string, value string) {
+	sink.keys[name] = true
+}
+
+func(sink *keySink) IntProperty(name string, value int64) {
+	sink.keys[name] = true
+}
+
+func(sink *keySink) FloatProperty(name string, value float64) {
+	sink.keys[name] = true
+}
+
+func(sink *keySink) TimeProperty(name string, value time.Time) {
+	sink.keys[name] = true
+}
+
+func(sink *keySink) DurationProperty(name string, value time.Duration) {
+	sink.keys[name] = true
+}
+
+func(sink *keySink) BytesProperty(name string, value []byte) {
+	sink.keys[name] = true
+}
+
+func(sink *keySink) UintProperty(name string, value uint64) {
+	sink.keys[name] = true
+}
+
+func(sink *keySink) NullProperty(name string) {
+	sink.keys[name] = true
+}
+
+func(sink *keySink) MapProperty(name string) StructMap {
+	sink.keys[name] = true
+	return discardSink{}
+}
+
+func(sink *keySink) ListProperty(name string) StructList {
+	sink.keys[name] = true
+	return discardSink{}
+}
+
+func(sink *keySink) EndMap() {}
+
+func PutValueProperty(m StructMap, name string, value any) {
+	switch v := value.(type) {
+		case nil:
+			PutNullProperty(m, name)
+		case bool:
+			m.BoolProperty(name, v)
+		case string:
+			m.StringProperty(name, v)
+		case int:
+			m.IntProperty(name, int64(v))
+		case int8:
+			m.IntProperty(name, int64(v))
+		case int16:
+			m.IntProperty(name, int64(v))
+		case int32:
+			m.IntProperty(name, int64(v))
+		case int64:
+			m.IntProperty(name, v)
+		case uint:
+			PutUintProperty(m, name, uint64(v))
+		case uint8:
+			PutUintProperty(m, name, uint64(v))
+		case uint16:
+			PutUintProperty(m, name, uint64(v))
+		case uint32:
+			PutUintProperty(m, name, uint64(v))
+		case uint64:
+			PutUintProperty(m, name, v)
+		case float32:
+			m.FloatProperty(name, float64(v))
+		case float64:
+			m.FloatProperty(name, v)
+		case time.Time:
+			PutTimeProperty(m, name, v)
+		case time.Duration:
+			PutDurationProperty(m, name, v)
+		case []byte:
+			PutBytesProperty(m, name, v)
+		case Structure:
+			v.PutStruct(&propertySink {
+				target: m,
+				name: name,
+			})
+		default:
+			DumbStructEncoder.PutProperty(m, name, v)
+	}
+}
+
+var _ Message = &boundMessage{}
+var _ MessageWrapper = &boundMessage{}
+var _ StructSink = &mergeSink{}
+var _ StructSink = &inlineSink{}
+var _ ExtendedStructMap = &inlineMap{}
+var _ StructSink = &propertySink{}
+var _ ExtendedStructList = discardSink{}
+var _ ExtendedStructMap = discardSink{}
+var _ StructSink = &keySink{}
+var _ ExtendedStructMap = &keySink{}
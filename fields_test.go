@@ -0,0 +1,154 @@
+package golog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBoundLogWithAccumulatesFields(t *testing.T) {
+	sink := newCaptureLogger()
+	root := &BoundLog {
+		Logger: sink,
+	}
+	derived := root.With("request", "r1").With("user", "alice")
+	derived.Infov(nil, "hello")
+	got := sink.Last().Details.ToMap()
+	want := map[string]any {
+		"request": "r1",
+		"user": "alice",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if lines := sink.Last().Lines; len(lines) != 1 || lines[0] != "hello" {
+		t.Fatalf("unexpected lines %q", lines)
+	}
+}
+
+func TestBoundLogDerivationsDoNotShareFields(t *testing.T) {
+	sink := newCaptureLogger()
+	parent := (&BoundLog {
+		Logger: sink,
+	}).With("a", "1")
+	left := parent.With("b", "left")
+	right := parent.With("b", "right")
+	left.Infov(nil, "x")
+	if got := sink.Last().Details.Field("b").String; got != "left" {
+		t.Fatalf("left derivation saw b=%q", got)
+	}
+	right.Infov(nil, "x")
+	if got := sink.Last().Details.Field("b").String; got != "right" {
+		t.Fatalf("right derivation saw b=%q", got)
+	}
+	parent.Infov(nil, "x")
+	if sink.Last().Details.Field("b") != nil {
+		t.Fatal("parent picked up a field bound by a child")
+	}
+}
+
+func TestBoundLogOverrideOrder(t *testing.T) {
+	sink := newCaptureLogger()
+	log := (&BoundLog {
+		Logger: sink,
+	}).With("k", "outer", "keep", "yes").With("k", "inner")
+	log.Infov(nil, "x")
+	details := sink.Last().Details
+	if got := details.Field("k").String; got != "inner" {
+		t.Fatalf("later With should win, got %q", got)
+	}
+	if len(details.Fields) != 2 {
+		t.Fatalf("duplicate key emitted twice: %v", details.ToMap())
+	}
+	call := MapValue()
+	call.Set("k", StringValue("call"))
+	log.Infov(call, "x")
+	details = sink.Last().Details
+	if got := details.Field("k").String; got != "call" {
+		t.Fatalf("per-call details should win, got %q", got)
+	}
+	if got := details.Field("keep").String; got != "yes" {
+		t.Fatalf("unrelated bound field lost, got %q", got)
+	}
+}
+
+func TestBoundLogWithStructure(t *testing.T) {
+	sink := newCaptureLogger()
+	bound := MapValue()
+	bound.Set("service", StringValue("api"))
+	bound.Set("k", StringValue("structure"))
+	log := (&BoundLog {
+		Logger: sink,
+	}).WithStructure(bound).With("k", "pair")
+	log.Infov(nil, "x")
+	details := sink.Last().Details
+	if details.Field("service").String != "api" || details.Field("k").String != "pair" {
+		t.Fatalf("unexpected details %v", details.ToMap())
+	}
+	if (&BoundLog{}).WithStructure(nil) == nil {
+		t.Fatal("WithStructure(nil) returned nil")
+	}
+}
+
+func TestBoundLogListDetailsAreWrapped(t *testing.T) {
+	sink := newCaptureLogger()
+	log := (&BoundLog {
+		Logger: sink,
+	}).With("k", "v")
+	log.Infov(ListValue(StringValue("a"), StringValue("b")), "x")
+	details := sink.Last().Details
+	list := details.Field(BoundListKey)
+	if list == nil || list.Kind != VAL_LIST || len(list.Items) != 2 {
+		t.Fatalf("list details not wrapped under %q: %v", BoundListKey, details.ToAny())
+	}
+	if details.Field("k").String != "v" {
+		t.Fatalf("bound field missing: %v", details.ToAny())
+	}
+}
+
+func TestBoundLogOddPairs(t *testing.T) {
+	sink := newCaptureLogger()
+	(&BoundLog {
+		Logger: sink,
+	}).With("dangling", 42, "last").Infov(nil, "x")
+	details := sink.Last().Details
+	if dangling := details.Field("dangling"); dangling == nil || dangling.Int != 42 {
+		t.Fatalf("pair not bound as key/value: %v", details.ToAny())
+	}
+	if details.Field("last") != nil {
+		t.Fatalf("dangling key bound as a field: %v", details.ToAny())
+	}
+	if bad := details.Field(BadFieldKey); bad == nil || bad.String != "last" {
+		t.Fatalf("dangling key not marked under %q: %v", BadFieldKey, details.ToAny())
+	}
+}
+
+type countingMessage struct {
+	StringMessage
+	emits int
+}
+
+func(msg *countingMessage) PutStruct(sink StructSink) {
+	msg.emits++
+	msg.StringMessage.PutStruct(sink)
+}
+
+func TestBoundMessageCollectsKeysOnce(t *testing.T) {
+	details := MapValue()
+	details.Set("k", StringValue("call"))
+	inner := &countingMessage {
+		StringMessage: StringMessage {
+			Text: []string { "x" },
+			Details: details,
+		},
+	}
+	msg := bindMessage(inner, deriveFields(nil, pairsToFields([]any { "k", "bound", "other", 1 })...))
+	for round := 0; round < 3; round++ {
+		value := CaptureValue(msg)
+		if value.Field("k").String != "call" || value.Field("other").Int != 1 || len(value.Fields) != 2 {
+			t.Fatalf("round %d: %v", round, value.ToAny())
+		}
+	}
+	if inner.emits != 4 {
+		t.Fatalf("wrapped PutStruct ran %d times for 3 emits, want 4", inner.emits)
+	}
+}
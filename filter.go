@@ -0,0 +1,627 @@
+package golog
+
+import (
+	"fmt"
+	"time"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	FilterLevelField = "level"
+	FilterSourceField = "source"
+	FilterMessageField = "msg"
+	FilterTimeField = "time"
+	FilterNominalField = "nominal"
+	FilterDetailsPrefix = "details."
+)
+
+var FilterTimeFormats = []string {
+	time.RFC3339Nano,
+	DefaultLayoutTimeFormat,
+	"2006-01-02T15:04:05",
+	time.DateTime,
+	time.DateOnly,
+}
+
+type FilterError struct {
+	Expression string
+	Offset int
+	Reason string
+}
+
+func(err *FilterError) Error() string {
+	return fmt.Sprintf("invalid filter %q at offset %d: %s", err.Expression, err.Offset, err.Reason)
+}
+
+func ParseFilterTime(spec string) (time.Time, error) {
+	var firstErr error
+	for _, format := range FilterTimeFormats {
+		stamp, err := time.ParseInLocation(format, spec, time.Local)
+		if err == nil {
+			return stamp, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return time.Time{}, firstErr
+}
+
+type filterOp uint
+
+const (
+	opTruthy filterOp = iota
+	opEqual
+	opNotEqual
+	opLess
+	opLessEqual
+	opGreater
+	opGreaterEqual
+	opMatch
+	opNotMatch
+	opContains
+)
+
+type filterComparison struct {
+	field string
+	path []string
+	op filterOp
+	operand *Value
+	pattern *regexp.Regexp
+}
+
+func(pred *filterComparison) Match(packet *Packet) bool {
+	if packet == nil {
+		return false
+	}
+	subject := filterSubject(packet, pred.field, pred.path)
+	if pred.field == FilterLevelField && packet.Level != nil && pred.op >= opMatch {
+		subject = StringValue(packet.Level.HumanReadable(ADJ_NONE))
+	}
+	switch pred.op {
+		case opTruthy:
+			return filterTruthy(subject)
+		case opMatch:
+			return subject != nil && pred.pattern.MatchString(filterText(subject))
+		case opNotMatch:
+			return subject == nil || !pred.pattern.MatchString(filterText(subject))
+		case opContains:
+			return subject != nil && strings.Contains(filterText(subject), filterText(pred.operand))
+	}
+	if pred.operand.Kind == VAL_NULL || subject == nil || subject.Kind == VAL_NULL {
+		isNull := subject == nil || subject.Kind == VAL_NULL
+		switch pred.op {
+			case opEqual:
+				return isNull == (pred.operand.Kind == VAL_NULL)
+			case opNotEqual:
+				return isNull != (pred.operand.Kind == VAL_NULL)
+			default:
+				return false
+		}
+	}
+	order, ok := compareValues(subject, pred.operand)
+	if !ok {
+		return pred.op == opNotEqual
+	}
+	switch pred.op {
+		case opEqual:
+			return order == 0
+		case opNotEqual:
+			return order != 0
+		case opLess:
+			return order < 0
+		case opLessEqual:
+			return order <= 0
+		case opGreater:
+			return order > 0
+		default:
+			return order >= 0
+	}
+}
+
+func(pred *filterComparison) MatchEnabled(level Level, source Source) (bool, bool) {
+	switch pred.field {
+		case FilterLevelField, FilterSourceField, FilterNominalField:
+			return pred.Match(&Packet {
+				Level: level,
+				Source: source,
+			}), true
+		default:
+			return false, false
+	}
+}
+
+func filterSubject(packet *Packet, field string, path []string) *Value {
+	switch field {
+		case FilterLevelField:
+			if packet.Level == nil {
+				return nil
+			}
+			return IntValue(int64(packet.Level.Numerical()))
+		case FilterSourceField:
+			if packet.Source == nil {
+				return nil
+			}
+			return StringValue(packet.Source.StringSource())
+		case FilterMessageField:
+			if packet.Message == nil {
+				return nil
+			}
+			return StringValue(strings.Join(packet.Message.Lines(), "\n"))
+		case FilterTimeField:
+			if packet.Timestamp.IsZero() {
+				return nil
+			}
+			return TimeValue(packet.Timestamp)
+		case FilterNominalField:
+			if packet.Level == nil {
+				return nil
+			}
+			return BoolValue(packet.Level.IsNominal())
+	}
+	return detailsValue(packet.Message).LookupPath(path)
+}
+
+func detailsValue(msg Message) *Value {
+	if plain, ok := msg.(*StringMessage); ok {
+		if value, ok := plain.Details.(*Value); ok {
+			return value
+		}
+	}
+	return CaptureValue(msg)
+}
+
+func filterTruthy(value *Value) bool {
+	if value == nil {
+		return false
+	}
+	switch value.Kind {
+		case VAL_NULL:
+			return false
+		case VAL_BOOL:
+			return value.Bool
+		case VAL_STRING:
+			return len(value.String) > 0
+		case VAL_INT:
+			return value.Int != 0
+		case VAL_UINT:
+			return value.Uint != 0
+		case VAL_FLOAT:
+			return value.Float != 0
+		case VAL_MAP:
+			return len(value.Fields) > 0
+		case VAL_LIST:
+			return len(value.Items) > 0
+		default:
+			return true
+	}
+}
+
+func filterText(value *Value) string {
+	switch value.Kind {
+		case VAL_MAP, VAL_LIST:
+			return TextStructFormatter{}.StructToText(value)
+		default:
+			return FormatScalarValue(value)
+	}
+}
+
+func numericValue(value *Value) (float64, bool) {
+	switch value.Kind {
+		case VAL_INT:
+			return float64(value.Int), true
+		case VAL_UINT:
+			return float64(value.Uint), true
+		case VAL_FLOAT:
+			return value.Float, true
+		case VAL_DURATION:
+			return float64(value.Duration), true
+		case VAL_STRING:
+			number, err := strconv.ParseFloat(value.String, 64)
+			return number, err == nil
+		default:
+			return 0, false
+	}
+}
+
+func compareValues(subject *Value, operand *Value) (int, bool) {
+	switch subject.Kind {
+		case VAL_TIME:
+			var stamp time.Time
+			switch operand.Kind {
+				case VAL_TIME:
+					stamp = operand.Time
+				case VAL_STRING:
+					parsed, err := ParseFilterTime(operand.String)
+					if err != nil {
+						return 0, false
+					}
+					stamp = parsed
+				default:
+					return 0, false
+			}
+			return subject.Time.Compare(stamp), true
+		case VAL_DURATION:
+			if operand.Kind == VAL_STRING {
+				duration, err := time.ParseDuration(operand.String)
+				if err != nil {
+					return 0, false
+				}
+				return compareOrdered(subject.Duration, duration), true
+			}
+		case VAL_BOOL:
+			if operand.Kind != VAL_BOOL {
+				return 0, false
+			}
+			if subject.Bool == operand.Bool {
+				return 0, true
+			}
+			if subject.Bool {
+				return 1, true
+			}
+			return -1, true
+		case VAL_STRING:
+			if operand.Kind == VAL_STRING {
+				return strings.Compare(subject.String, operand.String), true
+			}
+	}
+	left, leftOK := numericValue(subject)
+	right, rightOK := numericValue(operand)
+	if leftOK && rightOK {
+		return compareOrdered(left, right), true
+	}
+	return strings.Compare(filterText(subject), filterText(operand)), true
+}
+
+func compareOrdered[T int | int64 | float64 | time.Duration](left T, right T) int {
+	switch {
+		case left < right:
+			return -1
+		case left > right:
+			return 1
+		default:
+			return 0
+	}
+}
+
+type filterTokenKind uint
+
+const (
+	tokEnd filterTokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOperator
+	tokOpenParen
+	tokCloseParen
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+	offset int
+}
+
+type filterParser struct {
+	expression string
+	pos int
+	token filterToken
+}
+
+func(parser *filterParser) fail(offset int, format string, args ...any) error {
+	return &FilterError {
+		Expression: parser.expression,
+		Offset: offset,
+		Reason: fmt.Sprintf(format, args...),
+	}
+}
+
+func isFilterIdentRune(r rune, first bool) bool {
+	if unicode.IsLetter(r) || r == '_' || r == '@' {
+		return true
+	}
+	return !first && (unicode.IsDigit(r) || r == '.' || r == '-')
+}
+
+func(parser *filterParser) next() error {
+	for parser.pos < len(parser.expression) && parser.expression[parser.pos] <= ' ' {
+		parser.pos++
+	}
+	start := parser.pos
+	parser.token = filterToken {
+		offset: start,
+	}
+	if parser.pos >= len(parser.expression) {
+		return nil
+	}
+	rest := parser.expression[parser.pos:]
+	for _, op := range []string { "&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "<", ">", "!", "=" } {
+		if strings.HasPrefix(rest, op) {
+			parser.pos += len(op)
+			parser.token.kind = tokOperator
+			parser.token.text = op
+			if op == "=" {
+				parser.token.text = "=="
+			}
+			return nil
+		}
+	}
+	c := rest[0]
+	switch {
+		case c == '(':
+			parser.pos++
+			parser.token.kind = tokOpenParen
+		case c == ')':
+			parser.pos++
+			parser.token.kind = tokCloseParen
+		case c == '"' || c == '\'' || c == '`':
+			end := parser.pos + 1
+			for end < len(parser.expression) && parser.expression[end] != c {
+				if parser.expression[end] == '\\' && c != '`' {
+					end++
+				}
+				end++
+			}
+			if end >= len(parser.expression) {
+				return parser.fail(start, "unterminated string")
+			}
+			raw := parser.expression[parser.pos:end + 1]
+			parser.pos = end + 1
+			if c == '\'' {
+				raw = "\"" + strings.ReplaceAll(strings.ReplaceAll(raw[1:len(raw) - 1], "\\'", "'"), "\"", "\\\"") + "\""
+			}
+			text, err := strconv.Unquote(raw)
+			if err != nil {
+				return parser.fail(start, "malformed string literal")
+			}
+			parser.token.kind = tokString
+			parser.token.text = text
+		case c == '-' || c == '+' || c >= '0' && c <= '9':
+			end := parser.pos + 1
+			for end < len(parser.expression) && strings.IndexByte("0123456789.:eE+-hmsunTZ", parser.expression[end]) >= 0 {
+				end++
+			}
+			parser.token.kind = tokNumber
+			parser.token.text = parser.expression[parser.pos:end]
+			parser.pos = end
+		default:
+			r, _ := utf8.DecodeRuneInString(rest)
+			if !isFilterIdentRune(r, true) {
+				return parser.fail(start, "unexpected character %q", r)
+			}
+			end := parser.pos
+			for end < len(parser.expression) {
+				r, size := utf8.DecodeRuneInString(parser.expression[end:])
+				if !isFilterIdentRune(r, end == parser.pos) {
+					break
+				}
+				end += size
+			}
+			parser.token.kind = tokIdent
+			parser.token.text = parser.expression[parser.pos:end]
+			parser.pos = end
+	}
+	return nil
+}
+
+func(parser *filterParser) isKeyword(word string) bool {
+	return parser.token.kind == tokIdent && strings.EqualFold(parser.token.text, word)
+}
+
+func(parser *filterParser) parseOr() (Predicate[*Packet], error) {
+	first, err := parser.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []Predicate[*Packet] { first }
+	for parser.token.kind == tokOperator && parser.token.text == "||" || parser.isKeyword("or") {
+		if err := parser.next(); err != nil {
+			return nil, err
+		}
+		child, err := parser.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return AnyPredicate[*Packet] {
+		Children: children,
+	}, nil
+}
+
+func(parser *filterParser) parseAnd() (Predicate[*Packet], error) {
+	first, err := parser.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	children := []Predicate[*Packet] { first }
+	for parser.token.kind == tokOperator && parser.token.text == "&&" || parser.isKeyword("and") {
+		if err := parser.next(); err != nil {
+			return nil, err
+		}
+		child, err := parser.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return AllPredicate[*Packet] {
+		Children: children,
+	}, nil
+}
+
+func(parser *filterParser) parseUnary() (Predicate[*Packet], error) {
+	switch {
+		case parser.token.kind == tokOperator && parser.token.text == "!" || parser.isKeyword("not"):
+			if err := parser.next(); err != nil {
+				return nil, err
+			}
+			child, err := parser.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			return NonePredicate[*Packet] {
+				Children: []Predicate[*Packet] { child },
+			}, nil
+		case parser.token.kind == tokOpenParen:
+			open := parser.token.offset
+			if err := parser.next(); err != nil {
+				return nil, err
+			}
+			inner, err := parser.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if parser.token.kind != tokCloseParen {
+				return nil, parser.fail(open, "unbalanced '('")
+			}
+			if err := parser.next(); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		case parser.token.kind == tokIdent:
+			return parser.parseComparison()
+		case parser.token.kind == tokEnd:
+			return nil, parser.fail(parser.token.offset, "unexpected end of filter")
+		default:
+			return nil, parser.fail(parser.token.offset, "expected field name")
+	}
+}
+
+func(parser *filterParser) parseComparison() (Predicate[*Packet], error) {
+	pred := &filterComparison {
+		field: parser.token.text,
+	}
+	switch strings.ToLower(pred.field) {
+		case "message":
+			pred.field = FilterMessageField
+		case "timestamp":
+			pred.field = FilterTimeField
+		case FilterLevelField, FilterSourceField, FilterMessageField, FilterTimeField, FilterNominalField:
+			pred.field = strings.ToLower(pred.field)
+		default:
+			pred.path = strings.Split(strings.TrimPrefix(pred.field, FilterDetailsPrefix), ".")
+	}
+	if err := parser.next(); err != nil {
+		return nil, err
+	}
+	switch {
+		case parser.token.kind == tokOperator:
+			switch parser.token.text {
+				case "==":
+					pred.op = opEqual
+				case "!=":
+					pred.op = opNotEqual
+				case "<":
+					pred.op = opLess
+				case "<=":
+					pred.op = opLessEqual
+				case ">":
+					pred.op = opGreater
+				case ">=":
+					pred.op = opGreaterEqual
+				case "=~":
+					pred.op = opMatch
+				case "!~":
+					pred.op = opNotMatch
+				default:
+					return pred, nil
+			}
+		case parser.isKeyword("contains"):
+			pred.op = opContains
+		default:
+			return pred, nil
+	}
+	if err := parser.next(); err != nil {
+		return nil, err
+	}
+	operandOffset := parser.token.offset
+	switch parser.token.kind {
+		case tokString:
+			pred.operand = StringValue(parser.token.text)
+		case tokNumber:
+			if _, err := strconv.ParseFloat(parser.token.text, 64); err == nil {
+				pred.operand = numberValue(parser.token.text)
+			} else if duration, err := time.ParseDuration(parser.token.text); err == nil {
+				pred.operand = DurationValue(duration)
+			} else if _, err := ParseFilterTime(parser.token.text); err == nil {
+				pred.operand = StringValue(parser.token.text)
+			} else {
+				return nil, parser.fail(operandOffset, "malformed number %q", parser.token.text)
+			}
+		case tokIdent:
+			switch strings.ToLower(parser.token.text) {
+				case "true":
+					pred.operand = BoolValue(true)
+				case "false":
+					pred.operand = BoolValue(false)
+				case "null", "nil":
+					pred.operand = NullValue()
+				default:
+					pred.operand = StringValue(parser.token.text)
+			}
+		default:
+			return nil, parser.fail(operandOffset, "expected value after operator")
+	}
+	if err := parser.next(); err != nil {
+		return nil, err
+	}
+	switch pred.op {
+		case opMatch, opNotMatch:
+			pattern, err := regexp.Compile(filterText(pred.operand))
+			if err != nil {
+				return nil, parser.fail(operandOffset, "%s", err.Error())
+			}
+			pred.pattern = pattern
+		default:
+			if pred.field == FilterLevelField && pred.operand.Kind == VAL_STRING {
+				level, ok := Levels.Lookup(pred.operand.String)
+				if !ok {
+					return nil, parser.fail(operandOffset, "unknown level %q", pred.operand.String)
+				}
+				pred.operand = IntValue(int64(level.Numerical()))
+			}
+			if pred.field == FilterTimeField && pred.operand.Kind == VAL_STRING {
+				stamp, err := ParseFilterTime(pred.operand.String)
+				if err != nil {
+					return nil, parser.fail(operandOffset, "malformed time %q", pred.operand.String)
+				}
+				pred.operand = TimeValue(stamp)
+			}
+	}
+	return pred, nil
+}
+
+func CompileFilter(expression string) (Predicate[*Packet], error) {
+	parser := &filterParser {
+		expression: expression,
+	}
+	if err := parser.next(); err != nil {
+		return nil, err
+	}
+	if parser.token.kind == tokEnd {
+		return TruePredicate[*Packet]{}, nil
+	}
+	pred, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if parser.token.kind != tokEnd {
+		return nil, parser.fail(parser.token.offset, "unexpected %q", parser.token.text)
+	}
+	return pred, nil
+}
+
+var _ error = &FilterError{}
+var _ Predicate[*Packet] = &filterComparison{}
+var _ EnabledPredicate = &filterComparison{}
@@ -1,8 +1,10 @@
 package golog
 
 import (
+	"sync"
 	"time"
 	"strings"
+	"unicode/utf8"
 )
 
 type TextFormatter interface {
@@ -17,6 +19,14 @@ type StructFormatter interface {
 	StructToText(Structure) string
 }
 
+type AppendLineFormatter interface {
+	AppendPacketLine([]byte, *Packet) []byte
+}
+
+type AppendTextFormatter interface {
+	AppendPacketText([]byte, *Packet) []byte
+}
+
 func PacketToLine(packet *Packet, formatters []LineFormatter) string {
 	var builder strings.Builder
 	for _, formatter := range formatters {
@@ -27,6 +37,44 @@ func PacketToLine(packet *Packet, formatters []LineFormatter) string {
 	return builder.String()
 }
 
+func AppendLine(dst []byte, packet *Packet, formatter LineFormatter) []byte {
+	if formatter == nil {
+		return dst
+	}
+	if appender, ok := formatter.(AppendLineFormatter); ok {
+		return appender.AppendPacketLine(dst, packet)
+	}
+	var builder strings.Builder
+	formatter.PacketToLine(packet, &builder)
+	return append(dst, builder.String()...)
+}
+
+func AppendPacketLine(dst []byte, packet *Packet, formatters []LineFormatter) []byte {
+	for _, formatter := range formatters {
+		dst = AppendLine(dst, packet, formatter)
+	}
+	return dst
+}
+
+func AppendPacketText(dst []byte, packet *Packet, formatter TextFormatter) []byte {
+	if appender, ok := formatter.(AppendTextFormatter); ok {
+		return appender.AppendPacketText(dst, packet)
+	}
+	var lines []string
+	if formatter == nil {
+		if packet.Message != nil {
+			lines = packet.Message.Lines()
+		}
+	} else {
+		lines = formatter.PacketToText(packet)
+	}
+	for _, line := range lines {
+		dst = append(dst, line...)
+		dst = append(dst, '\n')
+	}
+	return dst
+}
+
 type MessageTextFormatter struct {}
 
 func(form MessageTextFormatter) PacketToText(packet *Packet) []string {
@@ -37,6 +85,50 @@ func(form MessageTextFormatter) PacketToText(packet *Packet) []string {
 	}
 }
 
+type CauseTextFormatter struct {
+	Indent string
+	CausePrefix string
+}
+
+func(form CauseTextFormatter) PacketToText(packet *Packet) []string {
+	if packet.Message == nil {
+		return nil
+	}
+	causal, ok := FindMessage[CausalMessage](packet.Message)
+	if !ok {
+		return packet.Message.Lines()
+	}
+	indent := form.Indent
+	if len(indent) == 0 {
+		indent = DefaultCauseIndent
+	}
+	return CausesToLines(causal.Summary(), causal.Causes(), indent, form.CausePrefix)
+}
+
+type StackTextFormatter struct {
+	Indent string
+	MaxFrames int
+}
+
+func(form StackTextFormatter) PacketToText(packet *Packet) []string {
+	if len(packet.Stack) == 0 {
+		return nil
+	}
+	indent := form.Indent
+	if len(indent) == 0 {
+		indent = DefaultStackIndent
+	}
+	frames := packet.Stack
+	if form.MaxFrames > 0 && len(frames) > form.MaxFrames {
+		frames = frames[:form.MaxFrames]
+	}
+	lines := make([]string, 0, len(frames))
+	for index := range frames {
+		lines = append(lines, indent + frames[index].String())
+	}
+	return lines
+}
+
 type ConcatTextFormatter struct {
 	Formatters []TextFormatter
 }
@@ -76,6 +168,17 @@ func(form LineTextFormatter) PacketToText(packet *Packet) []string {
 	return all
 }
 
+func(form LineTextFormatter) AppendPacketText(dst []byte, packet *Packet) []byte {
+	for _, outer := range form.Formatters {
+		if outer == nil {
+			continue
+		}
+		dst = AppendPacketLine(dst, packet, outer)
+		dst = append(dst, '\n')
+	}
+	return dst
+}
+
 type PrefixMode uint
 
 const (
@@ -107,7 +210,7 @@ func(form *PrefixedTextFormatter) PacketToText(packet *Packet) []string {
 		case PFX_ALL_SAME:
 			restPrefix = topPrefix
 		case PFX_THEN_SPACES:
-			restPrefix = string(RepeatRune(' ', len(topPrefix)))
+			restPrefix = string(RepeatRune(' ', TextWidth(topPrefix)))
 	}
 	for index := 0; index < len(lines); index++ {
 		if index == 0 {
@@ -131,6 +234,10 @@ func(form ConcatLineFormatter) PacketToLine(packet *Packet, builder *strings.Bui
 	}
 }
 
+func(form ConcatLineFormatter) AppendPacketLine(dst []byte, packet *Packet) []byte {
+	return AppendPacketLine(dst, packet, form.Formatters)
+}
+
 type StringLineFormatter struct {
 	Value string
 }
@@ -139,6 +246,10 @@ func(form StringLineFormatter) PacketToLine(packet *Packet, builder *strings.Bui
 	builder.WriteString(form.Value)
 }
 
+func(form StringLineFormatter) AppendPacketLine(dst []byte, packet *Packet) []byte {
+	return append(dst, form.Value...)
+}
+
 type AffixFlags uint
 
 const (
@@ -194,6 +305,46 @@ func(form *PieceLineFormatterBase) WithString(rendition string, packet *Packet,
 	}
 }
 
+func(form *PieceLineFormatterBase) AppendMissing(dst []byte, packet *Packet) []byte {
+	if form.Prefix != nil && form.Flags & AFF_PREFIX_IF_MISSING != 0 {
+		dst = AppendLine(dst, packet, form.Prefix)
+	}
+	dst = AppendLine(dst, packet, form.ReplacementIfMissing)
+	if form.Suffix != nil && form.Flags & AFF_SUFFIX_IF_MISSING != 0 {
+		dst = AppendLine(dst, packet, form.Suffix)
+	}
+	return dst
+}
+
+func(form *PieceLineFormatterBase) AppendEmpty(dst []byte, packet *Packet) []byte {
+	if form.Prefix != nil && form.Flags & AFF_PREFIX_IF_EMPTY != 0 {
+		dst = AppendLine(dst, packet, form.Prefix)
+	}
+	dst = AppendLine(dst, packet, form.ReplacementIfEmpty)
+	if form.Suffix != nil && form.Flags & AFF_SUFFIX_IF_EMPTY != 0 {
+		dst = AppendLine(dst, packet, form.Suffix)
+	}
+	return dst
+}
+
+func(form *PieceLineFormatterBase) AppendWithString(dst []byte, rendition string, packet *Packet) []byte {
+	if len(rendition) == 0 {
+		return form.AppendEmpty(dst, packet)
+	}
+	dst = AppendLine(dst, packet, form.Prefix)
+	dst = append(dst, rendition...)
+	return AppendLine(dst, packet, form.Suffix)
+}
+
+func(form *PieceLineFormatterBase) AppendWithBytes(dst []byte, rendition []byte, packet *Packet) []byte {
+	if len(rendition) == 0 {
+		return form.AppendEmpty(dst, packet)
+	}
+	dst = AppendLine(dst, packet, form.Prefix)
+	dst = append(dst, rendition...)
+	return AppendLine(dst, packet, form.Suffix)
+}
+
 type GenericLevelLineFormatter struct {
 	PieceLineFormatterBase
 	Adjustment Adjustment
@@ -207,6 +358,13 @@ func(form *GenericLevelLineFormatter) PacketToLine(packet *Packet, builder *stri
 	}
 }
 
+func(form *GenericLevelLineFormatter) AppendPacketLine(dst []byte, packet *Packet) []byte {
+	if packet.Level == nil {
+		return form.AppendMissing(dst, packet)
+	}
+	return form.AppendWithString(dst, packet.Level.HumanReadable(form.Adjustment), packet)
+}
+
 type GenericSourceLineFormatter struct {
 	PieceLineFormatterBase
 }
@@ -219,6 +377,105 @@ func(form *GenericSourceLineFormatter) PacketToLine(packet *Packet, builder *str
 	}
 }
 
+func(form *GenericSourceLineFormatter) AppendPacketLine(dst []byte, packet *Packet) []byte {
+	if packet.Source == nil {
+		return form.AppendMissing(dst, packet)
+	}
+	if appender, ok := packet.Source.(AppendSource); ok {
+		start := len(dst)
+		dst = AppendLine(dst, packet, form.Prefix)
+		sourceStart := len(dst)
+		dst = appender.AppendStringSource(dst)
+		if len(dst) == sourceStart {
+			return form.AppendEmpty(dst[:start], packet)
+		}
+		return AppendLine(dst, packet, form.Suffix)
+	}
+	return form.AppendWithString(dst, packet.Source.StringSource(), packet)
+}
+
+type SourceParts uint
+
+const (
+	SRC_MODULE SourceParts = 1 << iota
+	SRC_TYPE
+	SRC_FUNCTION
+	SRC_ALL = SRC_MODULE | SRC_TYPE | SRC_FUNCTION
+)
+
+type AbbreviatedSourceLineFormatter struct {
+	PieceLineFormatterBase
+	TargetLength int
+	Parts SourceParts
+	Separator string
+}
+
+func(form *AbbreviatedSourceLineFormatter) PacketToLine(packet *Packet, builder *strings.Builder) {
+	if packet.Source == nil {
+		form.Missing(packet, builder)
+	} else {
+		separator := form.Separator
+		if len(separator) == 0 {
+			separator = "."
+		}
+		segments := form.segments(packet.Source, separator)
+		form.WithString(AbbreviateSegments(segments, separator, form.TargetLength), packet, builder)
+	}
+}
+
+func(form *AbbreviatedSourceLineFormatter) segments(src Source, separator string) []string {
+	parts := form.Parts
+	if parts == 0 {
+		parts = SRC_ALL
+	}
+	var segments []string
+	add := func(text string) {
+		for _, segment := range strings.Split(text, separator) {
+			if len(segment) > 0 {
+				segments = append(segments, segment)
+			}
+		}
+	}
+	if def, ok := src.(*DefaultSource); ok {
+		if def == nil {
+			return nil
+		}
+		if parts & SRC_MODULE != 0 {
+			add(def.Module)
+		}
+		if parts & SRC_TYPE != 0 {
+			add(def.Type)
+		}
+		if parts & SRC_FUNCTION != 0 {
+			add(def.Function)
+		}
+	} else {
+		add(src.StringSource())
+	}
+	return segments
+}
+
+func AbbreviateSegments(segments []string, separator string, targetLength int) string {
+	if targetLength > 0 && len(segments) > 1 {
+		total := len(separator) * (len(segments) - 1)
+		for _, segment := range segments {
+			total += TextWidth(segment)
+		}
+		abbreviated := make([]string, len(segments))
+		copy(abbreviated, segments)
+		for index := 0; index < len(abbreviated) - 1 && total > targetLength; index++ {
+			r, size := utf8.DecodeRuneInString(abbreviated[index])
+			if size == len(abbreviated[index]) {
+				continue
+			}
+			total -= TextWidth(abbreviated[index]) - RuneWidth(r)
+			abbreviated[index] = string(r)
+		}
+		segments = abbreviated
+	}
+	return strings.Join(segments, separator)
+}
+
 type GenericTimestampLineFormatter struct {
 	PieceLineFormatterBase
 	Format string
@@ -236,9 +493,23 @@ func(form *GenericTimestampLineFormatter) PacketToLine(packet *Packet, builder *
 	}
 }
 
+func(form *GenericTimestampLineFormatter) AppendPacketLine(dst []byte, packet *Packet) []byte {
+	if packet.Timestamp.IsZero() {
+		return form.AppendMissing(dst, packet)
+	}
+	format := form.Format
+	if len(format) == 0 {
+		format = time.DateTime
+	}
+	dst = AppendLine(dst, packet, form.Prefix)
+	dst = packet.Timestamp.AppendFormat(dst, format)
+	return AppendLine(dst, packet, form.Suffix)
+}
+
 type GenericStructLineFormatter struct {
 	PieceLineFormatterBase
 	Formatter StructFormatter
+	IncludeStack bool
 }
 
 func(form *GenericStructLineFormatter) PacketToLine(packet *Packet, builder *strings.Builder) {
@@ -253,10 +524,51 @@ func(form *GenericStructLineFormatter) PacketToLine(packet *Packet, builder *str
 				formatter = DumbStructFormatter
 			}
 		}
-		form.WithString(formatter.StructToText(packet.Message), packet, builder)
+		var structure Structure = packet.Message
+		if form.IncludeStack && len(packet.Stack) > 0 {
+			structure = bindMessage(packet.Message, []boundField {
+				{
+					key: StackStructKey,
+					value: packet.Stack,
+				},
+			})
+		}
+		form.WithString(formatter.StructToText(structure), packet, builder)
 	}
 }
 
+func(form *GenericStructLineFormatter) AppendPacketLine(dst []byte, packet *Packet) []byte {
+	if packet.Message == nil {
+		return form.AppendMissing(dst, packet)
+	}
+	formatter := form.Formatter
+	if formatter == nil {
+		if DumbStructFormatter == nil {
+			formatter = TextStructFormatter{}
+		} else {
+			formatter = DumbStructFormatter
+		}
+	}
+	var structure Structure = packet.Message
+	if form.IncludeStack && len(packet.Stack) > 0 {
+		structure = bindMessage(packet.Message, []boundField {
+			{
+				key: StackStructKey,
+				value: packet.Stack,
+			},
+		})
+	}
+	text, ok := formatter.(TextStructFormatter)
+	if !ok {
+		return form.AppendWithString(dst, formatter.StructToText(structure), packet)
+	}
+	sink := acquireTextStructSink(text.KeepOutermostParens)
+	structure.PutStruct(sink)
+	dst = form.AppendWithBytes(dst, sink.ToBytes(), packet)
+	releaseTextStructSink(sink)
+	return dst
+}
+
 type TextStructFormatter struct {
 	KeepOutermostParens bool
 }
@@ -272,9 +584,31 @@ func(form TextStructFormatter) StructToText(structure Structure) string {
 	return sink.ToString()
 }
 
+var textStructSinkPool = sync.Pool {
+	New: func() any {
+		return &TextStructSink{}
+	},
+}
+
+func acquireTextStructSink(keepOutermostParens bool) *TextStructSink {
+	sink := textStructSinkPool.Get().(*TextStructSink)
+	sink.KeepOutermostParens = keepOutermostParens
+	return sink
+}
+
+func releaseTextStructSink(sink *TextStructSink) {
+	if cap(sink.buffer) > maxPooledBufferSize {
+		return
+	}
+	sink.Reset()
+	textStructSinkPool.Put(sink)
+}
+
 var DumbStructFormatter StructFormatter = TextStructFormatter{}
 
 var _ TextFormatter = MessageTextFormatter{}
+var _ TextFormatter = CauseTextFormatter{}
+var _ TextFormatter = StackTextFormatter{}
 var _ TextFormatter = ConcatTextFormatter{}
 var _ TextFormatter = LineTextFormatter{}
 var _ TextFormatter = &PrefixedTextFormatter{}
@@ -283,7 +617,17 @@ var _ LineFormatter = ConcatLineFormatter{}
 var _ LineFormatter = StringLineFormatter{}
 var _ LineFormatter = &GenericLevelLineFormatter{}
 var _ LineFormatter = &GenericSourceLineFormatter{}
+var _ LineFormatter = &AbbreviatedSourceLineFormatter{}
 var _ LineFormatter = &GenericTimestampLineFormatter{}
 var _ LineFormatter = &GenericStructLineFormatter{}
 
+var _ AppendTextFormatter = LineTextFormatter{}
+
+var _ AppendLineFormatter = ConcatLineFormatter{}
+var _ AppendLineFormatter = StringLineFormatter{}
+var _ AppendLineFormatter = &GenericLevelLineFormatter{}
+var _ AppendLineFormatter = &GenericSourceLineFormatter{}
+var _ AppendLineFormatter = &GenericTimestampLineFormatter{}
+var _ AppendLineFormatter = &GenericStructLineFormatter{}
+
 var _ StructFormatter = TextStructFormatter{}
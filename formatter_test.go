@@ -0,0 +1,61 @@
+package golog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAbbreviateSegments(t *testing.T) {
+	cases := []struct {
+		segments []string
+		target int
+		want string
+	}{
+		{ []string { "com", "example", "service", "Handler" }, 0, "com.example.service.Handler" },
+		{ []string { "com", "example", "service", "Handler" }, 100, "com.example.service.Handler" },
+		{ []string { "com", "example", "service", "Handler" }, 20, "c.e.service.Handler" },
+		{ []string { "com", "example", "service", "Handler" }, 1, "c.e.s.Handler" },
+		{ []string { "only" }, 1, "only" },
+	}
+	for _, c := range cases {
+		if got := AbbreviateSegments(c.segments, ".", c.target); got != c.want {
+			t.Errorf("%v/%d: got %q, want %q", c.segments, c.target, got, c.want)
+		}
+	}
+}
+
+func TestAbbreviatedSourceLineFormatter(t *testing.T) {
+	packet := &Packet {
+		Source: &DefaultSource {
+			Module: "github.com/acme/app",
+			Type: "server.Handler",
+			Function: "ServeHTTP",
+		},
+	}
+	render := func(form *AbbreviatedSourceLineFormatter) string {
+		var builder strings.Builder
+		form.PacketToLine(packet, &builder)
+		return builder.String()
+	}
+	if got := render(&AbbreviatedSourceLineFormatter {
+		TargetLength: 10,
+		Parts: SRC_TYPE | SRC_FUNCTION,
+	}); got != "s.H.ServeHTTP" {
+		t.Fatalf("got %q", got)
+	}
+	if got := render(&AbbreviatedSourceLineFormatter {
+		TargetLength: 1,
+		Separator: "/",
+		Parts: SRC_MODULE,
+	}); got != "g/a/app" {
+		t.Fatalf("got %q", got)
+	}
+	packet.Source = nil
+	form := &AbbreviatedSourceLineFormatter{}
+	form.ReplacementIfMissing = StringLineFormatter {
+		Value: "?",
+	}
+	if got := render(form); got != "?" {
+		t.Fatalf("missing source rendered %q", got)
+	}
+}
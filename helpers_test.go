@@ -0,0 +1,109 @@
+package golog
+
+import (
+	"sync"
+	"bytes"
+	"errors"
+)
+
+type capturedPacket struct {
+	Level Level
+	Source Source
+	Lines []string
+	Details *Value
+	Packet *Packet
+}
+
+type captureLogger struct {
+	ID uintptr
+	mutex sync.Mutex
+	packets []capturedPacket
+}
+
+func newCaptureLogger() *captureLogger {
+	return &captureLogger {
+		ID: NewLoggerID(),
+	}
+}
+
+func(logger *captureLogger) Log(packet *Packet) {
+	if packet == nil {
+		return
+	}
+	captured := capturedPacket {
+		Level: packet.Level,
+		Source: packet.Source,
+		Packet: packet,
+	}
+	if packet.Message != nil {
+		captured.Lines = append([]string(nil), packet.Message.Lines()...)
+		captured.Details = CaptureValue(packet.Message)
+	}
+	logger.mutex.Lock()
+	logger.packets = append(logger.packets, captured)
+	logger.mutex.Unlock()
+}
+
+func(logger *captureLogger) Close() {}
+
+func(logger *captureLogger) SubLoggers() []Logger {
+	return nil
+}
+
+func(logger *captureLogger) Identity() uintptr {
+	return logger.ID
+}
+
+func(logger *captureLogger) Packets() []capturedPacket {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	return append([]capturedPacket(nil), logger.packets...)
+}
+
+func(logger *captureLogger) Last() capturedPacket {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	if len(logger.packets) == 0 {
+		return capturedPacket{}
+	}
+	return logger.packets[len(logger.packets) - 1]
+}
+
+var _ Logger = &captureLogger{}
+
+var errFailingWriter = errors.New("write refused")
+
+type failingWriter struct {}
+
+func(writer failingWriter) Write(data []byte) (int, error) {
+	return 0, errFailingWriter
+}
+
+type recordingWriter struct {
+	mutex sync.Mutex
+	buffer bytes.Buffer
+	writes int
+	failing bool
+}
+
+func(writer *recordingWriter) Write(data []byte) (int, error) {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	if writer.failing {
+		return 0, errFailingWriter
+	}
+	writer.writes++
+	return writer.buffer.Write(data)
+}
+
+func(writer *recordingWriter) String() string {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	return writer.buffer.String()
+}
+
+func(writer *recordingWriter) setFailing(failing bool) {
+	writer.mutex.Lock()
+	writer.failing = failing
+	writer.mutex.Unlock()
+}
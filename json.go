@@ -0,0 +1,377 @@
+package golog
+
+import (
+	"io"
+	"math"
+	"time"
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+	"encoding/json"
+	"encoding/base64"
+)
+
+type PacketEncoder interface {
+	EncodePacket(*Packet) ([]byte, error)
+}
+
+type JSONStructSink struct {
+	builder strings.Builder
+	stack BoolStack
+}
+
+func(sink *JSONStructSink) enterElement() {
+	if sink.stack.IsEmpty() {
+		return
+	}
+	if sink.stack.Top() {
+		sink.builder.WriteByte(',')
+	} else {
+		sink.stack.Replace(true)
+	}
+}
+
+func(sink *JSONStructSink) key(name string) {
+	sink.enterElement()
+	writeJSONString(&sink.builder, name)
+	sink.builder.WriteByte(':')
+}
+
+func(sink *JSONStructSink) Map() StructMap {
+	sink.enterElement()
+	sink.builder.WriteByte('{')
+	sink.stack.Push(false)
+	return sink
+}
+
+func(sink *JSONStructSink) List() StructList {
+	sink.enterElement()
+	sink.builder.WriteByte('[')
+	sink.stack.Push(false)
+	return sink
+}
+
+func(sink *JSONStructSink) BoolProperty(name string, value bool) {
+	sink.key(name)
+	sink.builder.WriteString(strconv.FormatBool(value))
+}
+
+func(sink *JSONStructSink) StringProperty(name string, value string) {
+	sink.key(name)
+	writeJSONString(&sink.builder, value)
+}
+
+func(sink *JSONStructSink) IntProperty(name string, value int64) {
+	sink.key(name)
+	sink.builder.WriteString(strconv.FormatInt(value, 10))
+}
+
+func(sink *JSONStructSink) FloatProperty(name string, value float64) {
+	sink.key(name)
+	writeJSONFloat(&sink.builder, value)
+}
+
+func(sink *JSONStructSink) TimeProperty(name string, value time.Time) {
+	sink.key(name)
+	writeJSONString(&sink.builder, value.Format(time.RFC3339Nano))
+}
+
+func(sink *JSONStructSink) DurationProperty(name string, value time.Duration) {
+	sink.key(name)
+	writeJSONString(&sink.builder, value.String())
+}
+
+func(sink *JSONStructSink) BytesProperty(name string, value []byte) {
+	sink.key(name)
+	writeJSONString(&sink.builder, base64.StdEncoding.EncodeToString(value))
+}
+
+func(sink *JSONStructSink) UintProperty(name string, value uint64) {
+	sink.key(name)
+	sink.builder.WriteString(strconv.FormatUint(value, 10))
+}
+
+func(sink *JSONStructSink) NullProperty(name string) {
+	sink.key(name)
+	sink.builder.WriteString("null")
+}
+
+func(sink *JSONStructSink) MapProperty(name string) StructMap {
+	sink.key(name)
+	sink.builder.WriteByte('{')
+	sink.stack.Push(false)
+	return sink
+}
+
+func(sink *JSONStructSink) ListProperty(name string) StructList {
+	sink.key(name)
+	sink.builder.WriteByte('[')
+	sink.stack.Push(false)
+	return sink
+}
+
+func(sink *JSONStructSink) EndMap() {
+	sink.stack.Pop()
+	sink.builder.WriteByte('}')
+}
+
+func(sink *JSONStructSink) Bool(value bool) {
+	sink.enterElement()
+	sink.builder.WriteString(strconv.FormatBool(value))
+}
+
+func(sink *JSONStructSink) String(value string) {
+	sink.enterElement()
+	writeJSONString(&sink.builder, value)
+}
+
+func(sink *JSONStructSink) Int(value int64) {
+	sink.enterElement()
+	sink.builder.WriteString(strconv.FormatInt(value, 10))
+}
+
+func(sink *JSONStructSink) Float(value float64) {
+	sink.enterElement()
+	writeJSONFloat(&sink.builder, value)
+}
+
+func(sink *JSONStructSink) Time(value time.Time) {
+	sink.enterElement()
+	writeJSONString(&sink.builder, value.Format(time.RFC3339Nano))
+}
+
+func(sink *JSONStructSink) Duration(value time.Duration) {
+	sink.enterElement()
+	writeJSONString(&sink.builder, value.String())
+}
+
+func(sink *JSONStructSink) Bytes(value []byte) {
+	sink.enterElement()
+	writeJSONString(&sink.builder, base64.StdEncoding.EncodeToString(value))
+}
+
+func(sink *JSONStructSink) Uint(value uint64) {
+	sink.enterElement()
+	sink.builder.WriteString(strconv.FormatUint(value, 10))
+}
+
+func(sink *JSONStructSink) Null() {
+	sink.enterElement()
+	sink.builder.WriteString("null")
+}
+
+func(sink *JSONStructSink) EndList() {
+	sink.stack.Pop()
+	sink.builder.WriteByte(']')
+}
+
+func(sink *JSONStructSink) ToString() string {
+	return sink.builder.String()
+}
+
+func writeJSONFloat(builder *strings.Builder, value float64) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		writeJSONString(builder, strconv.FormatFloat(value, 'g', -1, 64))
+		return
+	}
+	format := byte('f')
+	if abs := math.Abs(value); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	builder.WriteString(strconv.FormatFloat(value, format, -1, 64))
+}
+
+const hexDigits = "0123456789abcdef"
+
+func writeJSONString(builder *strings.Builder, value string) {
+	builder.WriteByte('"')
+	for index := 0; index < len(value); {
+		c := value[index]
+		if c < utf8.RuneSelf {
+			switch {
+				case c == '"' || c == '\\':
+					builder.WriteByte('\\')
+					builder.WriteByte(c)
+				case c == '\n':
+					builder.WriteString("\\n")
+				case c == '\r':
+					builder.WriteString("\\r")
+				case c == '\t':
+					builder.WriteString("\\t")
+				case c < 0x20 || c == 0x7F:
+					builder.WriteString("\\u00")
+					builder.WriteByte(hexDigits[c >> 4])
+					builder.WriteByte(hexDigits[c & 0x0F])
+				default:
+					builder.WriteByte(c)
+			}
+			index++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(value[index:])
+		switch {
+			case r == utf8.RuneError && size == 1:
+				builder.WriteString("\\ufffd")
+			case r == '\u2028' || r == '\u2029':
+				builder.WriteString("\\u202")
+				builder.WriteByte(hexDigits[r & 0x0F])
+			default:
+				builder.WriteString(value[index:index + size])
+		}
+		index += size
+	}
+	builder.WriteByte('"')
+}
+
+const (
+	JSONTimeKey = PacketTimeKey
+	JSONLevelKey = PacketLevelKey
+	JSONLevelNumberKey = PacketLevelNumberKey
+	JSONNominalKey = PacketNominalKey
+	JSONSourceKey = PacketSourceKey
+	JSONLinesKey = PacketLinesKey
+	JSONDetailsKey = PacketDetailsKey
+	JSONStackKey = PacketStackKey
+)
+
+type JSONPacketEncoder struct {}
+
+func(enc JSONPacketEncoder) EncodePacket(packet *Packet) ([]byte, error) {
+	return []byte(enc.encode(packet)), nil
+}
+
+func(enc JSONPacketEncoder) PacketToText(packet *Packet) []string {
+	return []string { enc.encode(packet) }
+}
+
+func(enc JSONPacketEncoder) encode(packet *Packet) string {
+	sink := &JSONStructSink{}
+	PacketStructure {
+		Packet: packet,
+	}.PutStruct(sink)
+	return sink.ToString()
+}
+
+type JSONError struct {
+	Offset int64
+	Reason string
+}
+
+func(err *JSONError) Error() string {
+	return "invalid JSON at offset " + strconv.FormatInt(err.Offset, 10) + ": " + err.Reason
+}
+
+func DecodeJSON(data []byte) (*Value, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	value, err := decodeJSONValue(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, &JSONError {
+			Offset: decoder.InputOffset(),
+			Reason: "trailing data after value",
+		}
+	}
+	return value, nil
+}
+
+func decodeJSONValue(decoder *json.Decoder) (*Value, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	switch token := token.(type) {
+		case nil:
+			return NullValue(), nil
+		case bool:
+			return BoolValue(token), nil
+		case string:
+			return StringValue(token), nil
+		case json.Number:
+			return numberValue(string(token)), nil
+		case json.Delim:
+			switch token {
+				case '{':
+					m := MapValue()
+					for decoder.More() {
+						key, err := decoder.Token()
+						if err != nil {
+							return nil, err
+						}
+						child, err := decodeJSONValue(decoder)
+						if err != nil {
+							return nil, err
+						}
+						m.Set(key.(string), child)
+					}
+					if _, err := decoder.Token(); err != nil {
+						return nil, err
+					}
+					return m, nil
+				case '[':
+					list := ListValue()
+					for decoder.More() {
+						child, err := decodeJSONValue(decoder)
+						if err != nil {
+							return nil, err
+						}
+						list.Append(child)
+					}
+					if _, err := decoder.Token(); err != nil {
+						return nil, err
+					}
+					return list, nil
+			}
+	}
+	return nil, &JSONError {
+		Offset: decoder.InputOffset(),
+		Reason: "unexpected delimiter",
+	}
+}
+
+func numberValue(text string) *Value {
+	if integer, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return IntValue(integer)
+	}
+	if unsigned, err := strconv.ParseUint(text, 10, 64); err == nil {
+		return UintValue(unsigned)
+	}
+	float, _ := strconv.ParseFloat(text, 64)
+	return FloatValue(float)
+}
+
+type emptinessSink struct {
+	touched bool
+}
+
+func(sink *emptinessSink) Map() StructMap {
+	sink.touched = true
+	return discardSink{}
+}
+
+func(sink *emptinessSink) List() StructList {
+	sink.touched = true
+	return discardSink{}
+}
+
+func IsEmptyStructure(structure Structure) bool {
+	if structure == nil {
+		return true
+	}
+	sink := &emptinessSink{}
+	structure.PutStruct(sink)
+	return !sink.touched
+}
+
+var _ ExtendedStructMap = &JSONStructSink{}
+var _ ExtendedStructList = &JSONStructSink{}
+var _ PacketEncoder = JSONPacketEncoder{}
+var _ error = &JSONError{}
+var _ TextFormatter = JSONPacketEncoder{}
+var _ StructSink = &emptinessSink{}
@@ -0,0 +1,27 @@
+package golog
+
+import (
+	"os"
+	"bytes"
+	"encoding/hex"
+	"encoding/base64"
+)
+
+func ParseKeyText(content []byte) []byte {
+	text := string(bytes.TrimSpace(content))
+	if decoded, err := hex.DecodeString(text); err == nil && len(decoded) > 0 {
+		return decoded
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(text); err == nil && len(decoded) > 0 {
+		return decoded
+	}
+	return content
+}
+
+func ReadKeyFile(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeyText(content), nil
+}
@@ -0,0 +1,40 @@
+package golog
+
+import (
+	"os"
+	"bytes"
+	"testing"
+	"path/filepath"
+)
+
+func TestParseKeyText(t *testing.T) {
+	cases := []struct {
+		text string
+		want []byte
+	} {
+		{ "00ff10\n", []byte { 0x00, 0xFF, 0x10 } },
+		{ "  AAEC  ", []byte { 0xAA, 0xEC } },
+		{ "AAECAw==", []byte { 0x00, 0x01, 0x02, 0x03 } },
+		{ "not a key!", []byte("not a key!") },
+		{ "", []byte("") },
+	}
+	for _, c := range cases {
+		if got := ParseKeyText([]byte(c.text)); !bytes.Equal(got, c.want) {
+			t.Errorf("%q: got %x, want %x", c.text, got, c.want)
+		}
+	}
+}
+
+func TestReadKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte("0102\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	key, err := ReadKeyFile(path)
+	if err != nil || !bytes.Equal(key, []byte { 0x01, 0x02 }) {
+		t.Fatalf("got %x, %v", key, err)
+	}
+	if _, err := ReadKeyFile(path + ".missing"); err == nil {
+		t.Fatal("missing file accepted")
+	}
+}
@@ -0,0 +1,691 @@
+package golog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const DefaultLayoutTimeFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
+const DefaultLayout = "%time{\"" + DefaultLayoutTimeFormat + "\"} %level{left} " +
+		"%source{prefix=\"[\", suffix=\"] \"}%msg%details{prefix=\" \"}"
+
+type LayoutError struct {
+	Layout string
+	Offset int
+	Reason string
+}
+
+func(err *LayoutError) Error() string {
+	return fmt.Sprintf("invalid layout %q at offset %d: %s", err.Layout, err.Offset, err.Reason)
+}
+
+type GenericMessageLineFormatter struct {
+	PieceLineFormatterBase
+	Line int
+}
+
+func(form *GenericMessageLineFormatter) PacketToLine(packet *Packet, builder *strings.Builder) {
+	if packet.Message == nil {
+		form.Missing(packet, builder)
+		return
+	}
+	lines := packet.Message.Lines()
+	if form.Line < 0 || form.Line >= len(lines) {
+		form.Empty(packet, builder)
+	} else {
+		form.WithString(lines[form.Line], packet, builder)
+	}
+}
+
+func(form *GenericMessageLineFormatter) AppendPacketLine(dst []byte, packet *Packet) []byte {
+	if packet.Message == nil {
+		return form.AppendMissing(dst, packet)
+	}
+	lines := packet.Message.Lines()
+	if form.Line < 0 || form.Line >= len(lines) {
+		return form.AppendEmpty(dst, packet)
+	}
+	return form.AppendWithString(dst, lines[form.Line], packet)
+}
+
+type singleLineMessage struct {
+	line string
+	message Message
+}
+
+func(msg *singleLineMessage) Lines() []string {
+	return []string { msg.line }
+}
+
+func(msg *singleLineMessage) PutStruct(sink StructSink) {
+	msg.message.PutStruct(sink)
+}
+
+func(msg *singleLineMessage) UnwrapMessage() Message {
+	return msg.message
+}
+
+type LayoutTextFormatter struct {
+	Head []LineFormatter
+	Message LineFormatter
+	Tail []LineFormatter
+	LineTail []LineFormatter
+	PrefixMode PrefixMode
+}
+
+func(form *LayoutTextFormatter) messageLines(packet *Packet) ([]string, bool) {
+	if packet.Message == nil {
+		return nil, true
+	}
+	lines := packet.Message.Lines()
+	return lines, len(lines) > 0 || form.Message == nil
+}
+
+func(form *LayoutTextFormatter) PacketToText(packet *Packet) []string {
+	lines, ok := form.messageLines(packet)
+	if !ok {
+		return nil
+	}
+	head := PacketToLine(packet, form.Head)
+	if len(lines) <= 1 || form.Message == nil {
+		var builder strings.Builder
+		builder.WriteString(head)
+		if form.Message != nil {
+			form.Message.PacketToLine(packet, &builder)
+		}
+		writeLineFormatters(packet, form.Tail, &builder)
+		writeLineFormatters(packet, form.LineTail, &builder)
+		return []string { builder.String() }
+	}
+	var restPrefix string
+	switch form.PrefixMode {
+		case PFX_ALL_SAME:
+			restPrefix = head
+		case PFX_THEN_SPACES:
+			restPrefix = string(RepeatRune(' ', TextWidth(head)))
+	}
+	linePacket := *packet
+	all := make([]string, 0, len(lines))
+	for index, line := range lines {
+		var builder strings.Builder
+		if index == 0 {
+			builder.WriteString(head)
+		} else {
+			builder.WriteString(restPrefix)
+		}
+		linePacket.Message = &singleLineMessage {
+			line: line,
+			message: packet.Message,
+		}
+		form.Message.PacketToLine(&linePacket, &builder)
+		if index == len(lines) - 1 {
+			writeLineFormatters(packet, form.Tail, &builder)
+		}
+		writeLineFormatters(&linePacket, form.LineTail, &builder)
+		all = append(all, builder.String())
+	}
+	return all
+}
+
+func writeLineFormatters(packet *Packet, formatters []LineFormatter, builder *strings.Builder) {
+	for _, formatter := range formatters {
+		if formatter != nil {
+			formatter.PacketToLine(packet, builder)
+		}
+	}
+}
+
+func(form *LayoutTextFormatter) AppendPacketText(dst []byte, packet *Packet) []byte {
+	lines, ok := form.messageLines(packet)
+	if !ok {
+		return dst
+	}
+	headStart := len(dst)
+	dst = AppendPacketLine(dst, packet, form.Head)
+	if len(lines) <= 1 || form.Message == nil {
+		dst = AppendLine(dst, packet, form.Message)
+		dst = AppendPacketLine(dst, packet, form.Tail)
+		dst = AppendPacketLine(dst, packet, form.LineTail)
+		return append(dst, '\n')
+	}
+	head := string(dst[headStart:])
+	var restPrefix string
+	switch form.PrefixMode {
+		case PFX_ALL_SAME:
+			restPrefix = head
+		case PFX_THEN_SPACES:
+			restPrefix = string(RepeatRune(' ', TextWidth(head)))
+	}
+	linePacket := *packet
+	for index, line := range lines {
+		if index > 0 {
+			dst = append(dst, restPrefix...)
+		}
+		linePacket.Message = &singleLineMessage {
+			line: line,
+			message: packet.Message,
+		}
+		dst = AppendLine(dst, &linePacket, form.Message)
+		if index == len(lines) - 1 {
+			dst = AppendPacketLine(dst, packet, form.Tail)
+		}
+		dst = AppendPacketLine(dst, &linePacket, form.LineTail)
+		dst = append(dst, '\n')
+	}
+	return dst
+}
+
+type layoutArg struct {
+	key string
+	value string
+	offset int
+}
+
+type layoutDirective struct {
+	name string
+	offset int
+	args []layoutArg
+	minWidth int
+	maxWidth int
+	alignLeft bool
+	truncateEnd bool
+	ellipsis string
+}
+
+type layoutElement struct {
+	formatter LineFormatter
+	message bool
+}
+
+type layoutParser struct {
+	layout string
+	pos int
+}
+
+func(parser *layoutParser) fail(offset int, format string, args ...any) error {
+	return &LayoutError {
+		Layout: parser.layout,
+		Offset: offset,
+		Reason: fmt.Sprintf(format, args...),
+	}
+}
+
+func(parser *layoutParser) atEnd() bool {
+	return parser.pos >= len(parser.layout)
+}
+
+func(parser *layoutParser) peek() byte {
+	return parser.layout[parser.pos]
+}
+
+func(parser *layoutParser) parse() ([]layoutElement, error) {
+	var elements []layoutElement
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			elements = append(elements, layoutElement {
+				formatter: StringLineFormatter {
+					Value: literal.String(),
+				},
+			})
+			literal.Reset()
+		}
+	}
+	for !parser.atEnd() {
+		c := parser.peek()
+		switch c {
+			case '%':
+				if parser.pos + 1 < len(parser.layout) && parser.layout[parser.pos + 1] == '%' {
+					literal.WriteByte('%')
+					parser.pos += 2
+					continue
+				}
+				directive, err := parser.parseDirective()
+				if err != nil {
+					return nil, err
+				}
+				element, err := parser.compileDirective(directive)
+				if err != nil {
+					return nil, err
+				}
+				flush()
+				elements = append(elements, element)
+			case '\\':
+				r, err := parser.parseEscape()
+				if err != nil {
+					return nil, err
+				}
+				literal.WriteRune(r)
+			default:
+				r, size := utf8.DecodeRuneInString(parser.layout[parser.pos:])
+				literal.WriteRune(r)
+				parser.pos += size
+		}
+	}
+	flush()
+	return elements, nil
+}
+
+func(parser *layoutParser) parseEscape() (rune, error) {
+	start := parser.pos
+	parser.pos++
+	if parser.atEnd() {
+		return 0, parser.fail(start, "dangling backslash at end of layout")
+	}
+	r, size := utf8.DecodeRuneInString(parser.layout[parser.pos:])
+	parser.pos += size
+	switch r {
+		case 'n':
+			return '\n', nil
+		case 't':
+			return '\t', nil
+		case '\\', '%', '{', '}', ',', '"', '=':
+			return r, nil
+		default:
+			return 0, parser.fail(start, "unknown escape sequence '\\%c'", r)
+	}
+}
+
+func(parser *layoutParser) parseNumber() (int, bool) {
+	var value int
+	var found bool
+	for !parser.atEnd() && parser.peek() >= '0' && parser.peek() <= '9' {
+		value = value * 10 + int(parser.peek() - '0')
+		found = true
+		parser.pos++
+	}
+	return value, found
+}
+
+func(parser *layoutParser) parseDirective() (*layoutDirective, error) {
+	directive := &layoutDirective {
+		offset: parser.pos,
+	}
+	parser.pos++
+	if !parser.atEnd() && parser.peek() == '-' {
+		directive.alignLeft = true
+		parser.pos++
+	}
+	directive.minWidth, _ = parser.parseNumber()
+	if !parser.atEnd() && parser.peek() == '.' {
+		dot := parser.pos
+		parser.pos++
+		if !parser.atEnd() && parser.peek() == '-' {
+			directive.truncateEnd = true
+			parser.pos++
+		}
+		var ok bool
+		directive.maxWidth, ok = parser.parseNumber()
+		if !ok || directive.maxWidth == 0 {
+			return nil, parser.fail(dot, "expected positive maximum width after '.'")
+		}
+	}
+	start := parser.pos
+	for !parser.atEnd() && isLayoutNameByte(parser.peek()) {
+		parser.pos++
+	}
+	if start == parser.pos {
+		return nil, parser.fail(directive.offset, "expected directive name after '%%'")
+	}
+	directive.name = parser.layout[start:parser.pos]
+	if !parser.atEnd() && parser.peek() == '{' {
+		args, err := parser.parseArgs()
+		if err != nil {
+			return nil, err
+		}
+		directive.args = args
+	}
+	return directive, nil
+}
+
+func parseSourceParts(spec string) (SourceParts, error) {
+	var parts SourceParts
+	for _, name := range strings.Split(spec, "+") {
+		switch strings.TrimSpace(name) {
+			case "module":
+				parts |= SRC_MODULE
+			case "type":
+				parts |= SRC_TYPE
+			case "function":
+				parts |= SRC_FUNCTION
+			case "all":
+				parts |= SRC_ALL
+			default:
+				return 0, fmt.Errorf("unknown source part %q (expected module, type, function or all)", name)
+		}
+	}
+	return parts, nil
+}
+
+func isLayoutNameByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func(parser *layoutParser) parseArgs() ([]layoutArg, error) {
+	open := parser.pos
+	parser.pos++
+	var args []layoutArg
+	for {
+		for !parser.atEnd() && parser.peek() == ' ' {
+			parser.pos++
+		}
+		if parser.atEnd() {
+			return nil, parser.fail(open, "unterminated '{'")
+		}
+		if parser.peek() == '}' && len(args) == 0 {
+			parser.pos++
+			return nil, nil
+		}
+		arg := layoutArg {
+			offset: parser.pos,
+		}
+		value, err := parser.parseArgValue(true)
+		if err != nil {
+			return nil, err
+		}
+		if !parser.atEnd() && parser.peek() == '=' {
+			parser.pos++
+			arg.key = strings.TrimSpace(value)
+			if len(arg.key) == 0 {
+				return nil, parser.fail(arg.offset, "empty option name")
+			}
+			value, err = parser.parseArgValue(false)
+			if err != nil {
+				return nil, err
+			}
+		}
+		arg.value = value
+		args = append(args, arg)
+		if parser.atEnd() {
+			return nil, parser.fail(open, "unterminated '{'")
+		}
+		switch parser.peek() {
+			case ',':
+				parser.pos++
+			case '}':
+				parser.pos++
+				return args, nil
+			default:
+				return nil, parser.fail(parser.pos, "expected ',' or '}' after option")
+		}
+	}
+}
+
+func(parser *layoutParser) parseArgValue(allowKey bool) (string, error) {
+	for !parser.atEnd() && parser.peek() == ' ' {
+		parser.pos++
+	}
+	var builder strings.Builder
+	if !parser.atEnd() && parser.peek() == '"' {
+		open := parser.pos
+		parser.pos++
+		for {
+			if parser.atEnd() {
+				return "", parser.fail(open, "unterminated quoted string")
+			}
+			c := parser.peek()
+			if c == '"' {
+				parser.pos++
+				break
+			}
+			if c == '\\' {
+				r, err := parser.parseEscape()
+				if err != nil {
+					return "", err
+				}
+				builder.WriteRune(r)
+				continue
+			}
+			r, size := utf8.DecodeRuneInString(parser.layout[parser.pos:])
+			builder.WriteRune(r)
+			parser.pos += size
+		}
+		for !parser.atEnd() && parser.peek() == ' ' {
+			parser.pos++
+		}
+		return builder.String(), nil
+	}
+	for !parser.atEnd() {
+		c := parser.peek()
+		if c == ',' || c == '}' || allowKey && c == '=' {
+			break
+		}
+		if c == '\\' {
+			r, err := parser.parseEscape()
+			if err != nil {
+				return "", err
+			}
+			builder.WriteRune(r)
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(parser.layout[parser.pos:])
+		builder.WriteRune(r)
+		parser.pos += size
+	}
+	return strings.TrimRight(builder.String(), " "), nil
+}
+
+func(parser *layoutParser) compileDirective(directive *layoutDirective) (layoutElement, error) {
+	var element layoutElement
+	var base *PieceLineFormatterBase
+	var positional []layoutArg
+	var options []layoutArg
+	for _, arg := range directive.args {
+		if len(arg.key) == 0 {
+			positional = append(positional, arg)
+		} else {
+			options = append(options, arg)
+		}
+	}
+	switch directive.name {
+		case "time", "timestamp", "date":
+			form := &GenericTimestampLineFormatter{}
+			if len(positional) > 0 {
+				form.Format = positional[0].value
+				positional = positional[1:]
+			}
+			base = &form.PieceLineFormatterBase
+			element.formatter = form
+		case "level":
+			form := &GenericLevelLineFormatter{}
+			if len(positional) > 0 {
+				switch positional[0].value {
+					case "left":
+						form.Adjustment = ADJ_LEFT
+					case "right":
+						form.Adjustment = ADJ_RIGHT
+					case "none":
+						form.Adjustment = ADJ_NONE
+					default:
+						return element, parser.fail(positional[0].offset,
+								"unknown level adjustment %q (expected left, right or none)", positional[0].value)
+				}
+				positional = positional[1:]
+			}
+			base = &form.PieceLineFormatterBase
+			element.formatter = form
+		case "source":
+			var abbreviate *AbbreviatedSourceLineFormatter
+			var rest []layoutArg
+			for _, arg := range options {
+				switch arg.key {
+					case "length", "parts", "separator":
+						if abbreviate == nil {
+							abbreviate = &AbbreviatedSourceLineFormatter{}
+						}
+					default:
+						rest = append(rest, arg)
+						continue
+				}
+				switch arg.key {
+					case "length":
+						length, err := strconv.Atoi(arg.value)
+						if err != nil || length < 0 {
+							return element, parser.fail(arg.offset, "invalid source length %q", arg.value)
+						}
+						abbreviate.TargetLength = length
+					case "parts":
+						parts, err := parseSourceParts(arg.value)
+						if err != nil {
+							return element, parser.fail(arg.offset, "%s", err.Error())
+						}
+						abbreviate.Parts = parts
+					case "separator":
+						abbreviate.Separator = arg.value
+				}
+			}
+			options = rest
+			if abbreviate == nil {
+				form := &GenericSourceLineFormatter{}
+				base = &form.PieceLineFormatterBase
+				element.formatter = form
+			} else {
+				base = &abbreviate.PieceLineFormatterBase
+				element.formatter = abbreviate
+			}
+		case "msg", "message":
+			form := &GenericMessageLineFormatter{}
+			base = &form.PieceLineFormatterBase
+			element.formatter = form
+			element.message = true
+		case "details", "struct":
+			form := &GenericStructLineFormatter{}
+			var flags []layoutArg
+			for _, arg := range positional {
+				switch arg.value {
+					case "parens":
+						form.Formatter = TextStructFormatter {
+							KeepOutermostParens: true,
+						}
+					case "stack":
+						form.IncludeStack = true
+					default:
+						flags = append(flags, arg)
+				}
+			}
+			positional = flags
+			base = &form.PieceLineFormatterBase
+			element.formatter = form
+		default:
+			return element, parser.fail(directive.offset, "unknown directive %q", directive.name)
+	}
+	for _, arg := range positional {
+		switch arg.value {
+			case "prefixIfMissing":
+				base.Flags |= AFF_PREFIX_IF_MISSING
+			case "prefixIfEmpty":
+				base.Flags |= AFF_PREFIX_IF_EMPTY
+			case "suffixIfMissing":
+				base.Flags |= AFF_SUFFIX_IF_MISSING
+			case "suffixIfEmpty":
+				base.Flags |= AFF_SUFFIX_IF_EMPTY
+			default:
+				return element, parser.fail(arg.offset, "unknown flag %q for directive %q", arg.value, directive.name)
+		}
+	}
+	for _, arg := range options {
+		value := StringLineFormatter {
+			Value: arg.value,
+		}
+		switch arg.key {
+			case "prefix":
+				base.Prefix = value
+			case "suffix":
+				base.Suffix = value
+			case "missing":
+				base.ReplacementIfMissing = value
+			case "empty":
+				base.ReplacementIfEmpty = value
+			case "ellipsis":
+				directive.ellipsis = arg.value
+			default:
+				return element, parser.fail(arg.offset, "unknown option %q for directive %q", arg.key, directive.name)
+		}
+	}
+	if directive.minWidth > 0 || directive.maxWidth > 0 {
+		if directive.maxWidth > 0 && directive.minWidth > directive.maxWidth {
+			return element, parser.fail(directive.offset, "minimum width %d exceeds maximum width %d",
+					directive.minWidth, directive.maxWidth)
+		}
+		padded := &PaddedLineFormatter {
+			Formatter: element.formatter,
+			MinWidth: directive.minWidth,
+			MaxWidth: directive.maxWidth,
+			Alignment: ALIGN_RIGHT,
+			Truncate: TRUNC_START,
+			Ellipsis: directive.ellipsis,
+		}
+		if directive.alignLeft {
+			padded.Alignment = ALIGN_LEFT
+		}
+		if directive.truncateEnd {
+			padded.Truncate = TRUNC_END
+		}
+		element.formatter = padded
+	}
+	return element, nil
+}
+
+func CompileLineLayout(layout string) ([]LineFormatter, error) {
+	parser := &layoutParser {
+		layout: layout,
+	}
+	elements, err := parser.parse()
+	if err != nil {
+		return nil, err
+	}
+	formatters := make([]LineFormatter, len(elements))
+	for index, element := range elements {
+		formatters[index] = element.formatter
+	}
+	return formatters, nil
+}
+
+func CompileLayout(layout string, mode PrefixMode) (TextFormatter, error) {
+	parser := &layoutParser {
+		layout: layout,
+	}
+	elements, err := parser.parse()
+	if err != nil {
+		return nil, err
+	}
+	form := &LayoutTextFormatter {
+		PrefixMode: mode,
+	}
+	for _, element := range elements {
+		switch {
+			case element.message && form.Message == nil:
+				form.Message = element.formatter
+			case form.Message == nil:
+				form.Head = append(form.Head, element.formatter)
+			default:
+				form.Tail = append(form.Tail, element.formatter)
+		}
+	}
+	if last := len(form.Tail) - 1; form.Message != nil && last >= 0 {
+		if _, literal := form.Tail[last].(StringLineFormatter); literal {
+			form.LineTail = []LineFormatter { form.Tail[last] }
+			form.Tail = form.Tail[:last]
+		}
+	}
+	return form, nil
+}
+
+func MustCompileLayout(layout string, mode PrefixMode) TextFormatter {
+	form, err := CompileLayout(layout, mode)
+	if err != nil {
+		panic(err)
+	}
+	return form
+}
+
+var _ error = &LayoutError{}
+var _ LineFormatter = &GenericMessageLineFormatter{}
+var _ AppendLineFormatter = &GenericMessageLineFormatter{}
+var _ TextFormatter = &LayoutTextFormatter{}
+var _ AppendTextFormatter = &LayoutTextFormatter{}
+var _ Message = &singleLineMessage{}
+var _ MessageWrapper = &singleLineMessage{}
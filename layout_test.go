@@ -0,0 +1,161 @@
+package golog
+
+import (
+	"time"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func layoutPacket(lines ...string) *Packet {
+	details := MapValue()
+	details.Set("k", StringValue("v"))
+	return &Packet {
+		Level: WARNING,
+		Source: &DefaultSource {
+			Module: "app",
+			Function: "run",
+		},
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Message: &StringMessage {
+			Text: lines,
+			Details: details,
+		},
+	}
+}
+
+func renderLayout(t *testing.T, layout string, mode PrefixMode, packet *Packet) []string {
+	t.Helper()
+	form, err := CompileLayout(layout, mode)
+	if err != nil {
+		t.Fatalf("compile %q: %v", layout, err)
+	}
+	return form.PacketToText(packet)
+}
+
+func TestLayoutRendersDirectives(t *testing.T) {
+	cases := []struct {
+		layout string
+		want string
+	}{
+		{ `%time{"15:04:05"} %level{left}|%msg`, "03:04:05 WARNING|hello" },
+		{ `[%source] %msg %details{parens}`, "[app.run] hello {k: \"v\"}" },
+		{ `%%%msg\t\%`, "%hello\t%" },
+		{ `%-9level|`, "WARNING  |" },
+		{ `%.3level|`, "ING|" },
+		{ `%.-3level|`, "WAR|" },
+		{ `%8msg|`, "   hello|" },
+		{ `%source{length=3, parts=function}`, "run" },
+		{ `%msg{prefix="<", suffix=">"}`, "<hello>" },
+	}
+	for _, c := range cases {
+		got := renderLayout(t, c.layout, PFX_ALL_SAME, layoutPacket("hello"))
+		if len(got) != 1 || got[0] != c.want {
+			t.Errorf("%s: got %q, want %q", c.layout, got, c.want)
+		}
+	}
+}
+
+func TestLayoutMissingAndEmptyReplacements(t *testing.T) {
+	packet := layoutPacket("hello")
+	packet.Source = nil
+	got := renderLayout(t, `%source{missing="-"} %msg`, PFX_ALL_SAME, packet)
+	if got[0] != "- hello" {
+		t.Fatalf("got %q", got)
+	}
+	got = renderLayout(t, `%source{prefix="[", suffix="]"}%msg`, PFX_ALL_SAME, packet)
+	if got[0] != "hello" {
+		t.Fatalf("affixes emitted for missing source: %q", got)
+	}
+}
+
+func TestLayoutMultiLinePrefixModes(t *testing.T) {
+	layout := `%level{left}: %msg%details{prefix=" "}`
+	packet := layoutPacket("first", "second")
+	cases := map[PrefixMode][]string {
+		PFX_ALL_SAME: { "WARNING: first", "WARNING: second k: \"v\"" },
+		PFX_THEN_SPACES: { "WARNING: first", "         second k: \"v\"" },
+		PFX_ONLY_TOP: { "WARNING: first", "second k: \"v\"" },
+	}
+	for mode, want := range cases {
+		if got := renderLayout(t, layout, mode, packet); !reflect.DeepEqual(got, want) {
+			t.Errorf("mode %d: got %q, want %q", mode, got, want)
+		}
+	}
+}
+
+func TestLayoutErrors(t *testing.T) {
+	cases := []struct {
+		layout string
+		offset int
+	}{
+		{ "abc %bogus", 4 },
+		{ "%msg{", 4 },
+		{ "x\\q", 1 },
+		{ "%level{sideways}", 7 },
+		{ "%5.2msg", 0 },
+		{ "%.msg", 1 },
+		{ "%msg{color=red}", 5 },
+		{ "trailing \\", 9 },
+	}
+	for _, c := range cases {
+		_, err := CompileLayout(c.layout, PFX_ALL_SAME)
+		var layoutErr *LayoutError
+		if !errors.As(err, &layoutErr) {
+			t.Errorf("%q: expected LayoutError, got %v", c.layout, err)
+			continue
+		}
+		if layoutErr.Offset != c.offset {
+			t.Errorf("%q: offset %d, want %d (%v)", c.layout, layoutErr.Offset, c.offset, err)
+		}
+	}
+}
+
+func TestCompileLineLayoutKeepsOrder(t *testing.T) {
+	formatters, err := CompileLineLayout("a%msg b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(formatters) != 3 {
+		t.Fatalf("got %d formatters", len(formatters))
+	}
+	if got := PacketToLine(layoutPacket("x"), formatters); got != "ax b" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestLayoutEmptyMessage(t *testing.T) {
+	form := MustCompileLayout(`%level: %msg|`, PFX_ALL_SAME)
+	packet := layoutPacket()
+	if got := form.PacketToText(packet); len(got) != 0 {
+		t.Fatalf("empty message rendered %q", got)
+	}
+	if got := AppendPacketText(nil, packet, form); len(got) != 0 {
+		t.Fatalf("empty message appended %q", got)
+	}
+	packet.Message = nil
+	if got := form.PacketToText(packet); len(got) != 1 {
+		t.Fatalf("missing message rendered %q", got)
+	}
+	noMessage := MustCompileLayout(`%level|`, PFX_ALL_SAME)
+	if got := noMessage.PacketToText(layoutPacket()); !reflect.DeepEqual(got, []string { "WARNING|" }) {
+		t.Fatalf("layout without %%msg rendered %q", got)
+	}
+}
+
+func TestLayoutTailLiteralOnEveryLine(t *testing.T) {
+	form := MustCompileLayout(`[%level] %msg%details{prefix=" "} |`, PFX_THEN_SPACES)
+	want := []string { "[WARNING] first |", "          second k: \"v\" |" }
+	packet := layoutPacket("first", "second")
+	if got := form.PacketToText(packet); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	appended := string(AppendPacketText(nil, packet, form))
+	if appended != want[0] + "\n" + want[1] + "\n" {
+		t.Fatalf("appended %q", appended)
+	}
+	single := form.PacketToText(layoutPacket("only"))
+	if !reflect.DeepEqual(single, []string { "[WARNING] only k: \"v\" |" }) {
+		t.Fatalf("single line %q", single)
+	}
+}
@@ -0,0 +1,597 @@
+package golog
+
+import (
+	"fmt"
+	"time"
+	"strconv"
+	"strings"
+	"encoding/base64"
+	"unicode/utf8"
+)
+
+const (
+	LogfmtTimeKey = "time"
+	LogfmtLevelKey = "level"
+	LogfmtSourceKey = "source"
+	LogfmtMessageKey = "msg"
+	LogfmtOmitKey = "-"
+	LogfmtFieldsPrefix = "fields."
+)
+
+func logfmtKey(key string, fallback string) string {
+	if len(key) == 0 {
+		return fallback
+	}
+	return key
+}
+
+type LogfmtTextFormatter struct {
+	TimeKey string
+	LevelKey string
+	SourceKey string
+	MessageKey string
+	TimeFormat string
+	IncludeStack bool
+}
+
+func(form *LogfmtTextFormatter) PacketToText(packet *Packet) []string {
+	var builder strings.Builder
+	writer := &logfmtWriter {
+		builder: &builder,
+	}
+	for _, key := range []string {
+		logfmtKey(form.TimeKey, LogfmtTimeKey),
+		logfmtKey(form.LevelKey, LogfmtLevelKey),
+		logfmtKey(form.SourceKey, LogfmtSourceKey),
+		logfmtKey(form.MessageKey, LogfmtMessageKey),
+	} {
+		if key != LogfmtOmitKey {
+			writer.reserved = append(writer.reserved, key)
+		}
+	}
+	timeFormat := form.TimeFormat
+	if len(timeFormat) == 0 {
+		timeFormat = time.RFC3339Nano
+	}
+	if key := logfmtKey(form.TimeKey, LogfmtTimeKey); key != LogfmtOmitKey && !packet.Timestamp.IsZero() {
+		writer.pair(key, packet.Timestamp.Format(timeFormat), false)
+	}
+	if key := logfmtKey(form.LevelKey, LogfmtLevelKey); key != LogfmtOmitKey && packet.Level != nil {
+		writer.pair(key, strings.ToLower(packet.Level.HumanReadable(ADJ_NONE)), false)
+	}
+	if key := logfmtKey(form.SourceKey, LogfmtSourceKey); key != LogfmtOmitKey && packet.Source != nil {
+		writer.pair(key, packet.Source.StringSource(), false)
+	}
+	if packet.Message != nil {
+		if key := logfmtKey(form.MessageKey, LogfmtMessageKey); key != LogfmtOmitKey {
+			writer.pair(key, strings.Join(packet.Message.Lines(), "\n"), true)
+		}
+		packet.Message.PutStruct(&FlatteningSink {
+			Emit: writer.detail,
+		})
+	}
+	if form.IncludeStack && len(packet.Stack) > 0 {
+		packet.Stack.PutStruct(&FlatteningSink {
+			Emit: writer.flat,
+			Prefix: StackStructKey,
+		})
+	}
+	return []string { builder.String() }
+}
+
+type logfmtWriter struct {
+	builder *strings.Builder
+	reserved []string
+}
+
+func(writer *logfmtWriter) detail(key string, value *Value) {
+	escape := strings.HasPrefix(key, LogfmtFieldsPrefix) || key == StackStructKey ||
+			strings.HasPrefix(key, StackStructKey + ".")
+	for _, reserved := range writer.reserved {
+		escape = escape || key == reserved
+	}
+	if escape {
+		key = LogfmtFieldsPrefix + key
+	}
+	writer.flat(key, value)
+}
+
+func(writer *logfmtWriter) pair(key string, value string, forceQuote bool) {
+	if writer.builder.Len() > 0 {
+		writer.builder.WriteByte(' ')
+	}
+	writer.builder.WriteString(LogfmtSanitizeKey(key))
+	writer.builder.WriteByte('=')
+	if forceQuote || LogfmtNeedsQuote(value) {
+		writer.builder.WriteString(strconv.Quote(value))
+	} else {
+		writer.builder.WriteString(value)
+	}
+}
+
+func(writer *logfmtWriter) flat(key string, value *Value) {
+	switch value.Kind {
+		case VAL_NULL:
+			if writer.builder.Len() > 0 {
+				writer.builder.WriteByte(' ')
+			}
+			writer.builder.WriteString(LogfmtSanitizeKey(key))
+			writer.builder.WriteByte('=')
+		case VAL_STRING:
+			writer.pair(key, value.String, LogfmtLooksTyped(value.String))
+		default:
+			writer.pair(key, FormatScalarValue(value), false)
+	}
+}
+
+func FormatScalarValue(value *Value) string {
+	switch value.Kind {
+		case VAL_BOOL:
+			return strconv.FormatBool(value.Bool)
+		case VAL_STRING:
+			return value.String
+		case VAL_INT:
+			return strconv.FormatInt(value.Int, 10)
+		case VAL_UINT:
+			return strconv.FormatUint(value.Uint, 10)
+		case VAL_FLOAT:
+			return strconv.FormatFloat(value.Float, 'g', -1, 64)
+		case VAL_TIME:
+			return value.Time.Format(time.RFC3339Nano)
+		case VAL_DURATION:
+			return value.Duration.String()
+		case VAL_BYTES:
+			return base64.StdEncoding.EncodeToString(value.Bytes)
+		default:
+			return ""
+	}
+}
+
+func LogfmtNeedsQuote(value string) bool {
+	if len(value) == 0 {
+		return true
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == 0x7F || r == utf8.RuneError {
+			return true
+		}
+	}
+	return false
+}
+
+func LogfmtLooksTyped(value string) bool {
+	return inferLogfmtValue(value).Kind != VAL_STRING
+}
+
+func LogfmtSanitizeKey(key string) string {
+	if len(key) == 0 {
+		return "_"
+	}
+	var builder strings.Builder
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7F || r == utf8.RuneError {
+			builder.WriteByte('_')
+		} else {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+type flatFrame struct {
+	prefix string
+	index int
+}
+
+type FlatteningSink struct {
+	Emit func(string, *Value)
+	Prefix string
+	Separator string
+	stack []flatFrame
+}
+
+func(sink *FlatteningSink) join(prefix string, name string) string {
+	if len(prefix) == 0 {
+		return name
+	}
+	separator := sink.Separator
+	if len(separator) == 0 {
+		separator = "."
+	}
+	return prefix + separator + name
+}
+
+func(sink *FlatteningSink) key(name string) string {
+	if len(sink.stack) == 0 {
+		return sink.join(sink.Prefix, name)
+	}
+	return sink.join(sink.stack[len(sink.stack) - 1].prefix, name)
+}
+
+func(sink *FlatteningSink) nextKey() string {
+	if len(sink.stack) == 0 {
+		return sink.Prefix
+	}
+	top := &sink.stack[len(sink.stack) - 1]
+	index := top.index
+	top.index++
+	return sink.join(top.prefix, strconv.Itoa(index))
+}
+
+func(sink *FlatteningSink) push(prefix string) *FlatteningSink {
+	sink.stack = append(sink.stack, flatFrame {
+		prefix: prefix,
+	})
+	return sink
+}
+
+func(sink *FlatteningSink) pop() {
+	if len(sink.stack) > 0 {
+		sink.stack = sink.stack[:len(sink.stack) - 1]
+	}
+}
+
+func(sink *FlatteningSink) emit(key string, value *Value) {
+	if sink.Emit != nil {
+		sink.Emit(key, value)
+	}
+}
+
+func(sink *FlatteningSink) Map() StructMap {
+	return sink.push(sink.nextKey())
+}
+
+func(sink *FlatteningSink) List() StructList {
+	return sink.push(sink.nextKey())
+}
+
+func(sink *FlatteningSink) BoolProperty(name string, value bool) {
+	sink.emit(sink.key(name), BoolValue(value))
+}
+
+func(sink *FlatteningSink) StringProperty(name string, value string) {
+	sink.emit(sink.key(name), StringValue(value))
+}
+
+func(sink *FlatteningSink) IntProperty(name string, value int64) {
+	sink.emit(sink.key(name), IntValue(value))
+}
+
+func(sink *FlatteningSink) FloatProperty(name string, value float64) {
+	sink.emit(sink.key(name), FloatValue(value))
+}
+
+func(sink *FlatteningSink) TimeProperty(name string, value time.Time) {
+	sink.emit(sink.key(name), TimeValue(value))
+}
+
+func(sink *FlatteningSink) DurationProperty(name string, value time.Duration) {
+	sink.emit(sink.key(name), DurationValue(value))
+}
+
+func(sink *FlatteningSink) BytesProperty(name string, value []byte) {
+	sink.emit(sink.key(name), BytesValue(value))
+}
+
+func(sink *FlatteningSink) UintProperty(name string, value uint64) {
+	sink.emit(sink.key(name), UintValue(value))
+}
+
+func(sink *FlatteningSink) NullProperty(name string) {
+	sink.emit(sink.key(name), NullValue())
+}
+
+func(sink *FlatteningSink) MapProperty(name string) StructMap {
+	return sink.push(sink.key(name))
+}
+
+func(sink *FlatteningSink) ListProperty(name string) StructList {
+	return sink.push(sink.key(name))
+}
+
+func(sink *FlatteningSink) EndMap() {
+	sink.pop()
+}
+
+func(sink *FlatteningSink) Bool(value bool) {
+	sink.emit(sink.nextKey(), BoolValue(value))
+}
+
+func(sink *FlatteningSink) String(value string) {
+	sink.emit(sink.nextKey(), StringValue(value))
+}
+
+func(sink *FlatteningSink) Int(value int64) {
+	sink.emit(sink.nextKey(), IntValue(value))
+}
+
+func(sink *FlatteningSink) Float(value float64) {
+	sink.emit(sink.nextKey(), FloatValue(value))
+}
+
+func(sink *FlatteningSink) Time(value time.Time) {
+	sink.emit(sink.nextKey(), TimeValue(value))
+}
+
+func(sink *FlatteningSink) Duration(value time.Duration) {
+	sink.emit(sink.nextKey(), DurationValue(value))
+}
+
+func(sink *FlatteningSink) Bytes(value []byte) {
+	sink.emit(sink.nextKey(), BytesValue(value))
+}
+
+func(sink *FlatteningSink) Uint(value uint64) {
+	sink.emit(sink.nextKey(), UintValue(value))
+}
+
+func(sink *FlatteningSink) Null() {
+	sink.emit(sink.nextKey(), NullValue())
+}
+
+func(sink *FlatteningSink) EndList() {
+	sink.pop()
+}
+
+type LogfmtPair struct {
+	Key string
+	Value string
+	Quoted bool
+	HasValue bool
+}
+
+type LogfmtError struct {
+	Line string
+	Offset int
+	Reason string
+}
+
+func(err *LogfmtError) Error() string {
+	return fmt.Sprintf("invalid logfmt at offset %d: %s", err.Offset, err.Reason)
+}
+
+func ScanLogfmt(line string) ([]LogfmtPair, error) {
+	var pairs []LogfmtPair
+	pos := 0
+	for {
+		for pos < len(line) && (line[pos] == ' ' || line[pos] == '\t') {
+			pos++
+		}
+		if pos >= len(line) {
+			return pairs, nil
+		}
+		start := pos
+		for pos < len(line) && line[pos] != '=' && line[pos] != ' ' && line[pos] != '\t' {
+			if line[pos] == '"' {
+				return nil, &LogfmtError {
+					Line: line,
+					Offset: pos,
+					Reason: "unexpected '\"' in key",
+				}
+			}
+			pos++
+		}
+		pair := LogfmtPair {
+			Key: line[start:pos],
+		}
+		if len(pair.Key) == 0 {
+			return nil, &LogfmtError {
+				Line: line,
+				Offset: start,
+				Reason: "empty key",
+			}
+		}
+		if pos < len(line) && line[pos] == '=' {
+			pos++
+			pair.HasValue = true
+			if pos < len(line) && line[pos] == '"' {
+				end := pos + 1
+				for end < len(line) && line[end] != '"' {
+					if line[end] == '\\' {
+						end++
+					}
+					end++
+				}
+				if end >= len(line) {
+					return nil, &LogfmtError {
+						Line: line,
+						Offset: pos,
+						Reason: "unterminated quoted value",
+					}
+				}
+				value, err := strconv.Unquote(line[pos:end + 1])
+				if err != nil {
+					return nil, &LogfmtError {
+						Line: line,
+						Offset: pos,
+						Reason: "malformed quoted value: " + err.Error(),
+					}
+				}
+				pair.Value = value
+				pair.Quoted = true
+				pos = end + 1
+			} else {
+				valueStart := pos
+				for pos < len(line) && line[pos] != ' ' && line[pos] != '\t' {
+					pos++
+				}
+				pair.Value = line[valueStart:pos]
+			}
+		}
+		pairs = append(pairs, pair)
+	}
+}
+
+func inferLogfmtValue(text string) *Value {
+	switch text {
+		case "":
+			return NullValue()
+		case "true":
+			return BoolValue(true)
+		case "false":
+			return BoolValue(false)
+	}
+	if integer, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return IntValue(integer)
+	}
+	if unsigned, err := strconv.ParseUint(text, 10, 64); err == nil {
+		return UintValue(unsigned)
+	}
+	if strings.ContainsAny(text, "0123456789") {
+		if float, err := strconv.ParseFloat(text, 64); err == nil {
+			return FloatValue(float)
+		}
+	}
+	return StringValue(text)
+}
+
+type LogfmtParser struct {
+	TimeKey string
+	LevelKey string
+	SourceKey string
+	MessageKey string
+	TimeFormat string
+	Separator string
+}
+
+func ParseLogfmt(line string) (*Packet, error) {
+	return (&LogfmtParser{}).ParseLine(line)
+}
+
+func(parser *LogfmtParser) ParseLine(line string) (*Packet, error) {
+	pairs, err := ScanLogfmt(line)
+	if err != nil {
+		return nil, err
+	}
+	timeFormat := parser.TimeFormat
+	if len(timeFormat) == 0 {
+		timeFormat = time.RFC3339Nano
+	}
+	separator := parser.Separator
+	if len(separator) == 0 {
+		separator = "."
+	}
+	packet := &Packet{}
+	message := &StringMessage{}
+	details := MapValue()
+	stack := MapValue()
+	for _, pair := range pairs {
+		escaped := strings.HasPrefix(pair.Key, LogfmtFieldsPrefix)
+		switch {
+			case escaped:
+			case pair.Key == logfmtKey(parser.TimeKey, LogfmtTimeKey):
+				stamp, err := time.Parse(timeFormat, pair.Value)
+				if err != nil {
+					return nil, &LogfmtError {
+						Line: line,
+						Offset: strings.Index(line, pair.Key),
+						Reason: "malformed timestamp: " + err.Error(),
+					}
+				}
+				packet.Timestamp = stamp
+				continue
+			case pair.Key == logfmtKey(parser.LevelKey, LogfmtLevelKey):
+				level, err := LevelFromName(pair.Value)
+				if err != nil {
+					return nil, &LogfmtError {
+						Line: line,
+						Offset: strings.Index(line, pair.Key),
+						Reason: "unknown level " + strconv.Quote(pair.Value),
+					}
+				}
+				packet.Level = level
+				continue
+			case pair.Key == logfmtKey(parser.SourceKey, LogfmtSourceKey):
+				packet.Source = TextSource(pair.Value)
+				continue
+			case pair.Key == logfmtKey(parser.MessageKey, LogfmtMessageKey):
+				message.Text = strings.Split(pair.Value, "\n")
+				continue
+		}
+		var value *Value
+		switch {
+			case !pair.HasValue:
+				value = BoolValue(true)
+			case pair.Quoted:
+				value = StringValue(pair.Value)
+			default:
+				value = inferLogfmtValue(pair.Value)
+		}
+		path := strings.Split(strings.TrimPrefix(pair.Key, LogfmtFieldsPrefix), separator)
+		if !escaped && path[0] == StackStructKey {
+			stack.SetPath(path, value)
+		} else {
+			details.SetPath(path, value)
+		}
+	}
+	if frames := stack.Field(StackStructKey); frames != nil {
+		if trace, ok := stackFromValue(listifyValue(frames)); ok {
+			packet.Stack = trace
+		} else {
+			details.Set(StackStructKey, frames)
+		}
+	}
+	if len(details.Fields) > 0 {
+		message.Details = listifyValue(details)
+	}
+	if message.Text != nil || message.Details != nil {
+		packet.Message = message
+	}
+	return packet, nil
+}
+
+func listifyValue(value *Value) *Value {
+	if value == nil || value.Kind != VAL_MAP {
+		return value
+	}
+	sequential := len(value.Fields) > 0
+	for index := range value.Fields {
+		field := &value.Fields[index]
+		field.Value = listifyValue(field.Value)
+		if field.Name != strconv.Itoa(index) {
+			sequential = false
+		}
+	}
+	if !sequential {
+		return value
+	}
+	list := ListValue()
+	for _, field := range value.Fields {
+		list.Append(field.Value)
+	}
+	return list
+}
+
+func stackFromValue(value *Value) (StackTrace, bool) {
+	if value == nil || value.Kind != VAL_LIST {
+		return nil, false
+	}
+	trace := make(StackTrace, 0, len(value.Items))
+	for _, item := range value.Items {
+		function := item.Field(FrameFunctionStructKey)
+		file := item.Field(FrameFileStructKey)
+		line := item.Field(FrameLineStructKey)
+		if function == nil || file == nil || line == nil {
+			return nil, false
+		}
+		frame := StackFrame {
+			Function: FormatScalarValue(function),
+			File: FormatScalarValue(file),
+		}
+		switch line.Kind {
+			case VAL_INT:
+				frame.Line = int(line.Int)
+			case VAL_UINT:
+				frame.Line = int(line.Uint)
+			default:
+				return nil, false
+		}
+		trace = append(trace, frame)
+	}
+	return trace, true
+}
+
+var _ TextFormatter = &LogfmtTextFormatter{}
+var _ ExtendedStructMap = &FlatteningSink{}
+var _ ExtendedStructList = &FlatteningSink{}
+var _ error = &LogfmtError{}
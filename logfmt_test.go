@@ -0,0 +1,187 @@
+package golog
+
+import (
+	"strings"
+	"time"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLogfmtFormat(t *testing.T) {
+	details := MapValue()
+	details.Set("user", StringValue("bob smith"))
+	details.Set("count", IntValue(3))
+	details.Set("numeric", StringValue("42"))
+	nested := MapValue()
+	nested.Set("ok", BoolValue(true))
+	details.Set("nested", nested)
+	details.Set("none", NullValue())
+	packet := &Packet {
+		Level: WARNING,
+		Source: TextSource("app"),
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Message: &StringMessage {
+			Text: []string { "disk", "low" },
+			Details: details,
+		},
+	}
+	got := (&LogfmtTextFormatter{}).PacketToText(packet)
+	want := `time=2024-01-02T03:04:05Z level=warning source=app msg="disk\nlow" user="bob smith" count=3 ` +
+			`numeric="42" nested.ok=true none=`
+	if len(got) != 1 || got[0] != want {
+		t.Fatalf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestLogfmtRoundTrip(t *testing.T) {
+	details := MapValue()
+	details.Set("user", StringValue("bob smith"))
+	details.Set("numeric", StringValue("42"))
+	details.Set("count", IntValue(-3))
+	details.Set("ratio", FloatValue(0.5))
+	details.Set("tags", ListValue(StringValue("a"), StringValue("b")))
+	nested := MapValue()
+	nested.Set("ok", BoolValue(false))
+	details.Set("nested", nested)
+	cases := []Structure {
+		details,
+		ListValue(StringValue("a"), StringValue("b")),
+	}
+	formatter := &LogfmtTextFormatter{}
+	for _, structure := range cases {
+		packet := &Packet {
+			Level: ERROR,
+			Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+			Message: &StringMessage {
+				Text: []string { "line one", "line two" },
+				Details: structure,
+			},
+			Stack: StackTrace {
+				{
+					Function: "main.run",
+					File: "/src/main.go",
+					Line: 9,
+				},
+			},
+		}
+		formatter.IncludeStack = true
+		line := formatter.PacketToText(packet)[0]
+		parsed, err := ParseLogfmt(line)
+		if err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+		if parsed.Level != ERROR || !parsed.Timestamp.Equal(packet.Timestamp) {
+			t.Fatalf("%s: header %v %v", line, parsed.Level, parsed.Timestamp)
+		}
+		if !reflect.DeepEqual(parsed.Message.Lines(), packet.Message.Lines()) {
+			t.Fatalf("%s: lines %q", line, parsed.Message.Lines())
+		}
+		if !reflect.DeepEqual(parsed.Stack, packet.Stack) {
+			t.Fatalf("%s: stack %v", line, parsed.Stack)
+		}
+		want := CaptureValue(structure).ToAny()
+		if got := CaptureValue(parsed.Message).ToAny(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s:\ngot  %#v\nwant %#v", line, got, want)
+		}
+	}
+}
+
+func TestLogfmtRoundTripCollidingKeys(t *testing.T) {
+	details := MapValue()
+	for _, key := range []string { "time", "level", "source", "msg" } {
+		details.Set(key, StringValue("detail " + key))
+	}
+	details.Set("stack", StringValue("not a trace"))
+	fields := MapValue()
+	fields.Set("level", IntValue(3))
+	details.Set("fields", fields)
+	packet := &Packet {
+		Level: WARNING,
+		Source: TextSource("app.db"),
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Message: &StringMessage {
+			Text: []string { "header message" },
+			Details: details,
+		},
+	}
+	line := (&LogfmtTextFormatter {
+		IncludeStack: true,
+	}).PacketToText(packet)[0]
+	if !strings.Contains(line, "fields.level=\"detail level\"") || !strings.Contains(line, "fields.fields.level=3") {
+		t.Fatalf("colliding keys not escaped: %s", line)
+	}
+	parsed, err := ParseLogfmt(line)
+	if err != nil {
+		t.Fatalf("%s: %v", line, err)
+	}
+	if parsed.Level != WARNING || parsed.Source.StringSource() != "app.db" || !parsed.Timestamp.Equal(packet.Timestamp) {
+		t.Fatalf("%s: header %v %v %v", line, parsed.Level, parsed.Source, parsed.Timestamp)
+	}
+	if lines := parsed.Message.Lines(); len(lines) != 1 || lines[0] != "header message" {
+		t.Fatalf("%s: lines %q", line, lines)
+	}
+	if parsed.Stack != nil {
+		t.Fatalf("%s: detail became a stack %v", line, parsed.Stack)
+	}
+	want := details.ToAny()
+	if got := CaptureValue(parsed.Message).ToAny(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("%s:\ngot  %#v\nwant %#v", line, got, want)
+	}
+}
+
+func TestScanLogfmt(t *testing.T) {
+	pairs, err := ScanLogfmt(`a=1 flag b="x y" c= d="q\"uote"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []LogfmtPair {
+		{ Key: "a", Value: "1", HasValue: true },
+		{ Key: "flag" },
+		{ Key: "b", Value: "x y", Quoted: true, HasValue: true },
+		{ Key: "c", HasValue: true },
+		{ Key: "d", Value: "q\"uote", Quoted: true, HasValue: true },
+	}
+	if !reflect.DeepEqual(pairs, want) {
+		t.Fatalf("got %+v", pairs)
+	}
+	for _, bad := range []string { `a="open`, `=x`, `a"b=1`, `a="\q"` } {
+		_, err := ScanLogfmt(bad)
+		var logfmtErr *LogfmtError
+		if !errors.As(err, &logfmtErr) {
+			t.Errorf("%q: expected LogfmtError, got %v", bad, err)
+		}
+	}
+}
+
+func TestParseLogfmtRejectsUnknownLevels(t *testing.T) {
+	_, err := ParseLogfmt(`msg=hello level=wraning`)
+	var logfmtErr *LogfmtError
+	if !errors.As(err, &logfmtErr) || logfmtErr.Offset != 10 || !strings.Contains(logfmtErr.Reason, "wraning") {
+		t.Fatalf("got %v", err)
+	}
+	value, _ := DecodeJSON([]byte(`{"level":"wraning","message":["hello"]}`))
+	var decodeErr *PacketDecodeError
+	if _, err := PacketFromValue(value); !errors.As(err, &decodeErr) || decodeErr.Key != PacketLevelKey {
+		t.Fatalf("JSON: got %v", err)
+	}
+}
+
+func TestParseLogfmtInfersTypes(t *testing.T) {
+	packet, err := ParseLogfmt(`i=-1 u=18446744073709551615 f=1.5 b=false n= s=abc flag`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any {
+		"i": int64(-1),
+		"u": uint64(18446744073709551615),
+		"f": 1.5,
+		"b": false,
+		"n": nil,
+		"s": "abc",
+		"flag": true,
+	}
+	if got := CaptureValue(packet.Message).ToAny(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v", got)
+	}
+}
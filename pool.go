@@ -0,0 +1,152 @@
+package golog
+
+import (
+	"sync"
+	"time"
+)
+
+const maxPooledBufferSize = 64 << 10
+
+type LineBuffer struct {
+	Bytes []byte
+}
+
+var lineBufferPool = sync.Pool {
+	New: func() any {
+		return &LineBuffer {
+			Bytes: make([]byte, 0, 256),
+		}
+	},
+}
+
+func AcquireLineBuffer() *LineBuffer {
+	return lineBufferPool.Get().(*LineBuffer)
+}
+
+func ReleaseLineBuffer(buffer *LineBuffer) {
+	if buffer == nil || cap(buffer.Bytes) > maxPooledBufferSize {
+		return
+	}
+	buffer.Bytes = buffer.Bytes[:0]
+	lineBufferPool.Put(buffer)
+}
+
+var packetPool = sync.Pool {
+	New: func() any {
+		return &Packet{}
+	},
+}
+
+func AcquirePacket() *Packet {
+	return packetPool.Get().(*Packet)
+}
+
+// ReleasePacket returns the packet to the pool. Callers must not touch it
+// afterwards, and no sink may still hold it. Log and BoundLog release their
+// packets this way when ReusePackets is set, so sinks that keep a packet past
+// Logger.Log (queues, async writers) must store packet.Clone() instead.
+func ReleasePacket(packet *Packet) {
+	if packet == nil {
+		return
+	}
+	*packet = Packet{}
+	packetPool.Put(packet)
+}
+
+func(packet *Packet) Clone() *Packet {
+	if packet == nil {
+		return nil
+	}
+	clone := *packet
+	clone.Message = cloneMessage(packet.Message)
+	return &clone
+}
+
+type messageCloner interface {
+	cloneMessage() Message
+}
+
+func cloneMessage(msg Message) Message {
+	if cloner, ok := msg.(messageCloner); ok {
+		return cloner.cloneMessage()
+	}
+	return msg
+}
+
+func(msg *StringMessage) cloneMessage() Message {
+	if msg == nil {
+		return msg
+	}
+	return &StringMessage {
+		Text: append([]string(nil), msg.Text...),
+		Details: msg.Details,
+	}
+}
+
+func(msg *boundMessage) cloneMessage() Message {
+	return &boundMessage {
+		message: cloneMessage(msg.message),
+		fields: msg.fields,
+	}
+}
+
+func(msg *singleLineMessage) cloneMessage() Message {
+	return &singleLineMessage {
+		line: msg.line,
+		message: cloneMessage(msg.message),
+	}
+}
+
+func(msg *redactedMessage) cloneMessage() Message {
+	return &redactedMessage {
+		message: cloneMessage(msg.message),
+		redactor: msg.redactor,
+	}
+}
+
+func(msg *frozenMessage) cloneMessage() Message {
+	return &frozenMessage {
+		lines: append([]string(nil), msg.lines...),
+		details: msg.details,
+	}
+}
+
+func(msg *LazyMessage) cloneMessage() Message {
+	return msg
+}
+
+type textPacket struct {
+	packet Packet
+	message StringMessage
+	text [1]string
+}
+
+var textPacketPool = sync.Pool {
+	New: func() any {
+		return &textPacket{}
+	},
+}
+
+func acquireTextPacket(level Level, src Source, details Structure, text string) *textPacket {
+	entry := textPacketPool.Get().(*textPacket)
+	entry.text[0] = text
+	entry.message.Text = entry.text[:]
+	entry.message.Details = details
+	entry.packet.Level = level
+	entry.packet.Message = &entry.message
+	entry.packet.Source = src
+	entry.packet.Timestamp = time.Now()
+	return entry
+}
+
+func(entry *textPacket) release() {
+	*entry = textPacket{}
+	textPacketPool.Put(entry)
+}
+
+var _ messageCloner = &StringMessage{}
+var _ messageCloner = &boundMessage{}
+var _ messageCloner = &singleLineMessage{}
+var _ messageCloner = &redactedMessage{}
+var _ messageCloner = &frozenMessage{}
+var _ messageCloner = &LazyMessage{}
@@ -0,0 +1,223 @@
+package golog
+
+import (
+	"io"
+	"testing"
+)
+
+func benchmarkSource() Source {
+	return &DefaultSource {
+		Module: "bench",
+		Function: "run",
+	}
+}
+
+func benchmarkSinks() map[string]func() Logger {
+	formatter := MustCompileLayout(DefaultLayout, PFX_THEN_SPACES)
+	return map[string]func() Logger {
+		"TextWriterLogger": func() Logger {
+			return TextWriterLogger(io.Discard, formatter)
+		},
+		"BufferedTextLogger": func() Logger {
+			return &BufferedTextLogger {
+				ID: NewLoggerID(),
+				Writer: io.Discard,
+				Formatter: formatter,
+				Plain: true,
+				FlushInterval: -1,
+			}
+		},
+	}
+}
+
+func BenchmarkDisabledLogf(b *testing.B) {
+	log := &Log {
+		Logger: func() Logger {
+			dispatcher, _ := thresholdDispatcher(WARNING, TextWriterLogger(io.Discard, nil))
+			return dispatcher
+		}(),
+		ReusePackets: true,
+	}
+	src := benchmarkSource()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		log.Debugf(src, nil, "iteration %d", i)
+	}
+}
+
+func BenchmarkDisabledDebugl(b *testing.B) {
+	dispatcher, _ := thresholdDispatcher(WARNING, TextWriterLogger(io.Discard, nil))
+	log := &Log {
+		Logger: dispatcher,
+		ReusePackets: true,
+	}
+	src := benchmarkSource()
+	render := func() string {
+		return "never rendered"
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		log.Debugl(src, nil, render)
+	}
+}
+
+func BenchmarkEnabledLog(b *testing.B) {
+	for name, sink := range benchmarkSinks() {
+		b.Run(name, func(b *testing.B) {
+			log := &Log {
+				Logger: sink(),
+				ReusePackets: true,
+			}
+			src := benchmarkSource()
+			msg := &StringMessage {
+				Text: []string { "simple line" },
+			}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				log.Log(INFO, src, msg)
+			}
+		})
+	}
+}
+
+func BenchmarkEnabledLogv(b *testing.B) {
+	for name, sink := range benchmarkSinks() {
+		b.Run(name, func(b *testing.B) {
+			log := &Log {
+				Logger: sink(),
+				ReusePackets: true,
+			}
+			src := benchmarkSource()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				log.Logv(INFO, src, nil, "simple line")
+			}
+		})
+	}
+}
+
+type cloningLogger struct {
+	captureLogger
+	clones []*Packet
+}
+
+func(logger *cloningLogger) Log(packet *Packet) {
+	logger.clones = append(logger.clones, packet.Clone())
+}
+
+func TestCloneSurvivesPacketRelease(t *testing.T) {
+	target := &cloningLogger{}
+	log := (&BoundLog {
+		Logger: target,
+		Source: TextSource("app"),
+		ReusePackets: true,
+	}).With("user", "bob")
+	log.Infof(nil, "hello %d", 1)
+	log.Warnv(nil, "second")
+	if len(target.clones) != 2 {
+		t.Fatalf("got %d packets", len(target.clones))
+	}
+	first := target.clones[0]
+	if lines := first.Message.Lines(); len(lines) != 1 || lines[0] != "hello 1" {
+		t.Fatalf("clone lost its text after release: %q", lines)
+	}
+	if user := CaptureValue(first.Message).Field("user"); user == nil || user.String != "bob" {
+		t.Fatalf("clone lost bound fields: %v", CaptureValue(first.Message).ToAny())
+	}
+	if first.Level != INFO || first.Source.StringSource() != "app" {
+		t.Fatalf("header %v %v", first.Level, first.Source)
+	}
+	if lines := target.clones[1].Message.Lines(); len(lines) != 1 || lines[0] != "second" {
+		t.Fatalf("second clone: %q", lines)
+	}
+}
+
+func TestCloneCopiesThroughWrappers(t *testing.T) {
+	text := []string { "mail bob@example.com" }
+	redactor := &Redactor {
+		Scrubbers: DefaultScrubbers,
+	}
+	wrappers := map[string]Message {
+		"redacted": redactor.RedactMessage(&StringMessage {
+			Text: text,
+		}),
+		"bound": bindMessage(&StringMessage {
+			Text: text,
+		}, pairsToFields([]any { "k", 1 })),
+	}
+	for name, msg := range wrappers {
+		clone := (&Packet {
+			Message: msg,
+		}).Clone()
+		want := msg.Lines()[0]
+		text[0] = "overwritten"
+		if got := clone.Message.Lines()[0]; got != want {
+			t.Errorf("%s: clone shares text: got %q, want %q", name, got, want)
+		}
+		text[0] = "mail bob@example.com"
+	}
+	if clone := (&Packet{}).Clone(); clone.Message != nil {
+		t.Errorf("nil message cloned to %v", clone.Message)
+	}
+}
+
+func TestCloneKeepsLazyMessagesLazy(t *testing.T) {
+	renders := 0
+	lazy := LazyText(nil, func() string {
+		renders++
+		return "rendered"
+	})
+	clone := (&Packet {
+		Message: lazy,
+	}).Clone()
+	if renders != 0 {
+		t.Fatal("Clone forced the lazy message")
+	}
+	if clone.Message != Message(lazy) {
+		t.Fatalf("clone holds %T, want the original lazy message", clone.Message)
+	}
+	if lines := clone.Message.Lines(); len(lines) != 1 || lines[0] != "rendered" || renders != 1 {
+		t.Fatalf("lines %q after %d renders", lines, renders)
+	}
+}
+
+type countingEnabledLogger struct {
+	captureLogger
+	checks int
+}
+
+func(logger *countingEnabledLogger) Enabled(level Level, source Source) bool {
+	logger.checks++
+	return true
+}
+
+func TestLogChecksEnabledOnce(t *testing.T) {
+	for _, reuse := range []bool { false, true } {
+		target := &countingEnabledLogger{}
+		log := &Log {
+			Logger: target,
+			ReusePackets: reuse,
+		}
+		bound := &BoundLog {
+			Logger: target,
+			ReusePackets: reuse,
+		}
+		calls := []func() {
+			func() { log.Infov(nil, nil, "v") },
+			func() { log.Infof(nil, nil, "f") },
+			func() { log.InfoErr(nil, io.EOF, nil) },
+			func() { log.Infol(nil, nil, func() string { return "l" }) },
+			func() { bound.Infov(nil, "v") },
+			func() { bound.Infof(nil, "f") },
+			func() { bound.InfoErr(io.EOF, nil) },
+			func() { bound.Infol(nil, func() string { return "l" }) },
+		}
+		for index, call := range calls {
+			target.checks = 0
+			call()
+			if target.checks != 1 {
+				t.Errorf("reuse=%v, call %d: %d Enabled checks", reuse, index, target.checks)
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package golog
+
+import (
+	"os"
+	"time"
+)
+
+type RecoverAction uint
+
+const (
+	RCV_REPANIC RecoverAction = iota
+	RCV_SWALLOW
+	RCV_EXIT
+)
+
+const DefaultPanicExitCode = 2
+
+type RecoverOptions struct {
+	Level Level
+	Action RecoverAction
+	ExitCode int
+	Details Structure
+}
+
+func(log *Log) Recover(src Source, opts *RecoverOptions) {
+	value := recover()
+	if value == nil {
+		return
+	}
+	log.Logp(newPanicPacket(value, src, opts))
+	FlushLoggers(log.Logger)
+	finishRecover(value, opts)
+}
+
+func(log *BoundLog) Recover(opts *RecoverOptions) {
+	value := recover()
+	if value == nil {
+		return
+	}
+	log.Logp(newPanicPacket(value, nil, opts))
+	FlushLoggers(log.Logger)
+	finishRecover(value, opts)
+}
+
+func newPanicPacket(value any, src Source, opts *RecoverOptions) *Packet {
+	var level Level = FATAL
+	var details Structure
+	if opts != nil {
+		if opts.Level != nil {
+			level = opts.Level
+		}
+		details = opts.Details
+	}
+	return &Packet {
+		Level: level,
+		Message: &PanicMessage {
+			Value: value,
+			Goroutine: CurrentGoroutineID(),
+			Details: details,
+		},
+		Source: src,
+		Timestamp: time.Now(),
+		Stack: CapturePanicStack(1),
+	}
+}
+
+func finishRecover(value any, opts *RecoverOptions) {
+	action := RCV_REPANIC
+	code := DefaultPanicExitCode
+	if opts != nil {
+		action = opts.Action
+		if opts.ExitCode != 0 {
+			code = opts.ExitCode
+		}
+	}
+	switch action {
+		case RCV_SWALLOW:
+		case RCV_EXIT:
+			os.Exit(code)
+		default:
+			panic(value)
+	}
+}
@@ -0,0 +1,114 @@
+package golog
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type flushCountingLogger struct {
+	captureLogger
+	flushes int
+}
+
+func(logger *flushCountingLogger) Flush() {
+	logger.flushes++
+}
+
+func TestRecoverSwallowLogsPanic(t *testing.T) {
+	sink := &flushCountingLogger {
+		captureLogger: captureLogger {
+			ID: NewLoggerID(),
+		},
+	}
+	log := &Log {
+		Logger: sink,
+	}
+	func() {
+		defer log.Recover(nil, &RecoverOptions {
+			Action: RCV_SWALLOW,
+		})
+		panic("kaboom")
+	}()
+	last := sink.Last()
+	if last.Level != FATAL {
+		t.Fatalf("panic logged at %v", last.Level)
+	}
+	if !reflect.DeepEqual(last.Lines, []string { "panic: kaboom" }) {
+		t.Fatalf("lines %q", last.Lines)
+	}
+	if got := last.Details.Field(PanicStructKey); got == nil || got.String != "kaboom" {
+		t.Fatalf("details %v", last.Details.ToAny())
+	}
+	if last.Details.Field(GoroutineStructKey) == nil {
+		t.Fatal("goroutine ID missing")
+	}
+	if len(last.Packet.Stack) == 0 {
+		t.Fatal("panic stack missing")
+	}
+	if sink.flushes != 1 {
+		t.Fatalf("logger flushed %d times", sink.flushes)
+	}
+}
+
+func TestRecoverRepanicsByDefault(t *testing.T) {
+	sink := newCaptureLogger()
+	log := &BoundLog {
+		Logger: sink,
+	}
+	cause := errors.New("bad state")
+	var repanicked any
+	func() {
+		defer func() {
+			repanicked = recover()
+		}()
+		defer log.Recover(&RecoverOptions {
+			Level: ERROR,
+		})
+		panic(cause)
+	}()
+	if repanicked != cause {
+		t.Fatalf("recovered %v after Recover", repanicked)
+	}
+	last := sink.Last()
+	if last.Level != ERROR {
+		t.Fatalf("panic logged at %v", last.Level)
+	}
+	if !reflect.DeepEqual(last.Lines, []string { "panic", "  bad state" }) {
+		t.Fatalf("lines %q", last.Lines)
+	}
+}
+
+func TestRecoverWithoutPanicDoesNothing(t *testing.T) {
+	sink := newCaptureLogger()
+	func() {
+		defer (&Log {
+			Logger: sink,
+		}).Recover(nil, nil)
+	}()
+	if len(sink.Packets()) != 0 {
+		t.Fatal("Recover logged without a panic")
+	}
+}
+
+func TestPanicMessageDetailsShadowPanicKeys(t *testing.T) {
+	details := MapValue()
+	details.Set(PanicTypeStructKey, StringValue("custom"))
+	counted := &countingStructure {
+		Structure: details,
+	}
+	value := CaptureValue(&PanicMessage {
+		Value: "kaboom",
+		Goroutine: 7,
+		Details: counted,
+	})
+	if counted.emits != 1 {
+		t.Fatalf("details emitted %d times", counted.emits)
+	}
+	if value.Field(PanicTypeStructKey).String != "custom" || value.Field(PanicStructKey).String != "kaboom" {
+		t.Fatalf("value %v", value.ToAny())
+	}
+	if len(value.Fields) != 3 {
+		t.Fatalf("panic type emitted twice: %v", value.ToAny())
+	}
+}
@@ -0,0 +1,435 @@
+package golog
+
+import (
+	"regexp"
+	"strings"
+	"strconv"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+type RedactAction uint
+
+const (
+	RDA_MASK RedactAction = iota
+	RDA_HASH
+	RDA_DROP
+)
+
+const (
+	RedactHashPrefix = "hmac:"
+	DefaultRedactHashLength = 8
+)
+
+type RedactRule struct {
+	Path string
+	Key string
+	Pattern *regexp.Regexp
+	Action RedactAction
+}
+
+func(rule *RedactRule) matches(path []string, dotted string) bool {
+	if len(rule.Path) > 0 && !matchRedactPath(rule.Path, path) {
+		return false
+	}
+	if len(rule.Key) > 0 && (len(path) == 0 || !strings.EqualFold(rule.Key, path[len(path) - 1])) {
+		return false
+	}
+	if rule.Pattern != nil && !rule.Pattern.MatchString(dotted) {
+		return false
+	}
+	return len(rule.Path) > 0 || len(rule.Key) > 0 || rule.Pattern != nil
+}
+
+func matchRedactPath(pattern string, path []string) bool {
+	segments := strings.Split(pattern, ".")
+	if len(segments) != len(path) {
+		return false
+	}
+	for index, segment := range segments {
+		if segment != "*" && segment != path[index] {
+			return false
+		}
+	}
+	return true
+}
+
+type Scrubber struct {
+	Pattern *regexp.Regexp
+	Replacement string
+	Accept func(string) bool
+}
+
+func(scrubber *Scrubber) Scrub(text string) string {
+	if scrubber.Pattern == nil {
+		return text
+	}
+	if scrubber.Accept == nil {
+		return scrubber.Pattern.ReplaceAllString(text, scrubber.Replacement)
+	}
+	return scrubber.Pattern.ReplaceAllStringFunc(text, func(match string) string {
+		if !scrubber.Accept(match) {
+			return match
+		}
+		return scrubber.Pattern.ReplaceAllString(match, scrubber.Replacement)
+	})
+}
+
+var EmailScrubber = Scrubber {
+	Pattern: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+	Replacement: RedactedText,
+}
+
+var CardNumberScrubber = Scrubber {
+	Pattern: regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`),
+	Replacement: RedactedText,
+	Accept: PassesLuhn,
+}
+
+var BearerTokenScrubber = Scrubber {
+	Pattern: regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9\-._~+/]+=*`),
+	Replacement: "${1}" + RedactedText,
+}
+
+var DefaultScrubbers = []Scrubber {
+	BearerTokenScrubber,
+	EmailScrubber,
+	CardNumberScrubber,
+}
+
+func PassesLuhn(text string) bool {
+	var sum, count int
+	double := false
+	for index := len(text) - 1; index >= 0; index-- {
+		c := text[index]
+		if c < '0' || c > '9' {
+			continue
+		}
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+		count++
+	}
+	return count >= 13 && sum % 10 == 0
+}
+
+type RedactorError struct {
+	Rule int
+	Reason string
+}
+
+func(err *RedactorError) Error() string {
+	return "invalid redact rule " + strconv.Itoa(err.Rule) + ": " + err.Reason
+}
+
+type Redactor struct {
+	Rules []RedactRule
+	Scrubbers []Scrubber
+	ScrubValues bool
+	Mask string
+	HashKey []byte
+	HashLength int
+}
+
+func(redactor *Redactor) Validate() error {
+	for index := range redactor.Rules {
+		rule := &redactor.Rules[index]
+		if len(rule.Path) == 0 && len(rule.Key) == 0 && rule.Pattern == nil {
+			return &RedactorError {
+				Rule: index,
+				Reason: "rule has no path, key or pattern",
+			}
+		}
+		if rule.Action == RDA_HASH && len(redactor.HashKey) == 0 {
+			return &RedactorError {
+				Rule: index,
+				Reason: "hashing requires a hash key",
+			}
+		}
+	}
+	return nil
+}
+
+func(redactor *Redactor) ScrubText(text string) string {
+	for index := range redactor.Scrubbers {
+		text = redactor.Scrubbers[index].Scrub(text)
+	}
+	return text
+}
+
+func(redactor *Redactor) ScrubLines(lines []string) []string {
+	if len(redactor.Scrubbers) == 0 {
+		return lines
+	}
+	scrubbed := make([]string, len(lines))
+	for index, line := range lines {
+		scrubbed[index] = redactor.ScrubText(line)
+	}
+	return scrubbed
+}
+
+func(redactor *Redactor) Hash(text string) string {
+	mac := hmac.New(sha256.New, redactor.HashKey)
+	mac.Write([]byte(text))
+	sum := mac.Sum(nil)
+	length := redactor.HashLength
+	if length <= 0 {
+		length = DefaultRedactHashLength
+	}
+	if length > len(sum) {
+		length = len(sum)
+	}
+	return RedactHashPrefix + hex.EncodeToString(sum[:length])
+}
+
+func(redactor *Redactor) action(path []string) (RedactAction, bool) {
+	dotted := strings.Join(path, ".")
+	for index := range redactor.Rules {
+		if redactor.Rules[index].matches(path, dotted) {
+			return redactor.Rules[index].Action, true
+		}
+	}
+	return RDA_MASK, false
+}
+
+func(redactor *Redactor) replacement(action RedactAction, value *Value) *Value {
+	if action == RDA_HASH && len(redactor.HashKey) > 0 {
+		var text string
+		switch value.Kind {
+			case VAL_MAP, VAL_LIST:
+				sink := &JSONStructSink{}
+				value.PutStruct(sink)
+				text = sink.ToString()
+			default:
+				text = FormatScalarValue(value)
+		}
+		return StringValue(redactor.Hash(text))
+	}
+	mask := redactor.Mask
+	if len(mask) == 0 {
+		mask = RedactedText
+	}
+	return StringValue(mask)
+}
+
+func(redactor *Redactor) RedactValue(value *Value) *Value {
+	return redactor.redact(value, nil)
+}
+
+func(redactor *Redactor) redact(value *Value, path []string) *Value {
+	if value == nil {
+		return nil
+	}
+	switch value.Kind {
+		case VAL_MAP:
+			result := MapValue()
+			for _, field := range value.Fields {
+				child := redactor.redactChild(field.Value, append(path, field.Name))
+				if child != nil {
+					result.Fields = append(result.Fields, ValueField {
+						Name: field.Name,
+						Value: child,
+					})
+				}
+			}
+			return result
+		case VAL_LIST:
+			result := ListValue()
+			for index, item := range value.Items {
+				child := redactor.redactChild(item, append(path, strconv.Itoa(index)))
+				if child != nil {
+					result.Append(child)
+				}
+			}
+			return result
+		case VAL_STRING:
+			if redactor.ScrubValues {
+				return StringValue(redactor.ScrubText(value.String))
+			}
+			return value
+		default:
+			return value
+	}
+}
+
+func(redactor *Redactor) redactChild(value *Value, path []string) *Value {
+	action, matched := redactor.action(path)
+	switch {
+		case !matched:
+			return redactor.redact(value, path)
+		case action == RDA_DROP:
+			return nil
+		case value == nil:
+			return redactor.replacement(action, NullValue())
+		default:
+			return redactor.replacement(action, value)
+	}
+}
+
+func(redactor *Redactor) RedactStructure(structure Structure) Structure {
+	if structure == nil {
+		return nil
+	}
+	return &redactedStructure {
+		structure: structure,
+		redactor: redactor,
+	}
+}
+
+type redactedStructure struct {
+	structure Structure
+	redactor *Redactor
+}
+
+func(rs *redactedStructure) PutStruct(sink StructSink) {
+	rs.structure.PutStruct(&RedactingSink {
+		Target: sink,
+		Redactor: rs.redactor,
+	})
+}
+
+type RedactingSink struct {
+	Target StructSink
+	Redactor *Redactor
+}
+
+func(sink *RedactingSink) flush(captured *Value) {
+	if sink.Target == nil {
+		return
+	}
+	if sink.Redactor != nil {
+		captured = sink.Redactor.RedactValue(captured)
+	}
+	captured.PutStruct(sink.Target)
+}
+
+func(sink *RedactingSink) Map() StructMap {
+	root := &redactingMap {
+		sink: sink,
+	}
+	root.value = MapValue()
+	return root
+}
+
+func(sink *RedactingSink) List() StructList {
+	root := &redactingList {
+		sink: sink,
+	}
+	root.value = ListValue()
+	return root
+}
+
+type redactingMap struct {
+	captureMap
+	sink *RedactingSink
+}
+
+func(m *redactingMap) EndMap() {
+	m.sink.flush(m.value)
+}
+
+type redactingList struct {
+	captureList
+	sink *RedactingSink
+}
+
+func(l *redactingList) EndList() {
+	l.sink.flush(l.value)
+}
+
+type redactedMessage struct {
+	message Message
+	redactor *Redactor
+}
+
+func(msg *redactedMessage) Lines() []string {
+	return msg.redactor.ScrubLines(msg.message.Lines())
+}
+
+func(msg *redactedMessage) PutStruct(sink StructSink) {
+	msg.message.PutStruct(&RedactingSink {
+		Target: sink,
+		Redactor: msg.redactor,
+	})
+}
+
+func(redactor *Redactor) RedactMessage(msg Message) Message {
+	if msg == nil {
+		return nil
+	}
+	return &redactedMessage {
+		message: msg,
+		redactor: redactor,
+	}
+}
+
+type RedactingLogger struct {
+	ID uintptr
+	Logger Logger
+	Redactor *Redactor
+}
+
+func(logger *RedactingLogger) Log(packet *Packet) {
+	if logger.Logger == nil {
+		return
+	}
+	if packet == nil || packet.Message == nil || logger.Redactor == nil {
+		logger.Logger.Log(packet)
+		return
+	}
+	redacted := *packet
+	redacted.Message = &frozenMessage {
+		lines: logger.Redactor.ScrubLines(packet.Message.Lines()),
+		details: logger.Redactor.RedactValue(CaptureValue(packet.Message)),
+	}
+	logger.Logger.Log(&redacted)
+}
+
+func(logger *RedactingLogger) Enabled(level Level, source Source) bool {
+	return LoggerEnabled(logger.Logger, level, source)
+}
+
+func(logger *RedactingLogger) Close() {
+	if logger.Logger != nil {
+		logger.Logger.Close()
+	}
+}
+
+func(logger *RedactingLogger) SubLoggers() []Logger {
+	if logger.Logger == nil {
+		return nil
+	}
+	return []Logger { logger.Logger }
+}
+
+func(logger *RedactingLogger) Identity() uintptr {
+	return logger.ID
+}
+
+type frozenMessage struct {
+	lines []string
+	details *Value
+}
+
+func(msg *frozenMessage) Lines() []string {
+	return msg.lines
+}
+
+func(msg *frozenMessage) PutStruct(sink StructSink) {
+	msg.details.PutStruct(sink)
+}
+
+var _ Logger = &RedactingLogger{}
+var _ EnabledChecker = &RedactingLogger{}
+var _ StructSink = &RedactingSink{}
+var _ ExtendedStructMap = &redactingMap{}
+var _ ExtendedStructList = &redactingList{}
+var _ Message = &redactedMessage{}
+var _ Message = &frozenMessage{}
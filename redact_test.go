@@ -0,0 +1,177 @@
+package golog
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const redactSecret = "hunter2@example.com"
+
+func secretErrorPacket() *Packet {
+	details := MapValue()
+	details.Set("password", StringValue("pw"))
+	err := errors.New("login failed for " + redactSecret)
+	return &Packet {
+		Level: ERROR,
+		Source: TextSource("auth"),
+		Message: NewErrorMessage(errors.Join(err, errors.New("token bearer abc.def")), details),
+	}
+}
+
+func redactingFixture() (*RedactingLogger, *captureLogger) {
+	target := newCaptureLogger()
+	return &RedactingLogger {
+		ID: NewLoggerID(),
+		Logger: target,
+		Redactor: &Redactor {
+			Rules: []RedactRule {
+				{
+					Key: "password",
+				},
+			},
+			Scrubbers: DefaultScrubbers,
+			ScrubValues: true,
+		},
+	}, target
+}
+
+func TestRedactingLoggerDoesNotExposeOriginal(t *testing.T) {
+	logger, target := redactingFixture()
+	logger.Log(secretErrorPacket())
+	packet := target.Last().Packet
+	if _, ok := FindMessage[*ErrorMessage](packet.Message); ok {
+		t.Fatal("original error message reachable through redacted packet")
+	}
+	otel := (&OTelEncoder{}).PacketToText(packet)[0]
+	causes := CauseTextFormatter {
+		CausePrefix: "caused by: ",
+	}.PacketToText(packet)
+	texts := map[string]string {
+		"otel": otel,
+		"causes": strings.Join(causes, "\n"),
+		"json": JSONPacketEncoder{}.PacketToText(packet)[0],
+	}
+	for name, text := range texts {
+		if strings.Contains(text, redactSecret) || strings.Contains(text, "abc.def") || strings.Contains(text, "\"pw\"") {
+			t.Errorf("%s leaks secret: %s", name, text)
+		}
+	}
+	if !strings.Contains(texts["causes"], "login failed for " + RedactedText) {
+		t.Errorf("scrubbed cause text missing: %q", causes)
+	}
+}
+
+func TestRedactedMessageDoesNotUnwrap(t *testing.T) {
+	redactor := &Redactor {
+		Scrubbers: DefaultScrubbers,
+	}
+	msg := redactor.RedactMessage(secretErrorPacket().Message)
+	if _, ok := msg.(MessageWrapper); ok {
+		t.Fatal("redacted message unwraps to its original")
+	}
+	if _, ok := FindMessage[CausalMessage](msg); ok {
+		t.Fatal("causal original reachable through redacted message")
+	}
+	for _, line := range msg.Lines() {
+		if strings.Contains(line, redactSecret) {
+			t.Fatalf("line leaks secret: %q", line)
+		}
+	}
+}
+
+func TestRedactorActions(t *testing.T) {
+	redactor := &Redactor {
+		Rules: []RedactRule {
+			{
+				Key: "token",
+				Action: RDA_HASH,
+			},
+			{
+				Path: "user.*.ssn",
+				Action: RDA_DROP,
+			},
+			{
+				Pattern: regexp.MustCompile(`^card\.`),
+			},
+		},
+		HashKey: []byte("k"),
+		Mask: "###",
+	}
+	if err := redactor.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	input := FromMap(map[string]any {
+		"Token": "abc",
+		"user": map[string]any {
+			"bob": map[string]any {
+				"ssn": "123",
+				"name": "Bob",
+			},
+		},
+		"card": map[string]any {
+			"number": "4111",
+		},
+	})
+	output := redactor.RedactValue(input)
+	if token := output.Field("Token").String; token != redactor.Hash("abc") || !strings.HasPrefix(token, RedactHashPrefix) {
+		t.Errorf("token: %q", token)
+	}
+	if output.LookupPath([]string { "user", "bob", "ssn" }) != nil || output.Lookup("user.bob.name").String != "Bob" {
+		t.Errorf("drop: %v", output.ToAny())
+	}
+	if output.Lookup("card.number").String != "###" {
+		t.Errorf("mask: %v", output.ToAny())
+	}
+	if input.Field("Token").String != "abc" {
+		t.Error("input modified")
+	}
+}
+
+func TestRedactorRejectsUnkeyedHash(t *testing.T) {
+	redactor := &Redactor {
+		Rules: []RedactRule {
+			{
+				Key: "token",
+				Action: RDA_HASH,
+			},
+		},
+	}
+	err := redactor.Validate()
+	if err == nil {
+		t.Fatal("hash rule without key accepted")
+	}
+	var redactErr *RedactorError
+	if !errors.As(err, &redactErr) || redactErr.Rule != 0 {
+		t.Fatalf("got %v", err)
+	}
+	input := MapValue()
+	input.Set("token", StringValue("abc"))
+	if got := redactor.RedactValue(input).Field("token").String; got != RedactedText {
+		t.Fatalf("unkeyed hash not masked: %q", got)
+	}
+	empty := &Redactor {
+		Rules: []RedactRule { {} },
+	}
+	if empty.Validate() == nil {
+		t.Fatal("rule matching nothing accepted")
+	}
+}
+
+func TestScrubbers(t *testing.T) {
+	cases := map[string]string {
+		"mail bob@example.org now": "mail " + RedactedText + " now",
+		"Authorization: Bearer abc.DEF-1=": "Authorization: Bearer " + RedactedText,
+		"card 4111 1111 1111 1111 ok": "card " + RedactedText + " ok",
+		"order 1234567890123 ok": "order 1234567890123 ok",
+	}
+	redactor := &Redactor {
+		Scrubbers: DefaultScrubbers,
+	}
+	for input, want := range cases {
+		if got := redactor.ScrubText(input); got != want {
+			t.Errorf("%q: got %q, want %q", input, got, want)
+		}
+	}
+}
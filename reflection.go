@@ -0,0 +1,728 @@
+package golog
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"reflect"
+	"strings"
+	"encoding"
+)
+
+const (
+	ScalarStructKey = "value"
+	RedactedText = "[REDACTED]"
+	TruncatedText = "..."
+	CycleText = "<cycle>"
+)
+
+type StructEncoder struct {
+	MaxDepth int
+	MaxLength int
+}
+
+var DumbStructEncoder *StructEncoder = &StructEncoder {
+	MaxDepth: 16,
+	MaxLength: 256,
+}
+
+func Struct(value any) Structure {
+	return DumbStructEncoder.Struct(value)
+}
+
+func(enc *StructEncoder) Struct(value any) Structure {
+	return &reflectStructure {
+		encoder: enc,
+		value: value,
+	}
+}
+
+func(enc *StructEncoder) PutProperty(m StructMap, name string, value any) {
+	enc.put(&propertyTarget {
+		target: m,
+		name: name,
+	}, value)
+}
+
+func(enc *StructEncoder) PutElement(l StructList, value any) {
+	enc.put(&elementTarget {
+		target: l,
+	}, value)
+}
+
+func(enc *StructEncoder) put(target valueTarget, value any) {
+	if value == nil {
+		target.Null()
+		return
+	}
+	state := &encodeState {
+		encoder: enc,
+	}
+	v := reflect.ValueOf(value)
+	encoderFor(v.Type())(state, target, v)
+}
+
+type reflectStructure struct {
+	encoder *StructEncoder
+	value any
+}
+
+func(structure *reflectStructure) PutStruct(sink StructSink) {
+	if structure.value == nil {
+		return
+	}
+	enc := structure.encoder
+	if enc == nil {
+		enc = &StructEncoder{}
+	}
+	root := &rootTarget {
+		sink: sink,
+	}
+	enc.put(root, structure.value)
+	root.finish()
+}
+
+type valueTarget interface {
+	Bool(bool)
+	String(string)
+	Int(int64)
+	Float(float64)
+	Time(time.Time)
+	Duration(time.Duration)
+	Bytes([]byte)
+	Uint(uint64)
+	Null()
+	Map() StructMap
+	List() StructList
+	Structure(Structure)
+}
+
+type propertyTarget struct {
+	target StructMap
+	name string
+}
+
+func(target *propertyTarget) Bool(value bool) {
+	target.target.BoolProperty(target.name, value)
+}
+
+func(target *propertyTarget) String(value string) {
+	target.target.StringProperty(target.name, value)
+}
+
+func(target *propertyTarget) Int(value int64) {
+	target.target.IntProperty(target.name, value)
+}
+
+func(target *propertyTarget) Float(value float64) {
+	target.target.FloatProperty(target.name, value)
+}
+
+func(target *propertyTarget) Time(value time.Time) {
+	PutTimeProperty(target.target, target.name, value)
+}
+
+func(target *propertyTarget) Duration(value time.Duration) {
+	PutDurationProperty(target.target, target.name, value)
+}
+
+func(target *propertyTarget) Bytes(value []byte) {
+	PutBytesProperty(target.target, target.name, value)
+}
+
+func(target *propertyTarget) Uint(value uint64) {
+	PutUintProperty(target.target, target.name, value)
+}
+
+func(target *propertyTarget) Null() {
+	PutNullProperty(target.target, target.name)
+}
+
+func(target *propertyTarget) Map() StructMap {
+	return target.target.MapProperty(target.name)
+}
+
+func(target *propertyTarget) List() StructList {
+	return target.target.ListProperty(target.name)
+}
+
+func(target *propertyTarget) Structure(structure Structure) {
+	structure.PutStruct(&propertySink {
+		target: target.target,
+		name: target.name,
+	})
+}
+
+type elementTarget struct {
+	target StructList
+}
+
+func(target *elementTarget) Bool(value bool) {
+	target.target.Bool(value)
+}
+
+func(target *elementTarget) String(value string) {
+	target.target.String(value)
+}
+
+func(target *elementTarget) Int(value int64) {
+	target.target.Int(value)
+}
+
+func(target *elementTarget) Float(value float64) {
+	target.target.Float(value)
+}
+
+func(target *elementTarget) Time(value time.Time) {
+	PutTime(target.target, value)
+}
+
+func(target *elementTarget) Duration(value time.Duration) {
+	PutDuration(target.target, value)
+}
+
+func(target *elementTarget) Bytes(value []byte) {
+	PutBytes(target.target, value)
+}
+
+func(target *elementTarget) Uint(value uint64) {
+	PutUint(target.target, value)
+}
+
+func(target *elementTarget) Null() {
+	PutNull(target.target)
+}
+
+func(target *elementTarget) Map() StructMap {
+	return target.target.Map()
+}
+
+func(target *elementTarget) List() StructList {
+	return target.target.List()
+}
+
+func(target *elementTarget) Structure(structure Structure) {
+	structure.PutStruct(target.target)
+}
+
+type rootTarget struct {
+	sink StructSink
+	wrapper StructMap
+}
+
+func(target *rootTarget) scalar() StructMap {
+	target.wrapper = target.sink.Map()
+	return target.wrapper
+}
+
+func(target *rootTarget) finish() {
+	if target.wrapper != nil {
+		target.wrapper.EndMap()
+		target.wrapper = nil
+	}
+}
+
+func(target *rootTarget) Bool(value bool) {
+	target.scalar().BoolProperty(ScalarStructKey, value)
+}
+
+func(target *rootTarget) String(value string) {
+	target.scalar().StringProperty(ScalarStructKey, value)
+}
+
+func(target *rootTarget) Int(value int64) {
+	target.scalar().IntProperty(ScalarStructKey, value)
+}
+
+func(target *rootTarget) Float(value float64) {
+	target.scalar().FloatProperty(ScalarStructKey, value)
+}
+
+func(target *rootTarget) Time(value time.Time) {
+	PutTimeProperty(target.scalar(), ScalarStructKey, value)
+}
+
+func(target *rootTarget) Duration(value time.Duration) {
+	PutDurationProperty(target.scalar(), ScalarStructKey, value)
+}
+
+func(target *rootTarget) Bytes(value []byte) {
+	PutBytesProperty(target.scalar(), ScalarStructKey, value)
+}
+
+func(target *rootTarget) Uint(value uint64) {
+	PutUintProperty(target.scalar(), ScalarStructKey, value)
+}
+
+func(target *rootTarget) Null() {
+	PutNullProperty(target.scalar(), ScalarStructKey)
+}
+
+func(target *rootTarget) Map() StructMap {
+	return target.sink.Map()
+}
+
+func(target *rootTarget) List() StructList {
+	return target.sink.List()
+}
+
+func(target *rootTarget) Structure(structure Structure) {
+	structure.PutStruct(target.sink)
+}
+
+type encodeState struct {
+	encoder *StructEncoder
+	depth int
+	active map[any]bool
+}
+
+type valueIdentity struct {
+	kind reflect.Type
+	pointer uintptr
+	length int
+}
+
+func(state *encodeState) enter(v reflect.Value) (any, bool) {
+	var key any
+	switch v.Kind() {
+		case reflect.Pointer, reflect.Map:
+			key = valueIdentity {
+				kind: v.Type(),
+				pointer: v.Pointer(),
+			}
+		case reflect.Slice:
+			key = valueIdentity {
+				kind: v.Type(),
+				pointer: v.Pointer(),
+				length: v.Len(),
+			}
+	}
+	if key != nil {
+		if state.active[key] {
+			return nil, false
+		}
+		if state.active == nil {
+			state.active = make(map[any]bool)
+		}
+		state.active[key] = true
+	}
+	return key, true
+}
+
+func(state *encodeState) leave(key any) {
+	if key != nil {
+		delete(state.active, key)
+	}
+}
+
+func(state *encodeState) descend(target valueTarget) bool {
+	if state.encoder.MaxDepth > 0 && state.depth >= state.encoder.MaxDepth {
+		target.String(TruncatedText)
+		return false
+	}
+	state.depth++
+	return true
+}
+
+func(state *encodeState) ascend() {
+	state.depth--
+}
+
+func(state *encodeState) limit(length int) (int, int) {
+	maximum := state.encoder.MaxLength
+	if maximum <= 0 || length <= maximum {
+		return length, 0
+	}
+	return maximum, length - maximum
+}
+
+type valueEncoder func(*encodeState, valueTarget, reflect.Value)
+
+var encoderCache sync.Map
+
+var (
+	structureType = reflect.TypeOf((*Structure)(nil)).Elem()
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	timeType = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+func encoderFor(t reflect.Type) valueEncoder {
+	if cached, ok := encoderCache.Load(t); ok {
+		return cached.(valueEncoder)
+	}
+	actual, _ := encoderCache.LoadOrStore(t, newEncoder(t))
+	return actual.(valueEncoder)
+}
+
+func newEncoder(t reflect.Type) valueEncoder {
+	switch {
+		case t == timeType:
+			return encodeTime
+		case t == durationType:
+			return encodeDuration
+		case t.Implements(structureType):
+			return nilSafe(t, encodeStructure)
+		case t.Implements(errorType):
+			return nilSafe(t, encodeError)
+		case t.Implements(stringerType):
+			return nilSafe(t, encodeStringer)
+		case t.Implements(textMarshalerType):
+			return nilSafe(t, encodeTextMarshaler)
+	}
+	switch t.Kind() {
+		case reflect.Bool:
+			return encodeBool
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return encodeInt
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return encodeUint
+		case reflect.Float32, reflect.Float64:
+			return encodeFloat
+		case reflect.String:
+			return encodeString
+		case reflect.Pointer:
+			return encodePointer
+		case reflect.Interface:
+			return encodeInterface
+		case reflect.Struct:
+			return newStructEncoder(t)
+		case reflect.Map:
+			return encodeMap
+		case reflect.Slice:
+			if t.Elem().Kind() == reflect.Uint8 {
+				return encodeBytes
+			}
+			return encodeList
+		case reflect.Array:
+			return encodeList
+		default:
+			return encodeOpaque
+	}
+}
+
+func nilSafe(t reflect.Type, encoder valueEncoder) valueEncoder {
+	switch t.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+			return func(state *encodeState, target valueTarget, v reflect.Value) {
+				if v.IsNil() {
+					target.Null()
+				} else {
+					encoder(state, target, v)
+				}
+			}
+		default:
+			return encoder
+	}
+}
+
+func encodeTime(state *encodeState, target valueTarget, v reflect.Value) {
+	if !v.CanInterface() {
+		encodeOpaque(state, target, v)
+		return
+	}
+	target.Time(v.Interface().(time.Time))
+}
+
+func encodeDuration(state *encodeState, target valueTarget, v reflect.Value) {
+	target.Duration(time.Duration(v.Int()))
+}
+
+func encodeStructure(state *encodeState, target valueTarget, v reflect.Value) {
+	if !v.CanInterface() {
+		encodeOpaque(state, target, v)
+		return
+	}
+	target.Structure(v.Interface().(Structure))
+}
+
+func encodeError(state *encodeState, target valueTarget, v reflect.Value) {
+	if !v.CanInterface() {
+		encodeOpaque(state, target, v)
+		return
+	}
+	target.String(v.Interface().(error).Error())
+}
+
+func encodeStringer(state *encodeState, target valueTarget, v reflect.Value) {
+	if !v.CanInterface() {
+		encodeOpaque(state, target, v)
+		return
+	}
+	target.String(v.Interface().(fmt.Stringer).String())
+}
+
+func encodeTextMarshaler(state *encodeState, target valueTarget, v reflect.Value) {
+	if !v.CanInterface() {
+		encodeOpaque(state, target, v)
+		return
+	}
+	text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		target.String(err.Error())
+	} else {
+		target.String(string(text))
+	}
+}
+
+func encodeBool(state *encodeState, target valueTarget, v reflect.Value) {
+	target.Bool(v.Bool())
+}
+
+func encodeInt(state *encodeState, target valueTarget, v reflect.Value) {
+	target.Int(v.Int())
+}
+
+func encodeUint(state *encodeState, target valueTarget, v reflect.Value) {
+	target.Uint(v.Uint())
+}
+
+func encodeFloat(state *encodeState, target valueTarget, v reflect.Value) {
+	target.Float(v.Float())
+}
+
+func encodeString(state *encodeState, target valueTarget, v reflect.Value) {
+	target.String(v.String())
+}
+
+func encodeBytes(state *encodeState, target valueTarget, v reflect.Value) {
+	if v.IsNil() {
+		target.Null()
+		return
+	}
+	target.Bytes(v.Bytes())
+}
+
+func encodeOpaque(state *encodeState, target valueTarget, v reflect.Value) {
+	target.String(fmt.Sprintf("<%s>", v.Type().String()))
+}
+
+func encodePointer(state *encodeState, target valueTarget, v reflect.Value) {
+	if v.IsNil() {
+		target.Null()
+		return
+	}
+	key, ok := state.enter(v)
+	if !ok {
+		target.String(CycleText)
+		return
+	}
+	elem := v.Elem()
+	encoderFor(elem.Type())(state, target, elem)
+	state.leave(key)
+}
+
+func encodeInterface(state *encodeState, target valueTarget, v reflect.Value) {
+	if v.IsNil() {
+		target.Null()
+		return
+	}
+	elem := v.Elem()
+	encoderFor(elem.Type())(state, target, elem)
+}
+
+func encodeList(state *encodeState, target valueTarget, v reflect.Value) {
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		target.Null()
+		return
+	}
+	key, ok := state.enter(v)
+	if !ok {
+		target.String(CycleText)
+		return
+	}
+	defer state.leave(key)
+	if !state.descend(target) {
+		return
+	}
+	defer state.ascend()
+	count, rest := state.limit(v.Len())
+	list := target.List()
+	element := &elementTarget {
+		target: list,
+	}
+	for index := 0; index < count; index++ {
+		item := v.Index(index)
+		encoderFor(item.Type())(state, element, item)
+	}
+	if rest > 0 {
+		list.String(fmt.Sprintf("%s %d more", TruncatedText, rest))
+	}
+	list.EndList()
+}
+
+func encodeMap(state *encodeState, target valueTarget, v reflect.Value) {
+	if v.IsNil() {
+		target.Null()
+		return
+	}
+	key, ok := state.enter(v)
+	if !ok {
+		target.String(CycleText)
+		return
+	}
+	defer state.leave(key)
+	if !state.descend(target) {
+		return
+	}
+	defer state.ascend()
+	type entry struct {
+		name string
+		value reflect.Value
+	}
+	var entries []entry
+	iter := v.MapRange()
+	for iter.Next() {
+		mapKey := iter.Key()
+		var name string
+		if mapKey.Kind() == reflect.String {
+			name = mapKey.String()
+		} else if mapKey.CanInterface() {
+			name = fmt.Sprint(mapKey.Interface())
+		} else {
+			continue
+		}
+		entries = append(entries, entry {
+			name: name,
+			value: iter.Value(),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+	count, rest := state.limit(len(entries))
+	m := target.Map()
+	property := &propertyTarget {
+		target: m,
+	}
+	for _, entry := range entries[:count] {
+		property.name = entry.name
+		encoderFor(entry.value.Type())(state, property, entry.value)
+	}
+	if rest > 0 {
+		m.StringProperty(TruncatedText, fmt.Sprintf("%d more", rest))
+	}
+	m.EndMap()
+}
+
+type structField struct {
+	index []int
+	name string
+	omitEmpty bool
+	redact bool
+}
+
+func newStructEncoder(t reflect.Type) valueEncoder {
+	fields := collectStructFields(t, nil, map[reflect.Type]bool {
+		t: true,
+	})
+	return func(state *encodeState, target valueTarget, v reflect.Value) {
+		if !state.descend(target) {
+			return
+		}
+		defer state.ascend()
+		m := target.Map()
+		property := &propertyTarget {
+			target: m,
+		}
+		for _, field := range fields {
+			if state.encoder.MaxDepth > 0 && len(field.index) > state.encoder.MaxDepth {
+				continue
+			}
+			value, ok := fieldByIndex(v, field.index)
+			if !ok || field.omitEmpty && isEmptyValue(value) {
+				continue
+			}
+			if field.redact {
+				m.StringProperty(field.name, RedactedText)
+				continue
+			}
+			property.name = field.name
+			encoderFor(value.Type())(state, property, value)
+		}
+		m.EndMap()
+	}
+}
+
+func collectStructFields(t reflect.Type, prefix []int, visiting map[reflect.Type]bool) []structField {
+	var fields []structField
+	for index := 0; index < t.NumField(); index++ {
+		field := t.Field(index)
+		tag := field.Tag.Get("golog")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		path := make([]int, len(prefix) + 1)
+		copy(path, prefix)
+		path[len(prefix)] = index
+		if field.Anonymous && len(name) == 0 {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if !visiting[embedded] && (field.IsExported() || field.Type.Kind() != reflect.Pointer) {
+					visiting[embedded] = true
+					fields = append(fields, collectStructFields(embedded, path, visiting)...)
+					delete(visiting, embedded)
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if len(name) == 0 {
+			name = field.Name
+		}
+		info := structField {
+			index: path,
+			name: name,
+		}
+		for len(options) > 0 {
+			var option string
+			option, options, _ = strings.Cut(options, ",")
+			switch strings.TrimSpace(option) {
+				case "omitempty":
+					info.omitEmpty = true
+				case "redact":
+					info.redact = true
+			}
+		}
+		fields = append(fields, info)
+	}
+	return fields
+}
+
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for position, step := range index {
+		if position > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(step)
+	}
+	return v, true
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+		case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+			return v.Len() == 0
+		case reflect.Pointer, reflect.Interface:
+			return v.IsNil()
+		default:
+			return v.IsZero()
+	}
+}
+
+var _ Structure = &reflectStructure{}
+var _ valueTarget = &propertyTarget{}
+var _ valueTarget = &elementTarget{}
+var _ valueTarget = &rootTarget{}
@@ -0,0 +1,167 @@
+package golog
+
+import (
+	"time"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type reflectInner struct {
+	Port int
+}
+
+type reflectOuter struct {
+	reflectInner
+	Name string `golog:"name"`
+	Secret string `golog:"secret,redact"`
+	Skipped string `golog:"-"`
+	Empty string `golog:",omitempty"`
+	hidden string
+	When time.Time
+	Took time.Duration
+	Err error
+	Tags []string
+}
+
+type reflectNode struct {
+	*reflectNode
+	Name string
+}
+
+type reflectLinked struct {
+	Name string
+	Next *reflectLinked
+}
+
+func captureStruct(t *testing.T, encoder *StructEncoder, value any) *Value {
+	t.Helper()
+	done := make(chan *Value, 1)
+	go func() {
+		done <- CaptureValue(encoder.Struct(value))
+	}()
+	select {
+		case captured := <-done:
+			return captured
+		case <-time.After(5 * time.Second):
+			t.Fatal("encoding did not terminate")
+			return nil
+	}
+}
+
+func TestStructEncoderTagsAndEmbedding(t *testing.T) {
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	value := captureStruct(t, DumbStructEncoder, reflectOuter {
+		reflectInner: reflectInner {
+			Port: 8080,
+		},
+		Name: "svc",
+		Secret: "hunter2",
+		Skipped: "nope",
+		hidden: "nope",
+		When: when,
+		Took: time.Second,
+		Err: errors.New("broken"),
+		Tags: []string { "a", "b" },
+	})
+	want := map[string]any {
+		"Port": int64(8080),
+		"name": "svc",
+		"secret": RedactedText,
+		"When": when,
+		"Took": time.Second,
+		"Err": "broken",
+		"Tags": []any { "a", "b" },
+	}
+	if got := value.ToMap(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v\nwant %#v", got, want)
+	}
+}
+
+func TestStructEncoderRecursiveEmbeddingTerminates(t *testing.T) {
+	value := captureStruct(t, DumbStructEncoder, reflectNode {
+		Name: "root",
+	})
+	if got := value.ToMap(); !reflect.DeepEqual(got, map[string]any { "Name": "root" }) {
+		t.Fatalf("got %#v", got)
+	}
+	nested := &reflectNode {
+		Name: "child",
+	}
+	value = captureStruct(t, DumbStructEncoder, reflectNode {
+		reflectNode: nested,
+		Name: "root",
+	})
+	if got := value.Field("Name"); got == nil || got.String != "root" {
+		t.Fatalf("got %#v", value.ToAny())
+	}
+}
+
+func TestStructEncoderEmbeddingRespectsMaxDepth(t *testing.T) {
+	value := captureStruct(t, &StructEncoder {
+		MaxDepth: 1,
+	}, reflectOuter {
+		reflectInner: reflectInner {
+			Port: 8080,
+		},
+		Name: "svc",
+	})
+	if value.Field("Port") != nil {
+		t.Fatalf("embedded field beyond MaxDepth was encoded: %#v", value.ToAny())
+	}
+	if got := value.Field("name"); got == nil || got.String != "svc" {
+		t.Fatalf("top-level field lost: %#v", value.ToAny())
+	}
+}
+
+func TestStructEncoderPointerCycle(t *testing.T) {
+	node := &reflectLinked {
+		Name: "a",
+	}
+	node.Next = node
+	value := captureStruct(t, DumbStructEncoder, node)
+	if got := value.LookupPath([]string { "Next" }); got == nil || got.String != CycleText {
+		t.Fatalf("cycle not marked: %#v", value.ToAny())
+	}
+}
+
+func TestStructEncoderLimits(t *testing.T) {
+	encoder := &StructEncoder {
+		MaxDepth: 2,
+		MaxLength: 2,
+	}
+	value := captureStruct(t, encoder, map[string]any {
+		"list": []int { 1, 2, 3, 4 },
+		"deep": map[string]any {
+			"deeper": map[string]any {
+				"x": 1,
+			},
+		},
+	})
+	list := value.Field("list")
+	if list == nil || len(list.Items) != 3 || list.Items[2].String != TruncatedText + " 2 more" {
+		t.Fatalf("list not truncated: %#v", value.ToAny())
+	}
+	if got := value.LookupPath([]string { "deep", "deeper" }); got == nil || got.String != TruncatedText {
+		t.Fatalf("depth not truncated: %#v", value.ToAny())
+	}
+	unlimited := captureStruct(t, &StructEncoder{}, []int { 1, 2, 3, 4 })
+	if len(unlimited.Items) != 4 {
+		t.Fatalf("zero limits should not truncate: %#v", unlimited.ToAny())
+	}
+}
+
+func TestStructEncoderScalarRootAndNil(t *testing.T) {
+	value := captureStruct(t, DumbStructEncoder, 42)
+	if got := value.Field(ScalarStructKey); got == nil || got.Int != 42 {
+		t.Fatalf("scalar root not wrapped: %#v", value.ToAny())
+	}
+	if CaptureValue(Struct(nil)) != nil {
+		t.Fatal("nil value should produce no structure")
+	}
+	var missing *reflectLinked
+	value = captureStruct(t, DumbStructEncoder, missing)
+	if got := value.Field(ScalarStructKey); got == nil || got.Kind != VAL_NULL {
+		t.Fatalf("nil pointer should be null: %#v", value.ToAny())
+	}
+}
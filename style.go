@@ -0,0 +1,424 @@
+package golog
+
+import (
+	"io"
+	"os"
+	"math"
+	"bytes"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type ColorKind uint
+
+const (
+	CLR_DEFAULT ColorKind = iota
+	CLR_BASIC
+	CLR_INDEXED
+	CLR_RGB
+)
+
+const (
+	ANSI_BLACK uint8 = iota
+	ANSI_RED
+	ANSI_GREEN
+	ANSI_YELLOW
+	ANSI_BLUE
+	ANSI_MAGENTA
+	ANSI_CYAN
+	ANSI_WHITE
+	ANSI_BRIGHT_BLACK
+	ANSI_BRIGHT_RED
+	ANSI_BRIGHT_GREEN
+	ANSI_BRIGHT_YELLOW
+	ANSI_BRIGHT_BLUE
+	ANSI_BRIGHT_MAGENTA
+	ANSI_BRIGHT_CYAN
+	ANSI_BRIGHT_WHITE
+)
+
+type Color struct {
+	Kind ColorKind
+	Index uint8
+	Red uint8
+	Green uint8
+	Blue uint8
+}
+
+func BasicColor(index uint8) Color {
+	return Color {
+		Kind: CLR_BASIC,
+		Index: index & 0x0F,
+	}
+}
+
+func IndexedColor(index uint8) Color {
+	return Color {
+		Kind: CLR_INDEXED,
+		Index: index,
+	}
+}
+
+func RGBColor(red, green, blue uint8) Color {
+	return Color {
+		Kind: CLR_RGB,
+		Red: red,
+		Green: green,
+		Blue: blue,
+	}
+}
+
+type ColorDepth uint
+
+const (
+	DEPTH_AUTO ColorDepth = iota
+	DEPTH_16
+	DEPTH_256
+	DEPTH_TRUECOLOR
+)
+
+func(color Color) downgrade(depth ColorDepth) Color {
+	switch color.Kind {
+		case CLR_RGB:
+			switch depth {
+				case DEPTH_256:
+					return IndexedColor(rgbToIndexed(color.Red, color.Green, color.Blue))
+				case DEPTH_16:
+					return BasicColor(rgbToBasic(color.Red, color.Green, color.Blue))
+			}
+		case CLR_INDEXED:
+			if depth == DEPTH_16 {
+				if color.Index < 16 {
+					return BasicColor(color.Index)
+				}
+				red, green, blue := indexedToRGB(color.Index)
+				return BasicColor(rgbToBasic(red, green, blue))
+			}
+	}
+	return color
+}
+
+func rgbToIndexed(red, green, blue uint8) uint8 {
+	scale := func(component uint8) int {
+		return (int(component) * 5 + 127) / 255
+	}
+	return uint8(16 + 36 * scale(red) + 6 * scale(green) + scale(blue))
+}
+
+func indexedToRGB(index uint8) (uint8, uint8, uint8) {
+	switch {
+		case index >= 232:
+			gray := uint8(8 + 10 * int(index - 232))
+			return gray, gray, gray
+		case index >= 16:
+			cube := int(index) - 16
+			level := func(step int) uint8 {
+				if step == 0 {
+					return 0
+				}
+				return uint8(55 + 40 * step)
+			}
+			return level(cube / 36), level(cube / 6 % 6), level(cube % 6)
+		default:
+			return 0, 0, 0
+	}
+}
+
+func rgbToBasic(red, green, blue uint8) uint8 {
+	var index uint8
+	if red > 127 {
+		index |= 1
+	}
+	if green > 127 {
+		index |= 2
+	}
+	if blue > 127 {
+		index |= 4
+	}
+	if red > 191 || green > 191 || blue > 191 {
+		index += 8
+	}
+	return index
+}
+
+func(color Color) appendCode(codes []string, background bool) []string {
+	switch color.Kind {
+		case CLR_BASIC:
+			base := 30
+			if color.Index >= 8 {
+				base = 90
+			}
+			if background {
+				base += 10
+			}
+			return append(codes, strconv.Itoa(base + int(color.Index & 0x07)))
+		case CLR_INDEXED:
+			lead := "38;5;"
+			if background {
+				lead = "48;5;"
+			}
+			return append(codes, lead + strconv.Itoa(int(color.Index)))
+		case CLR_RGB:
+			lead := "38;2;"
+			if background {
+				lead = "48;2;"
+			}
+			return append(codes, lead + strconv.Itoa(int(color.Red)) + ";" + strconv.Itoa(int(color.Green)) +
+					";" + strconv.Itoa(int(color.Blue)))
+		default:
+			return codes
+	}
+}
+
+type Style struct {
+	Foreground Color
+	Background Color
+	Bold bool
+	Faint bool
+	Italic bool
+	Underline bool
+}
+
+const StyleReset = "\x1b[0m"
+
+func(style *Style) Sequence(depth ColorDepth) string {
+	var codes []string
+	if style.Bold {
+		codes = append(codes, "1")
+	}
+	if style.Faint {
+		codes = append(codes, "2")
+	}
+	if style.Italic {
+		codes = append(codes, "3")
+	}
+	if style.Underline {
+		codes = append(codes, "4")
+	}
+	codes = style.Foreground.downgrade(depth).appendCode(codes, false)
+	codes = style.Background.downgrade(depth).appendCode(codes, true)
+	if len(codes) == 0 {
+		return ""
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m"
+}
+
+type LevelStyle struct {
+	Min int
+	Max int
+	Style Style
+}
+
+type Palette []LevelStyle
+
+func(palette Palette) StyleFor(level Level) *Style {
+	if level == nil {
+		return nil
+	}
+	numerical := level.Numerical()
+	for index := range palette {
+		if numerical >= palette[index].Min && numerical <= palette[index].Max {
+			return &palette[index].Style
+		}
+	}
+	return nil
+}
+
+var DumbPalette Palette = Palette {
+	{
+		Min: int(WARNING),
+		Max: int(WARNING),
+		Style: Style {
+			Foreground: BasicColor(ANSI_YELLOW),
+		},
+	},
+	{
+		Min: int(ERROR),
+		Max: int(MISUSE),
+		Style: Style {
+			Foreground: BasicColor(ANSI_RED),
+		},
+	},
+	{
+		Min: int(FATAL),
+		Max: math.MaxInt,
+		Style: Style {
+			Foreground: BasicColor(ANSI_RED),
+			Bold: true,
+		},
+	},
+}
+
+type StyleMode uint
+
+const (
+	STY_AUTO StyleMode = iota
+	STY_ALWAYS
+	STY_NEVER
+)
+
+type StyledLineFormatter struct {
+	Formatter LineFormatter
+	Palette Palette
+	Mode StyleMode
+	Depth ColorDepth
+}
+
+func(form *StyledLineFormatter) PacketToLine(packet *Packet, builder *strings.Builder) {
+	if form.Formatter == nil {
+		return
+	}
+	var style *Style
+	switch form.Mode {
+		case STY_AUTO:
+			if AutoStyles() {
+				style = form.style(packet)
+			}
+		case STY_ALWAYS:
+			style = form.style(packet)
+	}
+	if style == nil {
+		form.Formatter.PacketToLine(packet, builder)
+		return
+	}
+	depth := form.Depth
+	if depth == DEPTH_AUTO {
+		depth = DetectColorDepth()
+	}
+	sequence := style.Sequence(depth)
+	if len(sequence) == 0 {
+		form.Formatter.PacketToLine(packet, builder)
+		return
+	}
+	var inner strings.Builder
+	form.Formatter.PacketToLine(packet, &inner)
+	if inner.Len() == 0 {
+		return
+	}
+	builder.WriteString(sequence)
+	builder.WriteString(inner.String())
+	builder.WriteString(StyleReset)
+}
+
+func NewStyledLineFormatter(formatter LineFormatter, output io.Writer) *StyledLineFormatter {
+	return &StyledLineFormatter {
+		Formatter: formatter,
+		Mode: StyleModeFor(output),
+		Depth: DetectColorDepth(),
+	}
+}
+
+func StyleModeFor(output io.Writer) StyleMode {
+	if f, ok := output.(*os.File); ok && IsTerminal(f) && !NoColorRequested() {
+		return STY_ALWAYS
+	}
+	return STY_NEVER
+}
+
+func plainFallback(output io.Writer) bool {
+	return AutoStyles() && StyleModeFor(output) == STY_NEVER
+}
+
+func(form *StyledLineFormatter) style(packet *Packet) *Style {
+	palette := form.Palette
+	if palette == nil {
+		palette = DumbPalette
+	}
+	return palette.StyleFor(packet.Level)
+}
+
+var environmentOnce sync.Once
+var noColorRequested bool
+var stdTerminals bool
+var detectedColorDepth ColorDepth
+
+func inspectEnvironment() {
+	noColor, ok := os.LookupEnv("NO_COLOR")
+	noColorRequested = ok && len(noColor) > 0 || os.Getenv("TERM") == "dumb"
+	stdTerminals = IsTerminal(os.Stdout) && IsTerminal(os.Stderr)
+	colorTerm := strings.ToLower(os.Getenv("COLORTERM"))
+	switch {
+		case colorTerm == "truecolor" || colorTerm == "24bit":
+			detectedColorDepth = DEPTH_TRUECOLOR
+		case strings.Contains(os.Getenv("TERM"), "256color"):
+			detectedColorDepth = DEPTH_256
+		default:
+			detectedColorDepth = DEPTH_16
+	}
+}
+
+func NoColorRequested() bool {
+	environmentOnce.Do(inspectEnvironment)
+	return noColorRequested
+}
+
+func AutoStyles() bool {
+	environmentOnce.Do(inspectEnvironment)
+	return stdTerminals && !noColorRequested
+}
+
+func DetectColorDepth() ColorDepth {
+	environmentOnce.Do(inspectEnvironment)
+	return detectedColorDepth
+}
+
+func IsTerminal(file *os.File) bool {
+	if file == nil {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode() & os.ModeCharDevice != 0
+}
+
+func escapeSequenceLength[TextT string | []byte](text TextT, start int) int {
+	if start + 1 >= len(text) {
+		return 1
+	}
+	if text[start + 1] != '[' {
+		return 2
+	}
+	for index := start + 2; index < len(text); index++ {
+		if text[index] >= 0x40 && text[index] <= 0x7E {
+			return index - start + 1
+		}
+	}
+	return len(text) - start
+}
+
+func StripStyles(text string) string {
+	if strings.IndexByte(text, '\x1b') < 0 {
+		return text
+	}
+	var builder strings.Builder
+	for index := 0; index < len(text); {
+		if text[index] == '\x1b' {
+			index += escapeSequenceLength(text, index)
+		} else {
+			builder.WriteByte(text[index])
+			index++
+		}
+	}
+	return builder.String()
+}
+
+func StripStyleBytes(text []byte) []byte {
+	if bytes.IndexByte(text, '\x1b') < 0 {
+		return text
+	}
+	stripped := text[:0]
+	for index := 0; index < len(text); {
+		if text[index] == '\x1b' {
+			index += escapeSequenceLength(text, index)
+		} else {
+			stripped = append(stripped, text[index])
+			index++
+		}
+	}
+	return stripped
+}
+
+var _ LineFormatter = &StyledLineFormatter{}
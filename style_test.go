@@ -0,0 +1,141 @@
+package golog
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestStyleSequenceDowngrades(t *testing.T) {
+	style := &Style {
+		Foreground: RGBColor(255, 0, 0),
+		Background: IndexedColor(21),
+		Bold: true,
+	}
+	cases := map[ColorDepth]string {
+		DEPTH_TRUECOLOR: "\x1b[1;38;2;255;0;0;48;5;21m",
+		DEPTH_256: "\x1b[1;38;5;196;48;5;21m",
+		DEPTH_16: "\x1b[1;91;104m",
+	}
+	for depth, want := range cases {
+		if got := style.Sequence(depth); got != want {
+			t.Errorf("depth %d: got %q, want %q", depth, got, want)
+		}
+	}
+	if got := (&Style{}).Sequence(DEPTH_TRUECOLOR); got != "" {
+		t.Errorf("empty style produced %q", got)
+	}
+}
+
+func TestPaletteStyleFor(t *testing.T) {
+	if DumbPalette.StyleFor(INFO) != nil {
+		t.Error("INFO should be unstyled")
+	}
+	if style := DumbPalette.StyleFor(MISUSE); style == nil || style.Foreground != BasicColor(ANSI_RED) {
+		t.Errorf("MISUSE style %+v", style)
+	}
+	if style := DumbPalette.StyleFor(&GenericLevel {
+		Number: 1000,
+	}); style == nil || !style.Bold {
+		t.Errorf("levels above FATAL should use the FATAL style, got %+v", style)
+	}
+	if DumbPalette.StyleFor(nil) != nil {
+		t.Error("nil level styled")
+	}
+}
+
+func TestStyledLineFormatterModes(t *testing.T) {
+	inner := &GenericMessageLineFormatter{}
+	packet := &Packet {
+		Level: ERROR,
+		Message: &StringMessage {
+			Text: []string { "failed" },
+		},
+	}
+	render := func(form *StyledLineFormatter, packet *Packet) string {
+		var builder strings.Builder
+		form.PacketToLine(packet, &builder)
+		return builder.String()
+	}
+	always := &StyledLineFormatter {
+		Formatter: inner,
+		Mode: STY_ALWAYS,
+		Depth: DEPTH_16,
+	}
+	if got := render(always, packet); got != "\x1b[31mfailed" + StyleReset {
+		t.Fatalf("got %q", got)
+	}
+	if got := StripStyles(render(always, packet)); got != "failed" {
+		t.Fatalf("StripStyles left %q", got)
+	}
+	never := &StyledLineFormatter {
+		Formatter: inner,
+		Mode: STY_NEVER,
+	}
+	if got := render(never, packet); got != "failed" {
+		t.Fatalf("STY_NEVER produced %q", got)
+	}
+	empty := &Packet {
+		Level: ERROR,
+		Message: &StringMessage{},
+	}
+	if got := render(always, empty); got != "" {
+		t.Fatalf("styled empty text as %q", got)
+	}
+}
+
+func TestStripStyles(t *testing.T) {
+	cases := map[string]string {
+		"plain": "plain",
+		"\x1b[1;31mred\x1b[0m text": "red text",
+		"trailing\x1b": "trailing",
+		"\x1b[38;2;1;2;3mrgb": "rgb",
+		"unterminated\x1b[12": "unterminated",
+	}
+	for input, want := range cases {
+		if got := StripStyles(input); got != want {
+			t.Errorf("%q: got %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestStylesDecidedForOutput(t *testing.T) {
+	var buffer strings.Builder
+	file, err := os.CreateTemp(t.TempDir(), "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	for _, output := range []io.Writer { &buffer, file, nil } {
+		if mode := StyleModeFor(output); mode != STY_NEVER {
+			t.Errorf("%T: mode %d", output, mode)
+		}
+	}
+	form := NewStyledLineFormatter(&GenericMessageLineFormatter{}, &buffer)
+	var line strings.Builder
+	form.PacketToLine(&Packet {
+		Level: FATAL,
+		Message: &StringMessage {
+			Text: []string { "down" },
+		},
+	}, &line)
+	if line.String() != "down" {
+		t.Fatalf("styled for a non-terminal: %q", line.String())
+	}
+	if plainFallback(&buffer) != AutoStyles() {
+		t.Error("plain fallback applied although automatic styles are off")
+	}
+}
+
+func TestTextWriterLoggerKeepsMessageEscapes(t *testing.T) {
+	if AutoStyles() {
+		t.Skip("standard streams are terminals")
+	}
+	writer := &recordingWriter{}
+	logger := TextWriterLogger(writer, nil)
+	logger.Log(levelPacket(INFO, "raw \x1b[7m bytes"))
+	if got := writer.String(); got != "raw \x1b[7m bytes\n" {
+		t.Fatalf("message escapes rewritten: %q", got)
+	}
+}
@@ -1,5 +1,10 @@
 package golog
 
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
 type BoolStack struct {
 	fill uint8
 	scalars []uint64
@@ -75,6 +80,69 @@ func RepeatRune(r rune, times int) []rune {
 	return buffer
 }
 
+func TextWidth(text string) int {
+	var width int
+	for index := 0; index < len(text); {
+		if text[index] == '\x1b' {
+			index += escapeSequenceLength(text, index)
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(text[index:])
+		index += size
+		width += RuneWidth(r)
+	}
+	return width
+}
+
+var wideRanges = []struct {
+	low rune
+	high rune
+} {
+	{0x1100, 0x115F},
+	{0x231A, 0x231B},
+	{0x2329, 0x232A},
+	{0x2E80, 0x303E},
+	{0x3041, 0x33FF},
+	{0x3400, 0x4DBF},
+	{0x4E00, 0x9FFF},
+	{0xA000, 0xA4CF},
+	{0xA960, 0xA97F},
+	{0xAC00, 0xD7A3},
+	{0xF900, 0xFAFF},
+	{0xFE10, 0xFE19},
+	{0xFE30, 0xFE6F},
+	{0xFF00, 0xFF60},
+	{0xFFE0, 0xFFE6},
+	{0x1F300, 0x1F64F},
+	{0x1F900, 0x1F9FF},
+	{0x20000, 0x2FFFD},
+	{0x30000, 0x3FFFD},
+}
+
+func RuneWidth(r rune) int {
+	if r < 0x20 || r >= 0x7F && r < 0xA0 {
+		return 0
+	}
+	if r < 0x1100 {
+		if unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) {
+			return 0
+		}
+		return 1
+	}
+	if unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || r == 0x200B {
+		return 0
+	}
+	for _, wide := range wideRanges {
+		if r < wide.low {
+			break
+		}
+		if r <= wide.high {
+			return 2
+		}
+	}
+	return 1
+}
+
 type OrderRel uint
 
 const (